@@ -0,0 +1,142 @@
+package testcontainers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// TestcontainerLabelAnnotation prefixes the labels of the annotations of the configuration stamped on all the
+// resources created, e.g. org.testcontainers.annotation.team for the team annotation
+const TestcontainerLabelAnnotation = TestcontainerLabel + ".annotation."
+
+// AnnotationUsage is the usage of the Docker host by the resources carrying a value of an annotation, e.g. by the
+// resources of a team on a CI host shared by several teams
+type AnnotationUsage struct {
+	Value             string        // value of the annotation, e.g. payments for the team annotation
+	Containers        int           // number of containers, running or not
+	RunningContainers int           // number of running containers
+	Networks          int           // number of networks
+	Volumes           int           // number of volumes
+	Images            int           // number of images, e.g. built from a Dockerfile
+	ContainerTime     time.Duration // time since the creation of the containers, summed over them
+}
+
+// UsageByAnnotation reports the resources of the Docker host carrying the given annotation, e.g. team, per value of
+// the annotation, sorted by value. The resources of all the test sessions are reported, whether they are over or not,
+// as long as their resources were not removed.
+func UsageByAnnotation(ctx context.Context, annotation string) ([]AnnotationUsage, error) {
+	provider, err := defaultDockerProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	label := TestcontainerLabelAnnotation + annotation
+	filter := filters.NewArgs(filters.Arg("label", label))
+
+	containers, err := provider.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	networks, err := provider.client.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := provider.client.VolumeList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := provider.client.ImageList(ctx, types.ImageListOptions{Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregateUsage(label, containers, networks, volumes.Volumes, images, time.Now()), nil
+}
+
+// aggregateUsage sums the resources per value of the label
+func aggregateUsage(label string, containers []types.Container, networks []types.NetworkResource, volumes []*volume.Volume, images []types.ImageSummary, now time.Time) []AnnotationUsage {
+	usages := map[string]*AnnotationUsage{}
+	usage := func(labels map[string]string) *AnnotationUsage {
+		value := labels[label]
+		if usages[value] == nil {
+			usages[value] = &AnnotationUsage{Value: value}
+		}
+		return usages[value]
+	}
+
+	for i := range containers {
+		u := usage(containers[i].Labels)
+		u.Containers++
+		if containers[i].State == "running" {
+			u.RunningContainers++
+		}
+		u.ContainerTime += containerAge(&containers[i], now)
+	}
+	for _, n := range networks {
+		usage(n.Labels).Networks++
+	}
+	for _, v := range volumes {
+		usage(v.Labels).Volumes++
+	}
+	for _, img := range images {
+		usage(img.Labels).Images++
+	}
+
+	report := make([]AnnotationUsage, 0, len(usages))
+	for _, u := range usages {
+		report = append(report, *u)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Value < report[j].Value
+	})
+
+	return report
+}
+
+// annotationLabels returns the labels of the annotations of the configuration, stamped on all the resources created
+func annotationLabels() map[string]string {
+	return parseAnnotations(configureTC().ResourceAnnotations)
+}
+
+// parseAnnotations parses comma separated annotations, e.g. team=payments,pipeline=1234, into their labels. The
+// entries without name are ignored.
+func parseAnnotations(s string) map[string]string {
+	labels := map[string]string{}
+	for _, entry := range strings.Split(s, ",") {
+		name, value, _ := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		labels[TestcontainerLabelAnnotation+name] = strings.TrimSpace(value)
+	}
+	return labels
+}
+
+// withAnnotations adds the labels of the annotations of the configuration to the given ones, keeping the labels
+// already set
+func withAnnotations(labels map[string]string) map[string]string {
+	annotations := annotationLabels()
+	if len(annotations) == 0 {
+		return labels
+	}
+
+	if labels == nil {
+		labels = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	return labels
+}
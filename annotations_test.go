@@ -0,0 +1,89 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/v3/env"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		TestcontainerLabelAnnotation + "team":     "payments",
+		TestcontainerLabelAnnotation + "pipeline": "1234",
+		TestcontainerLabelAnnotation + "pr":       "",
+	}, parseAnnotations("team=payments, pipeline=1234,pr,,=orphan"))
+
+	assert.Empty(t, parseAnnotations(""))
+}
+
+func TestWithAnnotations(t *testing.T) {
+	env.Patch(t, "TESTCONTAINERS_RESOURCE_ANNOTATIONS", "team=payments,pipeline=1234")
+
+	labels := withAnnotations(map[string]string{TestcontainerLabelAnnotation + "team": "checkout", "app": "orders"})
+	assert.Equal(t, map[string]string{
+		TestcontainerLabelAnnotation + "team":     "checkout",
+		TestcontainerLabelAnnotation + "pipeline": "1234",
+		"app": "orders",
+	}, labels, "the labels already set are kept")
+
+	assert.Len(t, withAnnotations(nil), 2)
+}
+
+func TestAggregateUsage(t *testing.T) {
+	label := TestcontainerLabelAnnotation + "team"
+	now := time.Now().Truncate(time.Second)
+	payments := map[string]string{label: "payments"}
+	checkout := map[string]string{label: "checkout"}
+
+	usage := aggregateUsage(label,
+		[]types.Container{
+			{Labels: payments, State: "running", Created: now.Add(-time.Hour).Unix()},
+			{Labels: payments, State: "exited", Created: now.Add(-30 * time.Minute).Unix()},
+			{Labels: checkout, State: "running", Created: now.Add(-time.Minute).Unix()},
+		},
+		[]types.NetworkResource{{Labels: payments}},
+		[]*volume.Volume{{Labels: checkout}, {Labels: checkout}},
+		[]types.ImageSummary{{Labels: payments}},
+		now,
+	)
+
+	assert.Equal(t, []AnnotationUsage{
+		{Value: "checkout", Containers: 1, RunningContainers: 1, Volumes: 2, ContainerTime: time.Minute},
+		{Value: "payments", Containers: 2, RunningContainers: 1, Networks: 1, Images: 1, ContainerTime: 90 * time.Minute},
+	}, usage)
+}
+
+func TestUsageByAnnotation(t *testing.T) {
+	ctx := context.Background()
+	pipeline := testNameHash(t.Name())
+	env.Patch(t, "TESTCONTAINERS_RESOURCE_ANNOTATIONS", "team=payments,pipeline="+string(pipeline))
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, c)
+
+	usage, err := UsageByAnnotation(ctx, "pipeline")
+	require.NoError(t, err)
+
+	var found *AnnotationUsage
+	for i := range usage {
+		if usage[i].Value == string(pipeline) {
+			found = &usage[i]
+		}
+	}
+	require.NotNil(t, found, "the container is annotated")
+	assert.Equal(t, 1, found.Containers)
+	assert.Equal(t, 1, found.RunningContainers)
+}
@@ -0,0 +1,300 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxAuditEntries bounds the entries kept in memory, the oldest ones are dropped first
+const maxAuditEntries = 10000
+
+// sessionAuditTrail records the operations of the test session
+var sessionAuditTrail = &auditTrail{}
+
+// AuditEntry is an operation performed on the Docker daemon, recorded in the audit trail of the session
+type AuditEntry struct {
+	Time       time.Time         `json:"time"`                 // time the operation started
+	Operation  string            `json:"operation"`            // name of the operation, e.g. ContainerStart
+	Parameters map[string]string `json:"parameters,omitempty"` // parameters of the operation, e.g. the container
+	Duration   time.Duration     `json:"duration"`             // time the daemon took to perform the operation, until the end of its progress stream for a pull
+	Error      string            `json:"error,omitempty"`      // error of the operation, empty if it succeeded
+}
+
+// auditTrail keeps the latest entries of the session, and appends them to the audit file of the configuration
+type auditTrail struct {
+	entries []AuditEntry
+	lock    sync.Mutex
+
+	// whether the audit file was opened since the trail was created or the file closed
+	fileOpened bool
+	file       io.WriteCloser
+}
+
+func (a *auditTrail) record(entry AuditEntry) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.fileOpened {
+		a.openFile()
+		a.fileOpened = true
+	}
+
+	if len(a.entries) == maxAuditEntries {
+		a.entries = append(a.entries[:0], a.entries[1:]...)
+	}
+	a.entries = append(a.entries, entry)
+
+	if a.file == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		_, err = a.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		Logger.Printf("failed to write the audit trail, it is only kept in memory: %s", err)
+		_ = a.file.Close()
+		a.file = nil
+	}
+}
+
+// openFile opens the audit file of the configuration, the entries are appended to it as JSON lines
+func (a *auditTrail) openFile() {
+	path := configureTC().AuditFile
+	if path == "" {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		Logger.Printf("failed to open audit file %s, the audit trail is only kept in memory: %s", path, err)
+		return
+	}
+	a.file = f
+}
+
+// closeFile closes the audit file, reopened by the next operation recorded
+func (a *auditTrail) closeFile() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.fileOpened = false
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+func (a *auditTrail) snapshot() []AuditEntry {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return append([]AuditEntry(nil), a.entries...)
+}
+
+// audit starts recording an operation, the returned function records it in the audit trail once it completes,
+// with its error if any, e.g. defer audit("ContainerStart", parameters)(&err)
+func audit(operation string, parameters map[string]string) func(err *error) {
+	start := time.Now()
+
+	return func(err *error) {
+		entry := AuditEntry{
+			Time:       start,
+			Operation:  operation,
+			Parameters: parameters,
+			Duration:   time.Since(start),
+		}
+		if *err != nil {
+			entry.Error = (*err).Error()
+		}
+		sessionAuditTrail.record(entry)
+	}
+}
+
+// auditClient records the operations changing the state of the daemon in the audit trail of the session, e.g. the
+// creation of containers and networks, the pulls of images and the commands executed in containers. The operations
+// reading the state, e.g. the inspection of containers, are not recorded.
+type auditClient struct {
+	client.APIClient
+}
+
+// withAuditTrail returns the client recording its operations in the audit trail of the session
+func withAuditTrail(c client.APIClient) client.APIClient {
+	if _, ok := c.(*auditClient); ok {
+		return c
+	}
+	return &auditClient{APIClient: c}
+}
+
+func (c *auditClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (resp container.CreateResponse, err error) {
+	parameters := map[string]string{"name": containerName}
+	if config != nil {
+		parameters["image"] = config.Image
+	}
+	done := audit("ContainerCreate", parameters)
+	defer func() {
+		parameters["container"] = resp.ID
+		done(&err)
+	}()
+
+	return c.APIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, containerName)
+}
+
+func (c *auditClient) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) (err error) {
+	defer audit("ContainerStart", map[string]string{"container": containerID})(&err)
+	return c.APIClient.ContainerStart(ctx, containerID, options)
+}
+
+func (c *auditClient) ContainerStop(ctx context.Context, containerID string, options container.StopOptions) (err error) {
+	parameters := map[string]string{"container": containerID, "signal": options.Signal}
+	if options.Timeout != nil {
+		parameters["timeout"] = (time.Duration(*options.Timeout) * time.Second).String()
+	}
+	defer audit("ContainerStop", parameters)(&err)
+	return c.APIClient.ContainerStop(ctx, containerID, options)
+}
+
+func (c *auditClient) ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) (err error) {
+	defer audit("ContainerRestart", map[string]string{"container": containerID})(&err)
+	return c.APIClient.ContainerRestart(ctx, containerID, options)
+}
+
+func (c *auditClient) ContainerKill(ctx context.Context, containerID, signal string) (err error) {
+	defer audit("ContainerKill", map[string]string{"container": containerID, "signal": signal})(&err)
+	return c.APIClient.ContainerKill(ctx, containerID, signal)
+}
+
+func (c *auditClient) ContainerPause(ctx context.Context, containerID string) (err error) {
+	defer audit("ContainerPause", map[string]string{"container": containerID})(&err)
+	return c.APIClient.ContainerPause(ctx, containerID)
+}
+
+func (c *auditClient) ContainerUnpause(ctx context.Context, containerID string) (err error) {
+	defer audit("ContainerUnpause", map[string]string{"container": containerID})(&err)
+	return c.APIClient.ContainerUnpause(ctx, containerID)
+}
+
+func (c *auditClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) (err error) {
+	defer audit("ContainerRemove", map[string]string{"container": containerID})(&err)
+	return c.APIClient.ContainerRemove(ctx, containerID, options)
+}
+
+func (c *auditClient) ContainerExecCreate(ctx context.Context, containerID string, config types.ExecConfig) (resp types.IDResponse, err error) {
+	// the arguments of the command are not recorded, they may hold secrets, e.g. the password of a database client
+	parameters := map[string]string{"container": containerID}
+	if len(config.Cmd) > 0 {
+		parameters["cmd"] = config.Cmd[0]
+		parameters["args"] = strconv.Itoa(len(config.Cmd) - 1)
+	}
+	defer audit("ContainerExecCreate", parameters)(&err)
+	return c.APIClient.ContainerExecCreate(ctx, containerID, config)
+}
+
+func (c *auditClient) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) (err error) {
+	defer audit("CopyToContainer", map[string]string{"container": containerID, "path": dstPath})(&err)
+	return c.APIClient.CopyToContainer(ctx, containerID, dstPath, content, options)
+}
+
+func (c *auditClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	done := audit("ImagePull", map[string]string{"image": ref, "platform": options.Platform})
+
+	rc, err := c.APIClient.ImagePull(ctx, ref, options)
+	if err != nil {
+		done(&err)
+		return rc, err
+	}
+	// the image is pulled once its progress stream is read to the end, the pull is recorded then
+	return &auditedStream{ReadCloser: rc, done: done}, nil
+}
+
+// auditedStream records an operation once its stream is read to the end, or closed before
+type auditedStream struct {
+	io.ReadCloser
+	done func(err *error)
+	once sync.Once
+}
+
+func (s *auditedStream) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if err != nil {
+		s.finish(err)
+	}
+	return n, err
+}
+
+func (s *auditedStream) Close() error {
+	s.finish(errors.New("stream closed before its end"))
+	return s.ReadCloser.Close()
+}
+
+// finish records the operation the first time the stream ends, successfully at EOF
+func (s *auditedStream) finish(err error) {
+	s.once.Do(func() {
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		s.done(&err)
+	})
+}
+
+func (c *auditClient) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (resp types.ImageBuildResponse, err error) {
+	// the build arguments are not recorded, they may hold secrets
+	defer audit("ImageBuild", map[string]string{"tags": strings.Join(options.Tags, ","), "dockerfile": options.Dockerfile})(&err)
+	return c.APIClient.ImageBuild(ctx, buildContext, options)
+}
+
+func (c *auditClient) ImageTag(ctx context.Context, image, ref string) (err error) {
+	defer audit("ImageTag", map[string]string{"image": image, "ref": ref})(&err)
+	return c.APIClient.ImageTag(ctx, image, ref)
+}
+
+func (c *auditClient) ImageRemove(ctx context.Context, image string, options types.ImageRemoveOptions) (items []types.ImageDeleteResponseItem, err error) {
+	defer audit("ImageRemove", map[string]string{"image": image})(&err)
+	return c.APIClient.ImageRemove(ctx, image, options)
+}
+
+func (c *auditClient) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (resp types.NetworkCreateResponse, err error) {
+	defer audit("NetworkCreate", map[string]string{"network": name, "driver": options.Driver})(&err)
+	return c.APIClient.NetworkCreate(ctx, name, options)
+}
+
+func (c *auditClient) NetworkRemove(ctx context.Context, networkID string) (err error) {
+	defer audit("NetworkRemove", map[string]string{"network": networkID})(&err)
+	return c.APIClient.NetworkRemove(ctx, networkID)
+}
+
+func (c *auditClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) (err error) {
+	defer audit("NetworkConnect", map[string]string{"network": networkID, "container": containerID})(&err)
+	return c.APIClient.NetworkConnect(ctx, networkID, containerID, config)
+}
+
+func (c *auditClient) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) (err error) {
+	defer audit("NetworkDisconnect", map[string]string{"network": networkID, "container": containerID})(&err)
+	return c.APIClient.NetworkDisconnect(ctx, networkID, containerID, force)
+}
+
+func (c *auditClient) VolumeCreate(ctx context.Context, options volume.CreateOptions) (v volume.Volume, err error) {
+	defer audit("VolumeCreate", map[string]string{"volume": options.Name})(&err)
+	return c.APIClient.VolumeCreate(ctx, options)
+}
+
+func (c *auditClient) VolumeRemove(ctx context.Context, volumeID string, force bool) (err error) {
+	defer audit("VolumeRemove", map[string]string{"volume": volumeID})(&err)
+	return c.APIClient.VolumeRemove(ctx, volumeID, force)
+}
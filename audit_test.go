@@ -0,0 +1,148 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTrail(t *testing.T) {
+	trail := &auditTrail{fileOpened: true}
+
+	for i := 0; i < maxAuditEntries+2; i++ {
+		trail.record(AuditEntry{Operation: fmt.Sprintf("op-%d", i)})
+	}
+
+	entries := trail.snapshot()
+	require.Len(t, entries, maxAuditEntries)
+	assert.Equal(t, "op-2", entries[0].Operation, "the oldest entries are dropped")
+	assert.Equal(t, fmt.Sprintf("op-%d", maxAuditEntries+1), entries[maxAuditEntries-1].Operation)
+}
+
+func TestAudit(t *testing.T) {
+	failing := func() (err error) {
+		defer audit("ContainerKill", map[string]string{"container": "test-audit", "signal": "SIGKILL"})(&err)
+		return errors.New("no such container")
+	}
+	require.Error(t, failing())
+
+	entries := CurrentSession().AuditTrail()
+	require.NotEmpty(t, entries)
+	entry := entries[len(entries)-1]
+	assert.Equal(t, "ContainerKill", entry.Operation)
+	assert.Equal(t, map[string]string{"container": "test-audit", "signal": "SIGKILL"}, entry.Parameters)
+	assert.Equal(t, "no such container", entry.Error)
+	assert.False(t, entry.Time.IsZero())
+}
+
+// auditedAPIClient answers the operations of the tests of the audit client
+type auditedAPIClient struct {
+	client.APIClient
+}
+
+func (c *auditedAPIClient) ContainerExecCreate(context.Context, string, types.ExecConfig) (types.IDResponse, error) {
+	return types.IDResponse{ID: "exec"}, nil
+}
+
+func (c *auditedAPIClient) ImagePull(context.Context, string, types.ImagePullOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(`{"status":"Downloaded newer image"}`)), nil
+}
+
+func lastAuditEntry(t *testing.T, operation string) AuditEntry {
+	entries := CurrentSession().AuditTrail()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Operation == operation {
+			return entries[i]
+		}
+	}
+	t.Fatalf("no %s recorded", operation)
+	return AuditEntry{}
+}
+
+func TestAuditClientExecArguments(t *testing.T) {
+	c := withAuditTrail(&auditedAPIClient{})
+
+	_, err := c.ContainerExecCreate(context.Background(), "test-audit-exec", types.ExecConfig{Cmd: []string{"psql", "-c", "ALTER USER app PASSWORD 's3cr3t'"}})
+	require.NoError(t, err)
+
+	entry := lastAuditEntry(t, "ContainerExecCreate")
+	assert.Equal(t, map[string]string{"container": "test-audit-exec", "cmd": "psql", "args": "2"}, entry.Parameters,
+		"the arguments of the command are not recorded")
+}
+
+func TestAuditClientImagePull(t *testing.T) {
+	c := withAuditTrail(&auditedAPIClient{})
+
+	rc, err := c.ImagePull(context.Background(), "test-audit-pull:latest", types.ImagePullOptions{})
+	require.NoError(t, err)
+	for _, entry := range CurrentSession().AuditTrail() {
+		assert.NotEqual(t, "test-audit-pull:latest", entry.Parameters["image"], "the pull is recorded once it completes")
+	}
+
+	_, err = io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	entry := lastAuditEntry(t, "ImagePull")
+	assert.Equal(t, "test-audit-pull:latest", entry.Parameters["image"])
+	assert.Empty(t, entry.Error)
+}
+
+func TestAuditTrailCloseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("TESTCONTAINERS_AUDIT_FILE", path)
+
+	trail := &auditTrail{}
+	trail.record(AuditEntry{Operation: "ContainerCreate"})
+	require.NoError(t, trail.closeFile())
+	trail.record(AuditEntry{Operation: "ContainerStart"})
+	require.NoError(t, trail.closeFile())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2, "the file is reopened by the next operation")
+	assert.Contains(t, lines[1], `"operation":"ContainerStart"`)
+}
+
+func TestSessionAuditTrail(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Terminate(ctx))
+
+	var operations []string
+	for _, entry := range CurrentSession().AuditTrail() {
+		if entry.Parameters["container"] == c.GetContainerID() {
+			operations = append(operations, entry.Operation)
+		}
+	}
+	assert.Subset(t, operations, []string{"ContainerCreate", "ContainerStart", "ContainerRemove"})
+
+	var buf bytes.Buffer
+	require.NoError(t, CurrentSession().WriteAuditTrail(&buf))
+
+	var written []AuditEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &written))
+	assert.NotEmpty(t, written)
+}
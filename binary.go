@@ -0,0 +1,114 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultBinaryBaseImage = "docker.io/alpine:3.17"
+	binaryDir              = "/usr/local/bin"
+)
+
+// BinaryRequest describes a binary built on the host and run in a container created from a minimal base image,
+// to test the real binary in a container without writing a Dockerfile
+type BinaryRequest struct {
+	// Path is the path of a Linux binary built on the host. If empty, Package is built with go build.
+	Path string
+	// Package is the Go package of the binary built when Path is empty, e.g. ./cmd/server, the package of the
+	// working directory if empty. It is built without cgo, so that it runs on any base image.
+	Package string
+	// GOARCH is the architecture the package is built for, the one of the host if empty.
+	// It must match the one of the Docker host, e.g. arm64 on Apple silicon.
+	GOARCH string
+	// BaseImage is the image the binary is copied to, docker.io/alpine:3.17 if empty
+	BaseImage    string
+	Args         []string
+	Env          map[string]string
+	ExposedPorts []string
+	WaitingFor   wait.Strategy
+}
+
+// RunBinary copies the binary of the request to a container of the base image, and starts it with the arguments,
+// the environment and the exposed ports of the request. The options customize the request of the container further.
+func RunBinary(ctx context.Context, binary BinaryRequest, opts ...CustomizeRequestOption) (Container, error) {
+	binaryPath := binary.Path
+	if binaryPath == "" {
+		dir, err := os.MkdirTemp("", "testcontainers-binary-")
+		if err != nil {
+			return nil, err
+		}
+		// the binary is copied to the container once it is created
+		defer os.RemoveAll(dir)
+
+		if binaryPath, err = BuildLinuxBinary(ctx, binary.Package, binary.GOARCH, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	baseImage := binary.BaseImage
+	if baseImage == "" {
+		baseImage = defaultBinaryBaseImage
+	}
+
+	containerPath := path.Join(binaryDir, filepath.Base(binaryPath))
+
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:        baseImage,
+			Entrypoint:   []string{containerPath},
+			Cmd:          binary.Args,
+			Env:          binary.Env,
+			ExposedPorts: binary.ExposedPorts,
+			WaitingFor:   binary.WaitingFor,
+			Files: []ContainerFile{
+				{HostFilePath: binaryPath, ContainerFilePath: containerPath, FileMode: 0o755},
+			},
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	return GenericContainer(ctx, req)
+}
+
+// BuildLinuxBinary builds the Go package for Linux without cgo, to the given directory, and returns the path of the
+// binary. An empty package builds the one of the working directory, an empty architecture the one of the host.
+func BuildLinuxBinary(ctx context.Context, pkg string, goarch string, dir string) (string, error) {
+	if pkg == "" {
+		pkg = "."
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+
+	name := path.Base(strings.TrimSuffix(filepath.ToSlash(pkg), "/"))
+	if name == "." || name == ".." {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		name = filepath.Base(filepath.Join(wd, pkg))
+	}
+	output := filepath.Join(dir, name)
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", output, pkg)
+	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH="+goarch, "CGO_ENABLED=0")
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%w: failed to build %s: %s", err, pkg, strings.TrimSpace(string(out)))
+	}
+
+	return output, nil
+}
@@ -0,0 +1,58 @@
+package testcontainers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestBuildLinuxBinary(t *testing.T) {
+	dir := t.TempDir()
+
+	binary, err := BuildLinuxBinary(context.Background(), "./testresources", "", dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "testresources"), binary)
+
+	info, err := os.Stat(binary)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+
+	_, err = BuildLinuxBinary(context.Background(), "./missing", "", dir)
+	assert.ErrorContains(t, err, "failed to build ./missing")
+}
+
+func TestRunBinary(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := RunBinary(ctx, BinaryRequest{
+		Package:      "./testresources",
+		Env:          map[string]string{"FOO": "BAR"},
+		ExposedPorts: []string{"8080/tcp"},
+		WaitingFor:   wait.ForLog("ready"),
+	}, func(req *GenericContainerRequest) {
+		req.ProviderType = providerType
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	endpoint, err := c.PortEndpoint(ctx, "8080/tcp", "http")
+	require.NoError(t, err)
+
+	resp, err := http.Get(endpoint + "/env")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "BAR", string(body))
+}
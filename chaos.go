@@ -0,0 +1,267 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChaosMinInterval = 5 * time.Second
+	defaultChaosMaxInterval = 15 * time.Second
+	defaultChaosDowntime    = 2 * time.Second
+)
+
+// ChaosAction defines the fault a ChaosMonkey injects into a container, and how the container is restored
+type ChaosAction int
+
+const (
+	// ChaosStop stops the container gracefully, then starts it again
+	ChaosStop ChaosAction = iota
+	// ChaosKill kills the container with SIGKILL, then starts it again
+	ChaosKill
+	// ChaosPause freezes the processes of the container, then resumes them
+	ChaosPause
+)
+
+func (a ChaosAction) String() string {
+	switch a {
+	case ChaosStop:
+		return "stop"
+	case ChaosKill:
+		return "kill"
+	case ChaosPause:
+		return "pause"
+	}
+	return fmt.Sprintf("ChaosAction(%d)", int(a))
+}
+
+// ChaosOptions configures the schedule of a ChaosMonkey. The zero value injects any action every 5 to 15 seconds,
+// until the monkey is stopped.
+type ChaosOptions struct {
+	Actions     []ChaosAction    // actions picked at random, defaults to all of them
+	MinInterval time.Duration    // minimum time between two faults, defaults to 5 seconds
+	MaxInterval time.Duration    // maximum time between two faults, defaults to 15 seconds
+	Downtime    time.Duration    // how long a container stays faulty before being restored, defaults to 2 seconds
+	Duration    time.Duration    // length of the test window, faults are injected until Stop is called if zero
+	Seed        int64            // seed of the random schedule, to replay a timeline, the current time if zero
+	OnEvent     func(ChaosEvent) // optional callback invoked once a container is restored
+}
+
+// ChaosEvent records a fault injected into a container. Err is set if the fault could not be injected,
+// or the container could not be restored.
+type ChaosEvent struct {
+	ContainerID string
+	Action      ChaosAction
+	InjectedAt  time.Time
+	RestoredAt  time.Time
+	Err         error
+}
+
+func (e ChaosEvent) String() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s of container %s at %s failed: %s", e.Action, e.ContainerID, e.InjectedAt.Format(time.RFC3339), e.Err)
+	}
+	return fmt.Sprintf("%s of container %s from %s to %s", e.Action, e.ContainerID,
+		e.InjectedAt.Format(time.RFC3339), e.RestoredAt.Format(time.RFC3339))
+}
+
+// ChaosMonkey injects faults into containers at random on a schedule, one at a time, and records the timeline
+// of the faults, so that tests can assert how the system under test copes with its dependencies going away.
+type ChaosMonkey struct {
+	containers []*DockerContainer
+	opts       ChaosOptions
+	rand       *rand.Rand
+
+	// inject applies the action to the container, and returns how to restore it
+	inject func(ctx context.Context, c *DockerContainer, action ChaosAction) (func(context.Context) error, error)
+
+	timeline []ChaosEvent
+	lock     sync.Mutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartChaos starts injecting faults into the given containers in the background, until Stop is called,
+// the duration of the options elapsed or the context is cancelled. The context is also used to restore
+// the containers, so that the last fault is undone when stopping the monkey.
+func StartChaos(ctx context.Context, containers []Container, opts ChaosOptions) (*ChaosMonkey, error) {
+	if len(containers) == 0 {
+		return nil, errors.New("chaos requires at least one container")
+	}
+
+	targets := make([]*DockerContainer, 0, len(containers))
+	for _, c := range containers {
+		dc, ok := c.(*DockerContainer)
+		if !ok {
+			return nil, errors.New("chaos requires containers created by the Docker provider")
+		}
+		targets = append(targets, dc)
+	}
+
+	if len(opts.Actions) == 0 {
+		opts.Actions = []ChaosAction{ChaosStop, ChaosKill, ChaosPause}
+	}
+	if opts.MinInterval <= 0 {
+		opts.MinInterval = defaultChaosMinInterval
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = defaultChaosMaxInterval
+	}
+	if opts.MaxInterval < opts.MinInterval {
+		opts.MaxInterval = opts.MinInterval
+	}
+	if opts.Downtime <= 0 {
+		opts.Downtime = defaultChaosDowntime
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	var scheduleCtx context.Context
+	var cancel context.CancelFunc
+	if opts.Duration > 0 {
+		scheduleCtx, cancel = context.WithTimeout(ctx, opts.Duration)
+	} else {
+		scheduleCtx, cancel = context.WithCancel(ctx)
+	}
+
+	m := newChaosMonkey(targets, opts)
+	m.cancel = cancel
+	go m.run(ctx, scheduleCtx)
+
+	return m, nil
+}
+
+func newChaosMonkey(containers []*DockerContainer, opts ChaosOptions) *ChaosMonkey {
+	return &ChaosMonkey{
+		containers: containers,
+		opts:       opts,
+		rand:       rand.New(rand.NewSource(opts.Seed)),
+		inject:     injectChaos,
+		done:       make(chan struct{}),
+	}
+}
+
+// Stop stops injecting faults, waits for the container currently faulty to be restored,
+// and returns the timeline of the faults
+func (m *ChaosMonkey) Stop() []ChaosEvent {
+	m.cancel()
+	<-m.done
+
+	return m.Timeline()
+}
+
+// Done is closed once the test window is over and the last faulty container is restored
+func (m *ChaosMonkey) Done() <-chan struct{} {
+	return m.done
+}
+
+// Timeline returns the faults injected so far, in the order they were injected
+func (m *ChaosMonkey) Timeline() []ChaosEvent {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	timeline := make([]ChaosEvent, len(m.timeline))
+	copy(timeline, m.timeline)
+
+	return timeline
+}
+
+// run injects faults until the schedule is over, the containers are restored with the context of the caller
+func (m *ChaosMonkey) run(ctx context.Context, scheduleCtx context.Context) {
+	defer close(m.done)
+	defer m.cancel()
+
+	for {
+		select {
+		case <-scheduleCtx.Done():
+			return
+		case <-time.After(m.nextInterval()):
+		}
+
+		target, action := m.pick()
+		event := m.fault(ctx, scheduleCtx, target, action)
+
+		m.lock.Lock()
+		m.timeline = append(m.timeline, event)
+		m.lock.Unlock()
+
+		if event.Err != nil {
+			target.logger.Printf("chaos: %s", event)
+		}
+		if m.opts.OnEvent != nil {
+			m.opts.OnEvent(event)
+		}
+	}
+}
+
+// fault injects the action into the container and restores it once the downtime elapsed,
+// or as soon as the schedule is over
+func (m *ChaosMonkey) fault(ctx context.Context, scheduleCtx context.Context, target *DockerContainer, action ChaosAction) ChaosEvent {
+	event := ChaosEvent{ContainerID: target.ID, Action: action, InjectedAt: time.Now()}
+
+	restore, err := m.inject(ctx, target, action)
+	if err != nil {
+		event.Err = fmt.Errorf("%w: failed to inject %s", err, action)
+		return event
+	}
+
+	select {
+	case <-scheduleCtx.Done():
+	case <-time.After(m.opts.Downtime):
+	}
+
+	if err := restore(ctx); err != nil {
+		event.Err = fmt.Errorf("%w: failed to restore after %s", err, action)
+		return event
+	}
+	event.RestoredAt = time.Now()
+
+	return event
+}
+
+// nextInterval picks the time until the next fault between the minimum and the maximum intervals
+func (m *ChaosMonkey) nextInterval() time.Duration {
+	spread := int64(m.opts.MaxInterval - m.opts.MinInterval)
+	if spread <= 0 {
+		return m.opts.MinInterval
+	}
+	return m.opts.MinInterval + time.Duration(m.rand.Int63n(spread+1))
+}
+
+func (m *ChaosMonkey) pick() (*DockerContainer, ChaosAction) {
+	target := m.containers[m.rand.Intn(len(m.containers))]
+	action := m.opts.Actions[m.rand.Intn(len(m.opts.Actions))]
+	return target, action
+}
+
+// injectChaos applies the action with the lifecycle APIs of the container, pausing relies on the Docker client
+func injectChaos(ctx context.Context, c *DockerContainer, action ChaosAction) (func(context.Context) error, error) {
+	var err error
+	restore := c.Start
+
+	switch action {
+	case ChaosStop:
+		err = c.Stop(ctx, nil)
+	case ChaosKill:
+		// the stop timeout is irrelevant as SIGKILL cannot be handled
+		err = c.StopWithSignal(ctx, "SIGKILL", nil)
+	case ChaosPause:
+		err = c.provider.client.ContainerPause(ctx, c.ID)
+		restore = func(ctx context.Context) error {
+			return c.provider.client.ContainerUnpause(ctx, c.ID)
+		}
+	default:
+		err = fmt.Errorf("unknown chaos action %s", action)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return restore, nil
+}
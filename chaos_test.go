@@ -0,0 +1,117 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosMonkeyTimeline(t *testing.T) {
+	containers := []*DockerContainer{{ID: "a", logger: Logger}, {ID: "b", logger: Logger}}
+	opts := ChaosOptions{
+		Actions:     []ChaosAction{ChaosStop, ChaosKill, ChaosPause},
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+		Downtime:    time.Millisecond,
+		Seed:        42,
+	}
+
+	// the same seed replays the same schedule
+	first := newChaosMonkey(containers, opts)
+	second := newChaosMonkey(containers, opts)
+	for i := 0; i < 10; i++ {
+		c1, a1 := first.pick()
+		c2, a2 := second.pick()
+		assert.Equal(t, c1.ID, c2.ID)
+		assert.Equal(t, a1, a2)
+
+		interval := first.nextInterval()
+		assert.Equal(t, interval, second.nextInterval())
+		assert.True(t, interval >= opts.MinInterval && interval <= opts.MaxInterval, "unexpected interval %s", interval)
+	}
+
+	restored := map[string]int{}
+	m := newChaosMonkey(containers, opts)
+	m.inject = func(ctx context.Context, c *DockerContainer, action ChaosAction) (func(context.Context) error, error) {
+		if action == ChaosKill {
+			return nil, errors.New("no such container")
+		}
+		return func(context.Context) error {
+			restored[c.ID]++
+			return nil
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	m.cancel = cancel
+	go m.run(context.Background(), ctx)
+
+	<-m.Done()
+	timeline := m.Timeline()
+	require.NotEmpty(t, timeline)
+
+	total := 0
+	for _, event := range timeline {
+		if event.Action == ChaosKill {
+			assert.EqualError(t, event.Err, "no such container: failed to inject kill")
+			assert.True(t, event.RestoredAt.IsZero())
+			continue
+		}
+		assert.NoError(t, event.Err)
+		assert.False(t, event.RestoredAt.Before(event.InjectedAt))
+		total++
+	}
+	assert.Equal(t, total, restored["a"]+restored["b"])
+}
+
+func TestChaosPausesAndResumesContainers(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	paused := make(chan bool, 1)
+	m, err := StartChaos(ctx, []Container{c}, ChaosOptions{
+		Actions:     []ChaosAction{ChaosPause},
+		MinInterval: 100 * time.Millisecond,
+		MaxInterval: 100 * time.Millisecond,
+		Downtime:    time.Second,
+		OnEvent: func(ChaosEvent) {
+			select {
+			case paused <- true:
+			default:
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	// the container is paused while the fault is injected
+	require.Eventually(t, func() bool {
+		state, err := c.State(ctx)
+		return err == nil && state.Paused
+	}, 5*time.Second, 50*time.Millisecond)
+
+	<-paused
+	timeline := m.Stop()
+	require.NotEmpty(t, timeline)
+	assert.Equal(t, ChaosPause, timeline[0].Action)
+	assert.NoError(t, timeline[0].Err)
+
+	state, err := c.State(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Paused, "the container is resumed once the monkey stopped")
+}
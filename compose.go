@@ -3,17 +3,25 @@ package testcontainers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"testing"
+	"time"
 
 	"github.com/compose-spec/compose-go/types"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/flags"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/compose"
+	"github.com/docker/go-connections/nat"
 	"github.com/google/uuid"
 
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -24,9 +32,32 @@ const (
 
 var ErrNoStackConfigured = errors.New("no stack files configured")
 
+var (
+	// ErrInvalidProjectName is returned when no valid compose project name can be derived from the StackIdentifier
+	ErrInvalidProjectName = errors.New("invalid compose project name")
+	// ErrProjectNameInUse is returned by Up when the containers of another project with the same name are running,
+	// e.g. started by another test session sharing the StackIdentifier
+	ErrProjectNameInUse = errors.New("compose project name already in use")
+)
+
 type composeStackOptions struct {
 	Identifier string
 	Paths      []string
+	Profiles   []string
+	Logger     Logging
+	Provider   ProviderType
+	FS         fs.FS
+	FSPaths    []string
+	URLs       []string
+	Readers    []io.Reader
+	Reuse      bool
+	SkipReaper bool
+	Backend    ComposeBackend
+
+	ImageSubstitutors []ImageSubstitutor
+
+	ServiceCommands    map[string][]string
+	ServiceEntrypoints map[string][]string
 }
 
 type ComposeStackOption interface {
@@ -44,14 +75,47 @@ type stackUpOptions struct {
 	Recreate string
 	// RecreateDependencies define the strategy to apply on dependencies services
 	RecreateDependencies string
+	// Profiles define the profiles of the services to start, services without profile are always started
+	Profiles []string
+	// Scale defines the number of replicas per service, overriding deploy.replicas
+	Scale map[string]int
+	// ServiceEnv defines environment variables per service, overriding the ones of the compose files
+	ServiceEnv map[string]map[string]string
+	// SecretContents defines the contents of secrets, replacing the files the compose files declare for them
+	SecretContents map[string][]byte
+	// ConfigContents defines the contents of configs, replacing the files the compose files declare for them
+	ConfigContents map[string][]byte
+	// ExternalNetworks are existing networks all the services are attached to, in addition to their own networks
+	ExternalNetworks []string
+	// Build defines the options to build the images of the services before they are created, nil to skip the build
+	Build *api.BuildOptions
+	// PullPolicy overrides the pull_policy of all services, empty to keep the ones of the compose files
+	PullPolicy PullPolicy
+	// Timeout limits the time to start the stack and wait for its services, no limit if zero
+	Timeout time.Duration
+	// FailureLogLines is the number of log lines of each container not ready added to the error of a failed Up
+	FailureLogLines int
 	// Project is the compose project used to define this app. Might be nil if user ran command just with project name
 	Project *types.Project
 }
 
+// defaultFailureLogLines is the default number of log lines of each container not ready added to the error of a
+// failed Up
+const defaultFailureLogLines = 50
+
 type StackUpOption interface {
 	applyToStackUp(o *stackUpOptions)
 }
 
+type waitForServiceOptions struct {
+	// Timeout limits the time to wait for the service, no limit other than the one of the strategy if zero
+	Timeout time.Duration
+}
+
+type WaitForServiceOption interface {
+	applyToWaitForService(o *waitForServiceOptions)
+}
+
 type stackDownOptions struct {
 	api.DownOptions
 }
@@ -60,15 +124,91 @@ type StackDownOption interface {
 	applyToStackDown(do *stackDownOptions)
 }
 
+// ComposeStackHook is a function executed at a given point of the lifecycle of a compose stack
+type ComposeStackHook func(ctx context.Context, stack ComposeStack) error
+
 // ComposeStack defines operations that can be applied to a parsed compose stack
 type ComposeStack interface {
 	Up(ctx context.Context, opts ...StackUpOption) error
 	Down(ctx context.Context, opts ...StackDownOption) error
+	Stop(ctx context.Context, services ...string) error
+	Start(ctx context.Context, services ...string) error
+	Kill(ctx context.Context, signal string, services ...string) error
+	Pause(ctx context.Context, services ...string) error
+	Unpause(ctx context.Context, services ...string) error
+	RestartService(ctx context.Context, svc string, timeout *time.Duration) error
+	Ps(ctx context.Context) ([]ServiceStatus, error)
+	Stats(ctx context.Context, svcName string) (ContainerStats, error)
+	Events(ctx context.Context, services ...string) (<-chan ComposeEvent, error)
 	Services() []string
-	WaitForService(s string, strategy wait.Strategy) ComposeStack
+	Project() *types.Project
+	Validate(ctx context.Context) error
+	Network(ctx context.Context) (*DockerNetwork, error)
+	Networks(ctx context.Context) ([]*DockerNetwork, error)
+	WaitForService(s string, strategy wait.Strategy, opts ...WaitForServiceOption) ComposeStack
+	WithLogConsumer(s string, consumer LogConsumer) ComposeStack
 	WithEnv(m map[string]string) ComposeStack
 	WithOsEnv() ComposeStack
+	WithEnvFile(paths ...string) ComposeStack
+	WithEnvStruct(v interface{}) ComposeStack
+	OnAfterUp(hook ComposeStackHook) ComposeStack
+	OnBeforeDown(hook ComposeStackHook) ComposeStack
 	ServiceContainer(ctx context.Context, svcName string) (*DockerContainer, error)
+	ServiceContainers(ctx context.Context, svcName string) ([]*DockerContainer, error)
+	AllServiceContainers(ctx context.Context) (map[string]*DockerContainer, error)
+	ServiceHost(ctx context.Context, svcName string) (string, error)
+	ServicePort(ctx context.Context, svcName string, port nat.Port) (nat.Port, error)
+	Endpoint(ctx context.Context, svcName string, port nat.Port, proto string) (string, error)
+	Exec(ctx context.Context, svcName string, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error)
+	RunOneOff(ctx context.Context, svcName string, cmd []string, options ...tcexec.ProcessOption) (*ExecResult, error)
+}
+
+// ServiceAs returns the container of the service wrapped by from, e.g. in the type of a module, so that its helpers
+// are available when the topology is defined in compose:
+//
+//	db, err := testcontainers.ServiceAs(ctx, stack, "db", postgres.FromContainer)
+//
+// It is a function rather than a method of ComposeStack, as methods can't have type parameters.
+func ServiceAs[T any](ctx context.Context, stack ComposeStack, svcName string, from func(context.Context, Container) (T, error)) (T, error) {
+	var zero T
+
+	container, err := stack.ServiceContainer(ctx, svcName)
+	if err != nil {
+		return zero, err
+	}
+
+	wrapped, err := from(ctx, container)
+	if err != nil {
+		return zero, fmt.Errorf("%w: failed to wrap the container of service %s", err, svcName)
+	}
+
+	return wrapped, nil
+}
+
+// WithComposeNetwork attaches the container to the network of the stack returned by ComposeStack.Network, with the
+// given aliases, so that it resolves the services of the stack by name, e.g. a test client. The stack must be up.
+func WithComposeNetwork(stack ComposeStack, aliases ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		name, err := composeNetworkName(stack.Project())
+		if err != nil {
+			logger := req.Logger
+			if logger == nil {
+				logger = Logger
+			}
+			logger.Printf("failed to attach the container to the compose network: %s", err)
+			return
+		}
+
+		req.Networks = append(req.Networks, name)
+		if len(aliases) == 0 {
+			return
+		}
+
+		if req.NetworkAliases == nil {
+			req.NetworkAliases = map[string][]string{}
+		}
+		req.NetworkAliases[name] = append(req.NetworkAliases[name], aliases...)
+	}
 }
 
 // DockerCompose defines the contract for running Docker Compose
@@ -96,37 +236,157 @@ func NewDockerCompose(filePaths ...string) (*dockerCompose, error) {
 	return NewDockerComposeWith(WithStackFiles(filePaths...))
 }
 
-func NewDockerComposeWith(opts ...ComposeStackOption) (*dockerCompose, error) {
-	composeOptions := composeStackOptions{
-		Identifier: uuid.New().String(),
+// NewDockerComposeForTest creates a stack from the given compose files for the test, failing it if the stack can't
+// be created. The output of the Docker CLI is logged with t.Logf, and the stack is torn down with its orphans and
+// volumes when the test completes, so that no defer is needed.
+func NewDockerComposeForTest(t testing.TB, filePaths ...string) *dockerCompose {
+	t.Helper()
+
+	stack, err := NewDockerComposeWith(WithStackFiles(filePaths...), WithLogger(TestLogger(t)))
+	if err != nil {
+		t.Fatalf("failed to create compose stack: %s", err)
 	}
 
+	t.Cleanup(func() {
+		if err := stack.Down(context.Background(), RemoveOrphans(true), RemoveVolumes(true)); err != nil {
+			t.Errorf("failed to tear down compose stack: %s", err)
+		}
+	})
+
+	return stack
+}
+
+func NewDockerComposeWith(opts ...ComposeStackOption) (_ *dockerCompose, err error) {
+	composeOptions := composeStackOptions{}
+
 	for i := range opts {
 		opts[i].applyToComposeStack(&composeOptions)
 	}
 
+	if composeOptions.Identifier == "" {
+		if composeOptions.Reuse {
+			return nil, errors.New("stack reuse requires a StackIdentifier")
+		}
+		composeOptions.Identifier = uuid.New().String()
+	} else {
+		name, err := sanitizeProjectName(composeOptions.Identifier)
+		if err != nil {
+			return nil, err
+		}
+		if name != composeOptions.Identifier {
+			logger := composeOptions.Logger
+			if logger == nil {
+				logger = Logger
+			}
+			logger.Printf("compose project name %q is not valid, using %q", composeOptions.Identifier, name)
+		}
+		composeOptions.Identifier = name
+	}
+
+	var tempDir string
+	var writeStackFiles func(dir string) error
+	if composeOptions.FS != nil || len(composeOptions.Readers) > 0 {
+		var readerContents [][]byte
+		if readerContents, err = readStackReaders(composeOptions.Readers); err != nil {
+			return nil, err
+		}
+
+		// the files are written again by the Up following a Down, which removes the directory
+		writeStackFiles = func(dir string) error {
+			if composeOptions.FS != nil {
+				if err := copyStackFS(composeOptions.FS, composeOptions.FSPaths, dir); err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(dir, 0o700); err != nil {
+				return err
+			}
+			return writeStackReaders(dir, readerContents)
+		}
+
+		if tempDir, err = stackTempDir(composeOptions.Identifier); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				_ = os.RemoveAll(tempDir)
+			}
+		}()
+
+		if err = writeStackFiles(tempDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if composeOptions.FS != nil {
+		for _, p := range composeOptions.FSPaths {
+			composeOptions.Paths = append(composeOptions.Paths, filepath.Join(tempDir, filepath.FromSlash(p)))
+		}
+	}
+
+	for _, u := range composeOptions.URLs {
+		p, err := fetchStackURL(u)
+		if err != nil {
+			return nil, err
+		}
+		composeOptions.Paths = append(composeOptions.Paths, p)
+	}
+
+	for i := range composeOptions.Readers {
+		composeOptions.Paths = append(composeOptions.Paths, stackReaderPath(tempDir, i))
+	}
+
 	if len(composeOptions.Paths) < 1 {
 		return nil, ErrNoStackConfigured
 	}
 
-	dockerCli, err := command.NewDockerCli()
+	var cliOpts []command.DockerCliOption
+	if composeOptions.Logger != nil {
+		cliOpts = append(cliOpts, command.WithCombinedStreams(&loggingWriter{logger: composeOptions.Logger}))
+	} else {
+		composeOptions.Logger = Logger
+	}
+
+	dockerCli, err := command.NewDockerCli(cliOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	podmanHost := resolvePodmanHost(composeOptions.Provider)
+
 	if err = dockerCli.Initialize(&flags.ClientOptions{
 		Common: new(flags.CommonOptions),
-	}, command.WithInitializeClient(makeClient)); err != nil {
+	}, command.WithInitializeClient(makeClient(podmanHost))); err != nil {
 		return nil, err
 	}
 
+	var composeService api.Service = compose.NewComposeService(dockerCli)
+	if composeOptions.Backend == ComposeBackendLocal {
+		if composeService, err = newLocalComposeService(dockerCli.Client().DaemonHost(), composeOptions.Logger); err != nil {
+			return nil, err
+		}
+	}
+
 	composeAPI := &dockerCompose{
-		name:           composeOptions.Identifier,
-		configs:        composeOptions.Paths,
-		composeService: compose.NewComposeService(dockerCli),
-		dockerClient:   dockerCli.Client(),
-		waitStrategies: make(map[string]wait.Strategy),
-		containers:     make(map[string]*DockerContainer),
+		name:               composeOptions.Identifier,
+		configs:            composeOptions.Paths,
+		profiles:           composeOptions.Profiles,
+		logger:             composeOptions.Logger,
+		podman:             podmanHost != "",
+		tempDir:            tempDir,
+		writeStackFiles:    writeStackFiles,
+		reuse:              composeOptions.Reuse,
+		imageSubstitutors:  composeOptions.ImageSubstitutors,
+		serviceCommands:    composeOptions.ServiceCommands,
+		serviceEntrypoints: composeOptions.ServiceEntrypoints,
+		provider:           composeOptions.Provider,
+		skipReaper:         composeOptions.SkipReaper,
+		composeService:     composeService,
+		dockerClient:       dockerCli.Client(),
+		waitStrategies:     make(map[string]wait.Strategy),
+		waitTimeouts:       make(map[string]time.Duration),
+		logConsumers:       make(map[string][]LogConsumer),
+		containers:         make(map[string]*DockerContainer),
 	}
 
 	return composeAPI, nil
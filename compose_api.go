@@ -1,21 +1,44 @@
 package testcontainers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/compose-spec/compose-go/cli"
+	"github.com/compose-spec/compose-go/loader"
 	"github.com/compose-spec/compose-go/types"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
 	types2 "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
 	"golang.org/x/sync/errgroup"
 
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -25,10 +48,10 @@ func (f stackUpOptionFunc) applyToStackUp(o *stackUpOptions) {
 	f(o)
 }
 
-type stackDownOptionFunc func(do *api.DownOptions)
+type stackDownOptionFunc func(o *stackDownOptions)
 
-func (f stackDownOptionFunc) applyToStackDown(do *api.DownOptions) {
-	f(do)
+func (f stackDownOptionFunc) applyToStackDown(o *stackDownOptions) {
+	f(o)
 }
 
 // RunServices is comparable to 'docker-compose run' as it only creates a subset of containers
@@ -39,6 +62,151 @@ func RunServices(serviceNames ...string) StackUpOption {
 	})
 }
 
+// WithScale sets the number of replicas of the given services, overriding their deploy.replicas attribute
+func WithScale(scale map[string]int) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		o.Scale = scale
+	})
+}
+
+// WithExternalNetwork attaches all the services of the stack to an existing network, in addition to the networks the
+// compose files attach them to, so that they resolve the containers of the network by name and are resolved by their
+// service name, e.g. the services of another stack. The services with a network_mode are left as is.
+func WithExternalNetwork(name string) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		o.ExternalNetworks = append(o.ExternalNetworks, name)
+	})
+}
+
+// WithServiceEnv overrides environment variables of a service for this Up, on top of the environment the compose
+// files define for it, without affecting the interpolation of the compose files or the other services. A service
+// whose environment changed is recreated. Use it once per service to override.
+func WithServiceEnv(service string, env map[string]string) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		if o.ServiceEnv == nil {
+			o.ServiceEnv = make(map[string]map[string]string)
+		}
+		if o.ServiceEnv[service] == nil {
+			o.ServiceEnv[service] = make(map[string]string, len(env))
+		}
+		for k, v := range env {
+			o.ServiceEnv[service][k] = v
+		}
+	})
+}
+
+// WithSecretContent sets the content of a secret the compose files declare, written to a temporary file at Up instead
+// of the file they declare for it, e.g. to keep credentials out of the repository. The file is removed by Down.
+func WithSecretContent(name string, data []byte) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		if o.SecretContents == nil {
+			o.SecretContents = make(map[string][]byte)
+		}
+		o.SecretContents[name] = data
+	})
+}
+
+// WithConfigContent sets the content of a config the compose files declare, written to a temporary file at Up instead
+// of the file they declare for it. The file is removed by Down.
+func WithConfigContent(name string, data []byte) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		if o.ConfigContents == nil {
+			o.ConfigContents = make(map[string][]byte)
+		}
+		o.ConfigContents[name] = data
+	})
+}
+
+// WithBuild builds the images of the services with a build section before they are created, even if they exist,
+// e.g. to take changes of their sources into account. By default, only missing images are built, with default options
+func WithBuild(options api.BuildOptions) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		o.Build = &options
+	})
+}
+
+// WithFailureLogLines sets the number of log lines of each container not ready added to the error of a failed Up,
+// defaultFailureLogLines if zero, no logs are added if negative
+func WithFailureLogLines(lines int) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		o.FailureLogLines = lines
+	})
+}
+
+// WithUpTimeout limits the time to create and start the stack and to wait for all its services to be ready
+func WithUpTimeout(timeout time.Duration) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		o.Timeout = timeout
+	})
+}
+
+type waitForServiceOptionFunc func(o *waitForServiceOptions)
+
+func (f waitForServiceOptionFunc) applyToWaitForService(o *waitForServiceOptions) {
+	f(o)
+}
+
+// WithWaitTimeout limits the time to wait for the service to be ready, so that a hung service fails fast
+// with an error identifying it
+func WithWaitTimeout(timeout time.Duration) WaitForServiceOption {
+	return waitForServiceOptionFunc(func(o *waitForServiceOptions) {
+		o.Timeout = timeout
+	})
+}
+
+// ServiceStatus describes a container of a compose service as reported by ComposeStack.Ps
+type ServiceStatus struct {
+	ContainerID string
+	// Name is the name of the container, e.g. my-stack-nginx-1
+	Name    string
+	Service string
+	// State is the state of the container, e.g. running or exited
+	State string
+	// Health is the status of the healthcheck of the container, empty if it has none
+	Health   string
+	ExitCode int
+	Ports    []PublishedPort
+}
+
+// ComposeEvent is a runtime event of a container of the stack as streamed by ComposeStack.Events
+type ComposeEvent struct {
+	Timestamp time.Time
+	Service   string
+	Container string
+	// Status is the action of the event, e.g. create, start, die, restart or health_status: healthy
+	Status string
+	// Attributes are the attributes of the container without the compose labels, e.g. exitCode for die events
+	Attributes map[string]string
+}
+
+// PublishedPort is a container port published on the host
+type PublishedPort struct {
+	// URL is the host address the port is bound to, e.g. 0.0.0.0
+	URL           string
+	TargetPort    int
+	PublishedPort int
+	Protocol      string
+}
+
+// PullPolicy defines when the images of the services are pulled
+type PullPolicy string
+
+const (
+	// PullAlways pulls the images every time the stack is started, e.g. to refresh them in CI
+	PullAlways PullPolicy = types.PullPolicyAlways
+	// PullMissing only pulls the images that are not present locally
+	PullMissing PullPolicy = types.PullPolicyMissing
+	// PullNever never pulls the images, e.g. to run fully offline against the local images
+	PullNever PullPolicy = types.PullPolicyNever
+)
+
+// WithPullPolicy overrides the pull_policy of all services of the stack
+func WithPullPolicy(policy PullPolicy) StackUpOption {
+	return stackUpOptionFunc(func(o *stackUpOptions) {
+		o.PullPolicy = policy
+	})
+}
+
 // IgnoreOrphans - Ignore legacy containers for services that are not defined in the project
 type IgnoreOrphans bool
 
@@ -57,6 +225,14 @@ func (ro RemoveOrphans) applyToStackDown(o *stackDownOptions) {
 	o.RemoveOrphans = bool(ro)
 }
 
+// RemoveVolumes will remove the named volumes declared in the volumes section of the compose files,
+// and the anonymous volumes attached to the containers, so that they don't leak across test runs
+type RemoveVolumes bool
+
+func (rv RemoveVolumes) applyToStackDown(o *stackDownOptions) {
+	o.Volumes = bool(rv)
+}
+
 // Wait won't return until containers reached the running|healthy state
 type Wait bool
 
@@ -64,6 +240,15 @@ func (w Wait) applyToStackUp(o *stackUpOptions) {
 	o.Wait = bool(w)
 }
 
+// WithDownTimeout sets how long Down waits for the containers to stop gracefully before killing them, overriding the
+// stop_grace_period of the services, 10 seconds by default. It gives slow services the time to flush their state,
+// or bounds the teardown of services ignoring the stop signal.
+func WithDownTimeout(timeout time.Duration) StackDownOption {
+	return stackDownOptionFunc(func(o *stackDownOptions) {
+		o.Timeout = &timeout
+	})
+}
+
 // RemoveImages used by services
 type RemoveImages uint8
 
@@ -76,12 +261,133 @@ func (ri RemoveImages) applyToStackDown(o *stackDownOptions) {
 	}
 }
 
+// ComposeProfiles selects the profiles of the services to start, services without profile are always started.
+// Used as ComposeStackOption it applies to every Up of the stack, as StackUpOption it overrides them for a single Up
+type ComposeProfiles []string
+
+// WithProfiles only starts the services of the stack matching one of the given profiles,
+// "*" selects all the services
+func WithProfiles(profiles ...string) ComposeProfiles {
+	return ComposeProfiles(profiles)
+}
+
+func (p ComposeProfiles) applyToComposeStack(o *composeStackOptions) {
+	o.Profiles = p
+}
+
+func (p ComposeProfiles) applyToStackUp(o *stackUpOptions) {
+	o.Profiles = p
+}
+
 type ComposeStackFiles []string
 
 func (f ComposeStackFiles) applyToComposeStack(o *composeStackOptions) {
 	o.Paths = f
 }
 
+// ComposeStackFS loads the compose files at the given paths of a file system, e.g. an embed.FS, after the ones
+// set with WithStackFiles. As compose reads the files from the disk, the whole file system is copied to a temporary
+// directory, so that the build contexts, env files or bind mounts the compose files refer to with relative paths are
+// found too. Use fs.Sub to only copy a directory of the file system. The temporary directory is removed by Down.
+func ComposeStackFS(fsys fs.FS, paths ...string) ComposeStackOption {
+	return composeStackFS{fsys: fsys, paths: paths}
+}
+
+type composeStackFS struct {
+	fsys  fs.FS
+	paths []string
+}
+
+func (f composeStackFS) applyToComposeStack(o *composeStackOptions) {
+	o.FS = f.fsys
+	o.FSPaths = f.paths
+}
+
+// ComposeStackURLs loads the compose files published at the given URLs, after the ones set with WithStackFiles
+// and ComposeStackFS. The expected SHA-256 checksum of a file can be set in the fragment of its URL, e.g.
+// https://example.com/stack.yml#sha256=<hex digest>: the file is then verified, and only downloaded once as it is
+// cached in the user cache directory. Files without checksum are downloaded again by each stack.
+func ComposeStackURLs(urls ...string) ComposeStackOption {
+	return composeStackURLs(urls)
+}
+
+type composeStackURLs []string
+
+func (u composeStackURLs) applyToComposeStack(o *composeStackOptions) {
+	o.URLs = u
+}
+
+// ComposeStackReaders loads compose files from readers, e.g. files generated by the test, after the ones set with
+// WithStackFiles, ComposeStackFS and ComposeStackURLs. The readers are read by NewDockerComposeWith, which writes them
+// to a temporary directory removed by Down, and reports the failures to read or write them. The relative paths of
+// a stack only loaded from readers resolve to the temporary directory.
+func ComposeStackReaders(readers ...io.Reader) ComposeStackOption {
+	return composeStackReaders(readers)
+}
+
+type composeStackReaders []io.Reader
+
+func (r composeStackReaders) applyToComposeStack(o *composeStackOptions) {
+	o.Readers = append(o.Readers, r...)
+}
+
+// WithImageSubstitutor rewrites the image of every service of the stack with the substitutor before it is pulled,
+// e.g. to pull them through the registry mirror of a corporate network with DockerHubMirror. Substitutors apply
+// in the order of the options. Services built from a Dockerfile keep the name of their image.
+func WithImageSubstitutor(s ImageSubstitutor) ComposeStackOption {
+	return composeImageSubstitutor{substitutor: s}
+}
+
+type composeImageSubstitutor struct {
+	substitutor ImageSubstitutor
+}
+
+func (s composeImageSubstitutor) applyToComposeStack(o *composeStackOptions) {
+	o.ImageSubstitutors = append(o.ImageSubstitutors, s.substitutor)
+}
+
+// WithoutReaper does not register the stack with the reaper, which otherwise removes its containers, networks and
+// volumes once the test session ends, even if the tests crashed before calling Down. Reused stacks are never
+// registered.
+type WithoutReaper bool
+
+func (r WithoutReaper) applyToComposeStack(o *composeStackOptions) {
+	o.SkipReaper = bool(r)
+}
+
+// WithServiceCommand overrides the command of a service, like command in a compose override file,
+// e.g. to run a server in debug mode. The command is not run by a shell.
+func WithServiceCommand(service string, cmd []string) ComposeStackOption {
+	return composeServiceOverride{service: service, command: cmd}
+}
+
+// WithServiceEntrypoint overrides the entrypoint of a service, like entrypoint in a compose override file.
+// The command of the service is passed to the new entrypoint as arguments.
+func WithServiceEntrypoint(service string, entrypoint []string) ComposeStackOption {
+	return composeServiceOverride{service: service, entrypoint: entrypoint}
+}
+
+type composeServiceOverride struct {
+	service    string
+	command    []string
+	entrypoint []string
+}
+
+func (o composeServiceOverride) applyToComposeStack(opts *composeStackOptions) {
+	if o.command != nil {
+		if opts.ServiceCommands == nil {
+			opts.ServiceCommands = make(map[string][]string)
+		}
+		opts.ServiceCommands[o.service] = o.command
+	}
+	if o.entrypoint != nil {
+		if opts.ServiceEntrypoints == nil {
+			opts.ServiceEntrypoints = make(map[string][]string)
+		}
+		opts.ServiceEntrypoints[o.service] = o.entrypoint
+	}
+}
+
 type StackIdentifier string
 
 func (f StackIdentifier) applyToComposeStack(o *composeStackOptions) {
@@ -92,6 +398,21 @@ func (f StackIdentifier) String() string {
 	return string(f)
 }
 
+// WithReuse shares the stack across tests and test packages: Up attaches to the running stack with the same
+// StackIdentifier if its services run with the same configuration, instead of recreating it, and Down leaves it
+// running. It requires a StackIdentifier.
+type WithReuse bool
+
+func (r WithReuse) applyToComposeStack(o *composeStackOptions) {
+	o.Reuse = bool(r)
+}
+
+// applyToComposeStack selects the container runtime of the stack, ProviderPodman runs it with the Podman API socket
+// of the current user, or the system one, instead of the Docker one
+func (t ProviderType) applyToComposeStack(o *composeStackOptions) {
+	o.Provider = t
+}
+
 const (
 	// RemoveImagesAll - remove all images used by the stack
 	RemoveImagesAll RemoveImages = iota
@@ -107,17 +428,71 @@ type dockerCompose struct {
 	// by default a UUID will be used
 	name string
 
+	// logger of the stack and of its containers, receiving the output of the Docker CLI when set with WithLogger
+	logger Logging
+
+	// whether the stack runs on Podman instead of Docker
+	podman bool
+
+	// temporary directory the compose files loaded with ComposeStackFS are copied to, removed by Down
+	tempDir string
+
+	// writes the compose files loaded with ComposeStackFS or ComposeStackReaders to a directory, nil if there are none
+	writeStackFiles func(dir string) error
+
+	// whether Down removed the temporary directory, whose compose files the next compilation writes again
+	tempDirRemoved bool
+
+	// whether the stack is shared, Up attaches to it if it is running with the same configuration and Down keeps it
+	reuse bool
+
+	// rewrite the images of the services before they are pulled
+	imageSubstitutors []ImageSubstitutor
+
+	// commands and entrypoints overriding the ones of the compose files, per service
+	serviceCommands    map[string][]string
+	serviceEntrypoints map[string][]string
+
+	// container runtime of the stack, running the reaper removing the stack once the session ends
+	provider ProviderType
+
+	// whether the stack is left to the caller instead of being registered with the reaper
+	skipReaper bool
+
+	// closes the connection to the reaper once the stack is torn down, nil until the stack is registered
+	terminationSignal chan bool
+
 	// paths to stack files that will be considered when compiling the final compose project
 	configs []string
 
+	// profiles of the services started by default, services without profile are always started
+	profiles []string
+
 	// wait strategies that are applied per service when starting the stack
 	// only one strategy can be added to a service, to use multiple use wait.ForAll(...)
 	waitStrategies map[string]wait.Strategy
 
+	// deadlines of the wait strategies per service, in addition to the own timeout of the strategies
+	waitTimeouts map[string]time.Duration
+
+	// log consumers that are attached per service once the stack is started
+	logConsumers map[string][]LogConsumer
+
+	// hooks executed in order once the stack is up and its services are ready, and before it is torn down
+	afterUpHooks    []ComposeStackHook
+	beforeDownHooks []ComposeStackHook
+
+	// containers of the stack currently streaming their logs to consumers
+	// their log producers are stopped when the stack is torn down
+	logProducers []*DockerContainer
+
 	// cache for containers that are part of the stack
 	// used in ServiceContainer(...) function to avoid calls to the Docker API
 	containers map[string]*DockerContainer
 
+	// whether the cache holds the containers of all the services, resolved once the stack is up
+	containersResolved bool
+
 	// docker/compose API service instance used to control the compose stack
 	composeService api.Service
 
@@ -128,6 +503,10 @@ type dockerCompose struct {
 	// e.g. environment settings, ...
 	projectOptions []cli.ProjectOptionsFn
 
+	// env files seeding the variable interpolation, in increasing precedence
+	// the variables set with WithEnv or WithOsEnv take precedence over them
+	envFiles []string
+
 	// compiled compose project
 	// can be nil if the stack wasn't started yet
 	project *types.Project
@@ -140,197 +519,1815 @@ func (d *dockerCompose) ServiceContainer(ctx context.Context, svcName string) (*
 	return d.lookupContainer(ctx, svcName)
 }
 
-func (d *dockerCompose) Services() []string {
-	d.lock.Lock()
-	defer d.lock.Unlock()
-
-	return d.project.ServiceNames()
-}
-
-func (d *dockerCompose) Down(ctx context.Context, opts ...StackDownOption) error {
+// AllServiceContainers returns the container of every service of the stack, by service name, the first replica of the
+// scaled services. The containers are resolved with a single call to the Docker API once the stack is up, and cached.
+func (d *dockerCompose) AllServiceContainers(ctx context.Context) (map[string]*DockerContainer, error) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	options := stackDownOptions{
-		DownOptions: api.DownOptions{
-			Project: d.project,
-		},
+	if !d.containersResolved {
+		if err := d.resolveContainers(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	for i := range opts {
-		opts[i].applyToStackDown(&options)
+	containers := make(map[string]*DockerContainer, len(d.containers))
+	for svc, c := range d.containers {
+		containers[svc] = c
 	}
 
-	return d.composeService.Down(ctx, d.name, options.DownOptions)
+	return containers, nil
 }
 
-func (d *dockerCompose) Up(ctx context.Context, opts ...StackUpOption) (err error) {
+// ServiceContainers returns the containers of all the replicas of a service, ordered by replica number
+func (d *dockerCompose) ServiceContainers(ctx context.Context, svcName string) ([]*DockerContainer, error) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	d.project, err = d.compileProject()
+	return d.lookupContainers(ctx, svcName)
+}
+
+// Stats returns the resource usage of the service, summed over its replicas, e.g. to assert a dependency stays within
+// its resource budget during a performance test. The replicas are sampled concurrently.
+func (d *dockerCompose) Stats(ctx context.Context, svcName string) (ContainerStats, error) {
+	containers, err := d.ServiceContainers(ctx, svcName)
 	if err != nil {
-		return err
+		return ContainerStats{}, err
 	}
 
-	upOptions := stackUpOptions{
-		Services:             d.project.ServiceNames(),
-		Recreate:             api.RecreateDiverged,
-		RecreateDependencies: api.RecreateDiverged,
-		Project:              d.project,
-	}
+	samples := make([]*ContainerStats, len(containers))
+	errs := make([]error, len(containers))
 
-	for i := range opts {
-		opts[i].applyToStackUp(&upOptions)
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		wg.Add(1)
+		go func(i int, c *DockerContainer) {
+			defer wg.Done()
+			samples[i], errs[i] = c.Stats(ctx)
+		}(i, c)
 	}
+	wg.Wait()
 
-	if len(upOptions.Services) != len(d.project.Services) {
-		sort.Strings(upOptions.Services)
-
-		filteredServices := make(types.Services, 0, len(d.project.Services))
-
-		for i := range d.project.Services {
-			if idx := sort.SearchStrings(upOptions.Services, d.project.Services[i].Name); idx < len(upOptions.Services) && upOptions.Services[idx] == d.project.Services[i].Name {
-				filteredServices = append(filteredServices, d.project.Services[i])
-			}
+	for i, err := range errs {
+		if err != nil {
+			return ContainerStats{}, fmt.Errorf("%w: failed to read the resource usage of container %s of service %s", err, containers[i].ID, svcName)
 		}
-
-		d.project.Services = filteredServices
 	}
 
-	err = d.composeService.Up(ctx, d.project, api.UpOptions{
-		Create: api.CreateOptions{
-			Services:             upOptions.Services,
-			Recreate:             upOptions.Recreate,
-			RecreateDependencies: upOptions.RecreateDependencies,
-			RemoveOrphans:        upOptions.RemoveOrphans,
-		},
-		Start: api.StartOptions{
-			Project: upOptions.Project,
-			Wait:    upOptions.Wait,
-		},
-	})
+	return sumStats(samples), nil
+}
 
+// Exec runs a command in the container of the service, like DockerContainer.Exec,
+// returning the exit code and the output of the command
+func (d *dockerCompose) Exec(ctx context.Context, svcName string, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error) {
+	container, err := d.serviceContainer(ctx, svcName)
 	if err != nil {
-		return err
-	}
-
-	if len(d.waitStrategies) == 0 {
-		return nil
+		return 0, nil, err
 	}
 
-	errGrp, errGrpCtx := errgroup.WithContext(ctx)
-
-	for svc, strategy := range d.waitStrategies { // pinning the variables
-		svc := svc
-		strategy := strategy
+	return container.Exec(ctx, cmd, options...)
+}
 
-		errGrp.Go(func() error {
-			target, err := d.lookupContainer(errGrpCtx, svc)
-			if err != nil {
-				return err
-			}
-			return strategy.WaitUntilReady(errGrpCtx, target)
-		})
+// ServiceHost returns the host the ports of the service are published on
+func (d *dockerCompose) ServiceHost(ctx context.Context, svcName string) (string, error) {
+	container, err := d.serviceContainer(ctx, svcName)
+	if err != nil {
+		return "", err
 	}
 
-	return errGrp.Wait()
+	return container.Host(ctx)
 }
 
-func (d *dockerCompose) WaitForService(s string, strategy wait.Strategy) ComposeStack {
-	d.lock.Lock()
-	defer d.lock.Unlock()
+// ServicePort returns the host port the given port of the service is published on
+func (d *dockerCompose) ServicePort(ctx context.Context, svcName string, port nat.Port) (nat.Port, error) {
+	container, err := d.serviceContainer(ctx, svcName)
+	if err != nil {
+		return "", err
+	}
 
-	d.waitStrategies[s] = strategy
-	return d
+	return container.MappedPort(ctx, port)
 }
 
-func (d *dockerCompose) WithEnv(m map[string]string) ComposeStack {
-	d.lock.Lock()
-	defer d.lock.Unlock()
+// Endpoint returns the proto://host:port string the given port of the service is reachable at,
+// the proto:// prefix is omitted if proto is empty
+func (d *dockerCompose) Endpoint(ctx context.Context, svcName string, port nat.Port, proto string) (string, error) {
+	container, err := d.serviceContainer(ctx, svcName)
+	if err != nil {
+		return "", err
+	}
 
-	d.projectOptions = append(d.projectOptions, withEnv(m))
-	return d
+	return container.PortEndpoint(ctx, port, proto)
 }
 
-func (d *dockerCompose) WithOsEnv() ComposeStack {
+// RunOneOff runs a command in a new one-off container of the service, like 'docker compose run',
+// waits for it to exit and returns its exit code and output. The container is created with the configuration,
+// networks and volumes of the service, e.g. to run migration or seed jobs defined in the compose file,
+// and is removed afterwards. If cmd is empty, the command of the service is run
+func (d *dockerCompose) RunOneOff(ctx context.Context, svcName string, cmd []string, options ...tcexec.ProcessOption) (*ExecResult, error) {
 	d.lock.Lock()
-	defer d.lock.Unlock()
-
-	d.projectOptions = append(d.projectOptions, cli.WithOsEnv)
-	return d
-}
-
-func (d *dockerCompose) lookupContainer(ctx context.Context, svcName string) (*DockerContainer, error) {
-	if container, ok := d.containers[svcName]; ok {
-		return container, nil
-	}
+	project := d.project
+	d.lock.Unlock()
 
-	listOptions := types2.ContainerListOptions{
-		All: true,
-		Filters: filters.NewArgs(
-			filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name)),
-			filters.Arg("label", fmt.Sprintf("%s=%s", api.ServiceLabel, svcName)),
-		),
+	if project == nil {
+		return nil, fmt.Errorf("cannot run service %s: the stack is not started", svcName)
 	}
 
-	containers, err := d.dockerClient.ContainerList(ctx, listOptions)
+	service, err := project.GetService(svcName)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(containers) == 0 {
-		return nil, fmt.Errorf("no container found for service name %s", svcName)
+	processOptions := tcexec.NewProcessOptions(cmd)
+	for _, o := range options {
+		o.Apply(processOptions)
 	}
 
-	containerInstance := containers[0]
-	container := &DockerContainer{
-		ID: containerInstance.ID,
-		provider: &DockerProvider{
-			client: d.dockerClient,
-		},
+	config, hostConfig := oneOffContainerConfig(project, service, processOptions.ExecConfig)
+
+	networks := oneOffNetworks(project, service)
+	networkingConfig := &network.NetworkingConfig{}
+	if len(networks) > 0 && hostConfig.NetworkMode == "" {
+		// a container can only be created in one network, it is connected to the others before it starts
+		networkingConfig.EndpointsConfig = map[string]*network.EndpointSettings{networks[0]: {}}
 	}
 
-	d.containers[svcName] = container
+	created, err := d.dockerClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, "")
+	if err != nil {
+		return nil, err
+	}
 
-	return container, nil
-}
+	defer func() {
+		removeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = d.dockerClient.ContainerRemove(removeCtx, created.ID, types2.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	}()
 
-func (d *dockerCompose) compileProject() (*types.Project, error) {
-	const nameAndDefaultConfigPath = 2
-	projectOptions := make([]cli.ProjectOptionsFn, len(d.projectOptions), len(d.projectOptions)+nameAndDefaultConfigPath)
+	if hostConfig.NetworkMode == "" && len(networks) > 1 {
+		for _, n := range networks[1:] {
+			if err := d.dockerClient.NetworkConnect(ctx, n, created.ID, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-	copy(projectOptions, d.projectOptions)
-	projectOptions = append(projectOptions, cli.WithName(d.name), cli.WithDefaultConfigPath)
+	if err := d.dockerClient.ContainerStart(ctx, created.ID, types2.ContainerStartOptions{}); err != nil {
+		return nil, err
+	}
 
-	compiledOptions, err := cli.NewProjectOptions(d.configs, projectOptions...)
-	if err != nil {
+	var exitCode int
+	statusCh, errCh := d.dockerClient.ContainerWait(ctx, created.ID, container.WaitConditionNotRunning)
+	select {
+	case status := <-statusCh:
+		if status.Error != nil {
+			return nil, errors.New(status.Error.Message)
+		}
+		exitCode = int(status.StatusCode)
+	case err := <-errCh:
 		return nil, err
 	}
 
-	proj, err := cli.ProjectFromOptions(compiledOptions)
+	logs, err := d.dockerClient.ContainerLogs(ctx, created.ID, types2.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
 	if err != nil {
 		return nil, err
 	}
+	defer logs.Close()
 
-	for i, s := range proj.Services {
-		s.CustomLabels = map[string]string{
-			api.ProjectLabel:     proj.Name,
-			api.ServiceLabel:     s.Name,
-			api.VersionLabel:     api.ComposeVersion,
-			api.WorkingDirLabel:  proj.WorkingDir,
-			api.ConfigFilesLabel: strings.Join(proj.ComposeFiles, ","),
-			api.OneoffLabel:      "False", // default, will be overridden by `run` command
+	var stdout, stderr, combined bytes.Buffer
+	if _, err := stdcopy.StdCopy(io.MultiWriter(&stdout, &combined), io.MultiWriter(&stderr, &combined), logs); err != nil {
+		return nil, err
+	}
+
+	return &ExecResult{
+		ExitCode: exitCode,
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		combined: combined.Bytes(),
+	}, nil
+}
+
+func (d *dockerCompose) Services() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.project.ServiceNames()
+}
+
+// Project returns a snapshot of the compose project of the stack, with its interpolated configuration,
+// compiled by Up, or by Validate before the stack is started. It is nil if neither was called.
+// The services are copied, but the configuration they refer to, e.g. their environment, must not be modified.
+func (d *dockerCompose) Project() *types.Project {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.project == nil {
+		return nil
+	}
+
+	project := *d.project
+	project.Services = append(types.Services(nil), d.project.Services...)
+
+	return &project
+}
+
+// Network returns the network the services of the stack are attached to, i.e. the default network of the project,
+// or the first network used by the services in alphabetical order if none of them uses the default one. Other
+// containers join it with WithComposeNetwork to resolve the services by name. The network belongs to the stack,
+// which removes it on Down.
+func (d *dockerCompose) Network(ctx context.Context) (*DockerNetwork, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	name, err := composeNetworkName(d.project)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := d.dockerClient.NetworkInspect(ctx, name, types2.NetworkInspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DockerNetwork{
+		ID:       resource.ID,
+		Driver:   resource.Driver,
+		Name:     resource.Name,
+		provider: &DockerProvider{client: d.dockerClient},
+	}, nil
+}
+
+// Networks returns the networks created by compose for the stack, found by the label of the project and sorted by
+// name, e.g. to inspect their IPAM configuration or to connect other containers. The external networks the services
+// are attached to are not part of them. The networks belong to the stack, which removes them on Down.
+func (d *dockerCompose) Networks(ctx context.Context) ([]*DockerNetwork, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	resources, err := d.dockerClient.NetworkList(ctx, types2.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name))),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+
+	provider := &DockerProvider{client: d.dockerClient}
+	networks := make([]*DockerNetwork, 0, len(resources))
+	for _, resource := range resources {
+		networks = append(networks, &DockerNetwork{
+			ID:       resource.ID,
+			Driver:   resource.Driver,
+			Name:     resource.Name,
+			provider: provider,
+		})
+	}
+
+	return networks, nil
+}
+
+// Validate compiles the compose project without starting it, with the profiles and the environment of the stack,
+// returning the errors of the compose files and of the interpolation of their variables, e.g. a missing required one.
+// The compiled project is returned by Project until the stack is started.
+func (d *dockerCompose) Validate(ctx context.Context) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	project, err := d.compileProject(d.profiles)
+	if err != nil {
+		return fmt.Errorf("invalid compose project: %w", err)
+	}
+
+	// Up would fail looking up the container of a service waited for which is not part of the project
+	for svc := range d.waitStrategies {
+		if _, err := project.GetService(svc); err != nil {
+			return fmt.Errorf("invalid compose project: no service %s to wait for", svc)
+		}
+	}
+
+	if d.project == nil {
+		d.project = project
+	}
+
+	return nil
+}
+
+func (d *dockerCompose) Down(ctx context.Context, opts ...StackDownOption) error {
+	// the stack is torn down even if a hook failed, the error is reported afterwards
+	hookErr := d.runBeforeDownHooks(ctx)
+
+	if err := d.down(ctx, opts...); err != nil {
+		return err
+	}
+	return hookErr
+}
+
+func (d *dockerCompose) down(ctx context.Context, opts ...StackDownOption) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	options := stackDownOptions{
+		DownOptions: api.DownOptions{
+			Project: d.project,
+		},
+	}
+
+	for i := range opts {
+		opts[i].applyToStackDown(&options)
+	}
+
+	for _, c := range d.logProducers {
+		_ = c.StopLogProducer()
+	}
+	d.logProducers = nil
+	d.clearContainers()
+
+	var err error
+	if d.reuse {
+		d.logger.Printf("compose stack %s is reused, leaving it running", d.name)
+	} else {
+		err = d.composeService.Down(ctx, d.name, options.DownOptions)
+	}
+
+	select {
+	// close reaper if it was created
+	case d.terminationSignal <- true:
+	default:
+	}
+	d.terminationSignal = nil
+
+	// the compose files are not needed to tear down the stack again, only its name and compiled project
+	if d.tempDir != "" {
+		if rmErr := os.RemoveAll(d.tempDir); rmErr != nil && err == nil {
+			err = rmErr
+		}
+		d.tempDirRemoved = true
+	}
+
+	return err
+}
+
+func (d *dockerCompose) Up(ctx context.Context, opts ...StackUpOption) error {
+	if err := d.up(ctx, opts...); err != nil {
+		return err
+	}
+	return d.runAfterUpHooks(ctx)
+}
+
+func (d *dockerCompose) up(ctx context.Context, opts ...StackUpOption) (err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	upOptions := stackUpOptions{
+		Recreate:             api.RecreateDiverged,
+		RecreateDependencies: api.RecreateDiverged,
+		Profiles:             d.profiles,
+	}
+
+	for i := range opts {
+		opts[i].applyToStackUp(&upOptions)
+	}
+
+	if upOptions.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, upOptions.Timeout)
+		defer cancel()
+	}
+
+	d.project, err = d.compileProject(upOptions.Profiles)
+	if err != nil {
+		return err
+	}
+
+	if err = scaleServices(d.project, upOptions.Scale); err != nil {
+		return err
+	}
+
+	if err = overrideServiceEnv(d.project, upOptions.ServiceEnv); err != nil {
+		return err
+	}
+
+	if err = attachExternalNetworks(d.project, upOptions.ExternalNetworks); err != nil {
+		return err
+	}
+
+	if err = d.writeFileObjectContents(upOptions.SecretContents, upOptions.ConfigContents); err != nil {
+		return err
+	}
+
+	if upOptions.PullPolicy != "" {
+		for i := range d.project.Services {
+			d.project.Services[i].PullPolicy = string(upOptions.PullPolicy)
+		}
+	}
+
+	upOptions.Project = d.project
+	if upOptions.Services == nil {
+		upOptions.Services = d.project.ServiceNames()
+	}
+
+	// the logs of the services which did not get ready are the first thing to look at when Up fails
+	if upOptions.FailureLogLines == 0 {
+		upOptions.FailureLogLines = defaultFailureLogLines
+	}
+	if upOptions.FailureLogLines > 0 {
+		services := upOptions.Services
+		defer func() {
+			if err != nil {
+				err = d.withNotReadyServiceLogs(err, services, upOptions.FailureLogLines)
+			}
+		}()
+	}
+
+	if len(upOptions.Services) != len(d.project.Services) {
+		sort.Strings(upOptions.Services)
+
+		filteredServices := make(types.Services, 0, len(d.project.Services))
+
+		for i := range d.project.Services {
+			if idx := sort.SearchStrings(upOptions.Services, d.project.Services[i].Name); idx < len(upOptions.Services) && upOptions.Services[idx] == d.project.Services[i].Name {
+				filteredServices = append(filteredServices, d.project.Services[i])
+			}
+		}
+
+		d.project.Services = filteredServices
+	}
+
+	if upOptions.Build != nil {
+		buildOptions := *upOptions.Build
+		if len(buildOptions.Services) == 0 {
+			buildOptions.Services = upOptions.Services
+		}
+
+		if err = d.composeService.Build(ctx, d.project, buildOptions); err != nil {
+			return err
+		}
+	}
+
+	// compose waits for all the services to be running, which one-shot services never are once they completed
+	oneShot := d.oneShotServices()
+
+	// a reused stack is meant to be attached to by other sessions, and the containers of a stack which is not reaped
+	// are not labelled with the session
+	if d.reaped() {
+		if err = d.checkProjectName(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = d.registerWithReaper(ctx); err != nil {
+		return err
+	}
+
+	if d.reuse {
+		reusable, err := d.reusableStack(ctx, upOptions.Services, oneShot)
+		if err != nil {
+			return err
+		}
+		if reusable {
+			d.logger.Printf("reusing compose stack %s", d.name)
+			if err = d.startLogProducers(ctx); err != nil {
+				return err
+			}
+			if err = d.waitForServices(ctx, nil); err != nil {
+				return err
+			}
+			return d.resolveContainers(ctx)
+		}
+	}
+
+	// each service counts against the limit of concurrent starts until the stack is up
+	release, err := newStartLimiter(configureTC()).acquire(ctx, len(upOptions.Services))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// the containers of the services may be recreated
+	d.clearContainers()
+
+	err = d.composeService.Up(ctx, d.project, api.UpOptions{
+		Create: api.CreateOptions{
+			Services:             upOptions.Services,
+			Recreate:             upOptions.Recreate,
+			RecreateDependencies: upOptions.RecreateDependencies,
+			RemoveOrphans:        upOptions.RemoveOrphans,
+		},
+		Start: api.StartOptions{
+			Project: upOptions.Project,
+			Wait:    upOptions.Wait && len(oneShot) == 0,
+		},
+	})
+
+	if err != nil {
+		if upOptions.Wait {
+			return d.unhealthyDependencyError(upOptions.Services, err)
+		}
+		return err
+	}
+
+	if upOptions.Wait && len(oneShot) > 0 {
+		if err = d.waitRunningOrHealthy(ctx, upOptions.Services, oneShot); err != nil {
+			return err
+		}
+	}
+
+	// consumers are attached before waiting, so that the logs of services not becoming ready are streamed too
+	if err = d.startLogProducers(ctx); err != nil {
+		return err
+	}
+
+	if err = d.waitForServices(ctx, nil); err != nil {
+		return err
+	}
+
+	return d.resolveContainers(ctx)
+}
+
+// Stop stops the containers of the given services, or of all services if none is given,
+// keeping the containers, networks and volumes of the stack to start them again with Start
+func (d *dockerCompose) Stop(ctx context.Context, services ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.composeService.Stop(ctx, d.name, api.StopOptions{
+		Project:  d.project,
+		Services: services,
+	})
+}
+
+// Kill sends the signal to the containers of the given services, or of all services if none is given,
+// e.g. SIGKILL to simulate the crash of a dependency. An empty signal defaults to SIGKILL.
+func (d *dockerCompose) Kill(ctx context.Context, signal string, services ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.composeService.Kill(ctx, d.name, api.KillOptions{
+		Project:  d.project,
+		Services: services,
+		Signal:   signal,
+	})
+}
+
+// Pause freezes the processes of the containers of the given services, or of all services if none is given,
+// e.g. to simulate a stalled peer or a network partition, until Unpause is called
+func (d *dockerCompose) Pause(ctx context.Context, services ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.composeService.Pause(ctx, d.name, api.PauseOptions{
+		Project:  d.project,
+		Services: services,
+	})
+}
+
+// Unpause resumes the processes of the containers of the given services, or of all services if none is given
+func (d *dockerCompose) Unpause(ctx context.Context, services ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.composeService.UnPause(ctx, d.name, api.PauseOptions{
+		Project:  d.project,
+		Services: services,
+	})
+}
+
+// Start starts the stopped containers of the given services, or of all services if none is given,
+// and waits until they are ready again according to their wait strategies
+func (d *dockerCompose) Start(ctx context.Context, services ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	project := d.project
+	if project != nil {
+		// the compose service restricts the project to the started services, keep the stack project untouched
+		projectCopy := *project
+		projectCopy.DisabledServices = append(types.Services(nil), project.DisabledServices...)
+		project = &projectCopy
+	}
+
+	err := d.composeService.Start(ctx, d.name, api.StartOptions{
+		Project:  project,
+		Services: services,
+		Wait:     true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.waitForServices(ctx, services)
+}
+
+// RestartService restarts the containers of the service and waits until they are ready again according to the
+// wait strategy of the service. The timeout overrides the time to wait for the containers to stop before killing
+// them, nil keeps the one of the compose file.
+func (d *dockerCompose) RestartService(ctx context.Context, svc string, timeout *time.Duration) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	err := d.composeService.Restart(ctx, d.name, api.RestartOptions{
+		Project:  d.project,
+		Timeout:  timeout,
+		Services: []string{svc},
+	})
+	if err != nil {
+		return err
+	}
+
+	// resolve the container again on the next lookup, in case it was recreated
+	delete(d.containers, svc)
+	d.containersResolved = false
+
+	return d.waitForServices(ctx, []string{svc})
+}
+
+// Ps lists the running containers of the stack with their state, health and published ports,
+// sorted by service name and container name
+func (d *dockerCompose) Ps(ctx context.Context) ([]ServiceStatus, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	summaries, err := d.composeService.Ps(ctx, d.name, api.PsOptions{
+		Project: d.project,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceStatuses(summaries), nil
+}
+
+func serviceStatuses(summaries []api.ContainerSummary) []ServiceStatus {
+	statuses := make([]ServiceStatus, 0, len(summaries))
+	for _, summary := range summaries {
+		status := ServiceStatus{
+			ContainerID: summary.ID,
+			Name:        summary.Name,
+			Service:     summary.Service,
+			State:       summary.State,
+			Health:      summary.Health,
+			ExitCode:    summary.ExitCode,
+		}
+
+		publishers := append(api.PortPublishers(nil), summary.Publishers...)
+		sort.Sort(publishers)
+		for _, publisher := range publishers {
+			status.Ports = append(status.Ports, PublishedPort{
+				URL:           publisher.URL,
+				TargetPort:    publisher.TargetPort,
+				PublishedPort: publisher.PublishedPort,
+				Protocol:      publisher.Protocol,
+			})
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Service != statuses[j].Service {
+			return statuses[i].Service < statuses[j].Service
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// Events streams the runtime events of the containers of the given services, or of all the services of the stack,
+// like docker compose events, until the context is cancelled. The subscription is active once Events returns, so
+// that the events of the actions performed afterwards are received, e.g. a die event after Kill. The channel is
+// closed once the context is cancelled or the stream fails. Events of one-off containers are skipped.
+func (d *dockerCompose) Events(ctx context.Context, services ...string) (<-chan ComposeEvent, error) {
+	messages, errs := d.dockerClient.Events(ctx, types2.EventsOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", "container"),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name)),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.OneoffLabel, "False")),
+		),
+	})
+
+	// the subscription failed if the stream already stopped
+	select {
+	case err := <-errs:
+		if err != nil {
+			return nil, err
+		}
+	default:
+	}
+
+	selected := make(map[string]bool, len(services))
+	for _, s := range services {
+		selected[s] = true
+	}
+
+	events := make(chan ComposeEvent)
+	go func() {
+		defer close(events)
+
+		for {
+			select {
+			case message := <-messages:
+				service := message.Actor.Attributes[api.ServiceLabel]
+				if len(selected) > 0 && !selected[service] {
+					continue
+				}
+
+				event := ComposeEvent{
+					Timestamp:  time.Unix(0, message.TimeNano),
+					Service:    service,
+					Container:  message.Actor.ID,
+					Status:     message.Action,
+					Attributes: make(map[string]string, len(message.Actor.Attributes)),
+				}
+				if message.TimeNano == 0 {
+					event.Timestamp = time.Unix(message.Time, 0)
+				}
+				for k, v := range message.Actor.Attributes {
+					if !strings.HasPrefix(k, "com.docker.compose.") {
+						event.Attributes[k] = v
+					}
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			case err := <-errs:
+				if err != nil && ctx.Err() == nil {
+					d.logger.Printf("compose stack %s: events stream failed: %s", d.name, err)
+				}
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// reusableStack reports whether the containers of the services are running with the configuration of the project,
+// comparing the configuration hashes compose labels them with. One-shot services may have completed.
+func (d *dockerCompose) reusableStack(ctx context.Context, services []string, oneShot map[string]bool) (bool, error) {
+	containers, err := d.dockerClient.ContainerList(ctx, types2.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name)),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.OneoffLabel, "False")),
+		),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	hashes := make(map[string]map[string]bool)
+	for _, c := range containers {
+		svc := c.Labels[api.ServiceLabel]
+		if c.State != "running" && !oneShot[svc] {
+			continue
+		}
+		if hashes[svc] == nil {
+			hashes[svc] = make(map[string]bool)
+		}
+		hashes[svc][c.Labels[api.ConfigHashLabel]] = true
+	}
+
+	for _, svc := range services {
+		service, err := d.project.GetService(svc)
+		if err != nil {
+			return false, err
+		}
+
+		hash, err := compose.ServiceHash(service)
+		if err != nil {
+			return false, err
+		}
+		if !hashes[svc][hash] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// oneShotServices returns the services waited for with wait.ForExit and which are not restarted by the daemon,
+// which are ready once they completed instead of running
+func (d *dockerCompose) oneShotServices() map[string]bool {
+	oneShot := make(map[string]bool)
+
+	for svc, strategy := range d.waitStrategies {
+		if _, ok := strategy.(*wait.ExitStrategy); !ok {
+			continue
+		}
+
+		service, err := d.project.GetService(svc)
+		if err != nil {
+			continue
+		}
+		if service.Restart == "" || service.Restart == types.RestartPolicyNo {
+			oneShot[svc] = true
+		}
+	}
+
+	return oneShot
+}
+
+// waitRunningOrHealthy waits for the containers of the services to be running, and healthy if they have a health
+// check, like compose does when waiting, except for the one-shot services which are waited for by their strategy
+func (d *dockerCompose) waitRunningOrHealthy(ctx context.Context, services []string, oneShot map[string]bool) error {
+	errGrp, errGrpCtx := errgroup.WithContext(ctx)
+
+	for _, svc := range services {
+		if oneShot[svc] {
+			continue
+		}
+
+		svc := svc
+		errGrp.Go(func() error {
+			containers, err := d.lookupContainers(errGrpCtx, svc)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range containers {
+				if err := waitRunningOrHealthy(errGrpCtx, c); err != nil {
+					return &serviceNotReadyError{service: svc, err: fmt.Errorf("service %s not ready: %w", svc, err)}
+				}
+			}
+			return nil
+		})
+	}
+
+	return errGrp.Wait()
+}
+
+func waitRunningOrHealthy(ctx context.Context, c *DockerContainer) error {
+	for {
+		state, err := c.State(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !state.Running && state.Status != "created":
+			return fmt.Errorf("container %s exited with code %d", c.ID[:12], state.ExitCode)
+		case state.Health != nil && state.Health.Status == types2.Unhealthy:
+			return fmt.Errorf("container %s is unhealthy%s", c.ID[:12], lastHealthcheck(state.Health))
+		case state.Running && (state.Health == nil || state.Health.Status == types2.Healthy):
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// unhealthyDependencyError explains why compose failed to start the services, when it waited for a dependency declared
+// with the service_healthy condition: the first dependency which is not healthy, in the order of the dependency graph,
+// is reported with the output of its last health check. The error of compose is returned as is otherwise.
+func (d *dockerCompose) unhealthyDependencyError(services []string, upErr error) error {
+	// the context of Up may be done, e.g. when a dependency was still starting at the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	checked := make(map[string]bool)
+	var depErr error
+
+	_ = d.project.WithServices(services, func(service types.ServiceConfig) error {
+		deps := make([]string, 0, len(service.DependsOn))
+		for dep, config := range service.DependsOn {
+			if config.Condition == types.ServiceConditionHealthy && !checked[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			checked[dep] = true
+			if err := d.checkHealthy(ctx, dep); err != nil {
+				depErr = fmt.Errorf("%w: dependency %s of service %s is not healthy: %s", upErr, dep, service.Name, err)
+				return depErr
+			}
+		}
+		return nil
+	})
+
+	if depErr != nil {
+		return depErr
+	}
+	return upErr
+}
+
+// checkHealthy returns an error describing the first container of the service which is not healthy
+func (d *dockerCompose) checkHealthy(ctx context.Context, svc string) error {
+	containers, err := d.lookupContainers(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		state, err := c.State(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case state.Health == nil:
+			return fmt.Errorf("container %s has no health check", c.ID[:12])
+		case !state.Running:
+			return fmt.Errorf("container %s exited with code %d%s", c.ID[:12], state.ExitCode, lastHealthcheck(state.Health))
+		case state.Health.Status != types2.Healthy:
+			return fmt.Errorf("container %s is %s%s", c.ID[:12], state.Health.Status, lastHealthcheck(state.Health))
+		}
+	}
+
+	return nil
+}
+
+// lastHealthcheck describes the result of the last health check of a container, with its output
+func lastHealthcheck(health *types2.Health) string {
+	if health == nil || len(health.Log) == 0 {
+		return ""
+	}
+
+	last := health.Log[len(health.Log)-1]
+	return fmt.Sprintf(", last health check exited with code %d: %s", last.ExitCode, strings.TrimSpace(last.Output))
+}
+
+// waitForServices applies the wait strategies of the given services in parallel, or of all services if none is given
+func (d *dockerCompose) waitForServices(ctx context.Context, services []string) error {
+	if len(d.waitStrategies) == 0 {
+		return nil
+	}
+
+	errGrp, errGrpCtx := errgroup.WithContext(ctx)
+
+	for svc, strategy := range d.waitStrategies { // pinning the variables
+		svc := svc
+		strategy := strategy
+
+		if len(services) > 0 && !containsString(services, svc) {
+			continue
+		}
+
+		timeout, hasTimeout := d.waitTimeouts[svc]
+
+		errGrp.Go(func() error {
+			targets, err := d.lookupContainers(errGrpCtx, svc)
+			if err != nil {
+				return err
+			}
+
+			waitCtx := errGrpCtx
+			if hasTimeout {
+				var cancel context.CancelFunc
+				waitCtx, cancel = context.WithTimeout(errGrpCtx, timeout)
+				defer cancel()
+			}
+
+			// the strategy is run against every replica of the service
+			errs := make([]error, len(targets))
+			var wg sync.WaitGroup
+			for i, target := range targets {
+				wg.Add(1)
+				go func(i int, target *DockerContainer) {
+					defer wg.Done()
+					errs[i] = strategy.WaitUntilReady(waitCtx, target)
+				}(i, target)
+			}
+			wg.Wait()
+
+			if err := replicasNotReadyError(svc, errs, waitCtx.Err() == context.DeadlineExceeded); err != nil {
+				return &serviceNotReadyError{service: svc, err: err}
+			}
+			return nil
+		})
+	}
+
+	return errGrp.Wait()
+}
+
+// serviceNotReadyError is the failure of a service to get ready, e.g. of its wait strategy, naming the service
+type serviceNotReadyError struct {
+	service string
+	err     error
+}
+
+func (e *serviceNotReadyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *serviceNotReadyError) Unwrap() error {
+	return e.err
+}
+
+// withNotReadyServiceLogs adds to the error of a failed Up the last log lines of the containers of the services which
+// are not ready, i.e. the service the error names, and the containers not running or not healthy. One-shot services
+// which completed successfully are ready.
+func (d *dockerCompose) withNotReadyServiceLogs(upErr error, services []string, lines int) error {
+	// the context of Up may be done, e.g. when a service was still starting at the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var notReady *serviceNotReadyError
+	errors.As(upErr, &notReady)
+	oneShot := d.oneShotServices()
+
+	sorted := append([]string(nil), services...)
+	sort.Strings(sorted)
+
+	var dump strings.Builder
+	for _, svc := range sorted {
+		containers, err := d.lookupContainers(ctx, svc)
+		if err != nil {
+			// the containers of the service were not created
+			continue
+		}
+
+		for i, c := range containers {
+			if notReady == nil || notReady.service != svc {
+				state, err := c.State(ctx)
+				if err != nil || containerReady(state, oneShot[svc]) {
+					continue
+				}
+			}
+
+			logs, err := c.tailLogs(ctx, lines)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&dump, "\n--- last %d log lines of service %s, replica %d ---\n%s", lines, svc, i+1, logs)
+		}
+	}
+
+	if dump.Len() == 0 {
+		return upErr
+	}
+	return fmt.Errorf("%w%s", upErr, dump.String())
+}
+
+// containerReady reports whether the container of a service is running and healthy, or completed successfully if
+// the service is a one-shot service
+func containerReady(state *types2.ContainerState, oneShot bool) bool {
+	if oneShot && !state.Running {
+		return state.Status == "exited" && state.ExitCode == 0
+	}
+	return state.Running && (state.Health == nil || state.Health.Status == types2.Healthy)
+}
+
+// replicasNotReadyError reports the replicas of the service whose wait strategy failed, given the errors of the
+// strategy per replica, nil if all of them are ready. The error wraps the one of the first failed replica.
+func replicasNotReadyError(svc string, errs []error, deadlineExceeded bool) error {
+	var failed []int
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	status := "not ready"
+	if deadlineExceeded {
+		status = "not ready before the deadline"
+	}
+
+	first := errs[failed[0]]
+	if len(errs) == 1 {
+		return fmt.Errorf("service %s %s: %w", svc, status, first)
+	}
+
+	var others strings.Builder
+	for _, i := range failed[1:] {
+		fmt.Fprintf(&others, "; replica %d: %v", i+1, errs[i])
+	}
+	return fmt.Errorf("service %s %s, %d of %d replicas failed: replica %d: %w%s",
+		svc, status, len(failed), len(errs), failed[0]+1, first, others.String())
+}
+
+func (d *dockerCompose) WaitForService(s string, strategy wait.Strategy, opts ...WaitForServiceOption) ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	options := waitForServiceOptions{}
+	for i := range opts {
+		opts[i].applyToWaitForService(&options)
+	}
+
+	d.waitStrategies[s] = strategy
+	if options.Timeout > 0 {
+		d.waitTimeouts[s] = options.Timeout
+	} else {
+		delete(d.waitTimeouts, s)
+	}
+	return d
+}
+
+// WithLogConsumer streams the STDOUT and STDERR of the given service to the consumer once the stack is started,
+// until it is torn down
+func (d *dockerCompose) WithLogConsumer(s string, consumer LogConsumer) ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.logConsumers[s] = append(d.logConsumers[s], consumer)
+	return d
+}
+
+// OnAfterUp registers a hook executed once the stack is up and its services are ready, e.g. to seed a database.
+// The hooks are executed in the order they are registered, Up fails at the first failing one.
+func (d *dockerCompose) OnAfterUp(hook ComposeStackHook) ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.afterUpHooks = append(d.afterUpHooks, hook)
+	return d
+}
+
+// OnBeforeDown registers a hook executed before the stack is torn down, while its containers still exist, e.g. to
+// capture the logs of the services or dump a database after a failed test. All the hooks are executed in the order
+// they are registered, and the stack is torn down even if some of them fail.
+func (d *dockerCompose) OnBeforeDown(hook ComposeStackHook) ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.beforeDownHooks = append(d.beforeDownHooks, hook)
+	return d
+}
+
+// runAfterUpHooks executes the after-up hooks, stopping at the first failure. Like the before-down hooks, they are
+// executed without holding the lock of the stack, so that they can use it.
+func (d *dockerCompose) runAfterUpHooks(ctx context.Context) error {
+	d.lock.RLock()
+	hooks := d.afterUpHooks
+	d.lock.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, d); err != nil {
+			return fmt.Errorf("%w: after-up hook failed", err)
+		}
+	}
+	return nil
+}
+
+// runBeforeDownHooks executes all the before-down hooks, even if some of them fail
+func (d *dockerCompose) runBeforeDownHooks(ctx context.Context) error {
+	d.lock.RLock()
+	hooks := d.beforeDownHooks
+	d.lock.RUnlock()
+
+	var firstErr error
+	for _, hook := range hooks {
+		if err := hook(ctx, d); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%w: before-down hook failed", err)
+		}
+	}
+	return firstErr
+}
+
+func (d *dockerCompose) WithEnv(m map[string]string) ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.projectOptions = append(d.projectOptions, withEnv(m))
+	return d
+}
+
+// WithEnvStruct seeds the variable interpolation with the tagged fields of a struct, or of a pointer to a struct, e.g.
+// `compose:"POSTGRES_PORT"`, so that a typed test configuration drives the compose files. Like with WithEnv, a variable
+// can only be set once. The struct is read at once, and an invalid one fails Up.
+func (d *dockerCompose) WithEnvStruct(v interface{}) ComposeStack {
+	env, err := flattenEnvStruct(v)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.projectOptions = append(d.projectOptions, func(options *cli.ProjectOptions) error {
+		if err != nil {
+			return err
+		}
+		return withEnv(env)(options)
+	})
+	return d
+}
+
+// WithEnvFile seeds the variable interpolation with the given env files, e.g. .env files outside
+// the working directory. Later files override earlier ones, and variables set with WithEnv or WithOsEnv
+// override all of them
+func (d *dockerCompose) WithEnvFile(paths ...string) ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.envFiles = append(d.envFiles, paths...)
+	return d
+}
+
+func (d *dockerCompose) WithOsEnv() ComposeStack {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.projectOptions = append(d.projectOptions, cli.WithOsEnv)
+	return d
+}
+
+func (d *dockerCompose) startLogProducers(ctx context.Context) error {
+	for svc, consumers := range d.logConsumers {
+		container, err := d.lookupContainer(ctx, svc)
+		if err != nil {
+			return err
+		}
+
+		for _, consumer := range consumers {
+			container.FollowOutput(consumer)
+		}
+
+		if err := container.StartLogProducer(ctx); err != nil {
+			return err
+		}
+		d.logProducers = append(d.logProducers, container)
+	}
+
+	return nil
+}
+
+// serviceContainer looks the container of the service up, only holding the lock during the lookup
+// so that slow operations on the container don't block the stack
+func (d *dockerCompose) serviceContainer(ctx context.Context, svcName string) (*DockerContainer, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.lookupContainer(ctx, svcName)
+}
+
+func (d *dockerCompose) lookupContainer(ctx context.Context, svcName string) (*DockerContainer, error) {
+	if container, ok := d.containers[svcName]; ok {
+		return container, nil
+	}
+
+	containers, err := d.lookupContainers(ctx, svcName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first replica of a scaled service
+	container := containers[0]
+	d.containers[svcName] = container
+
+	return container, nil
+}
+
+// resolveContainers caches the first replica of every service of the stack, listing the containers of the stack once
+func (d *dockerCompose) resolveContainers(ctx context.Context) error {
+	listOptions := types2.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name)),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.OneoffLabel, "False")),
+		),
+	}
+
+	containers, err := d.dockerClient.ContainerList(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	first := make(map[string]types2.Container, len(containers))
+	for _, c := range containers {
+		svc := c.Labels[api.ServiceLabel]
+		if current, ok := first[svc]; !ok || containerNumber(c) < containerNumber(current) {
+			first[svc] = c
+		}
+	}
+
+	provider := &DockerProvider{
+		client: d.dockerClient,
+	}
+
+	d.clearContainers()
+	for svc, c := range first {
+		d.containers[svc] = &DockerContainer{
+			ID:           c.ID,
+			provider:     provider,
+			stopProducer: make(chan bool),
+			logger:       d.logger,
+		}
+	}
+	d.containersResolved = true
+
+	return nil
+}
+
+// clearContainers empties the cache of the containers of the stack, they are resolved again on the next lookup
+func (d *dockerCompose) clearContainers() {
+	d.containers = make(map[string]*DockerContainer)
+	d.containersResolved = false
+}
+
+// lookupContainers returns all the containers of a service, ordered by replica number
+func (d *dockerCompose) lookupContainers(ctx context.Context, svcName string) ([]*DockerContainer, error) {
+	listOptions := types2.ContainerListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name)),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.ServiceLabel, svcName)),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.OneoffLabel, "False")),
+		),
+	}
+
+	containers, err := d.dockerClient.ContainerList(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no container found for service name %s", svcName)
+	}
+
+	sort.Slice(containers, func(i, j int) bool {
+		return containerNumber(containers[i]) < containerNumber(containers[j])
+	})
+
+	provider := &DockerProvider{
+		client: d.dockerClient,
+	}
+
+	found := make([]*DockerContainer, 0, len(containers))
+	for _, containerInstance := range containers {
+		found = append(found, &DockerContainer{
+			ID:           containerInstance.ID,
+			provider:     provider,
+			stopProducer: make(chan bool),
+			logger:       d.logger,
+		})
+	}
+
+	return found, nil
+}
+
+func containerNumber(c types2.Container) int {
+	n, err := strconv.Atoi(c.Labels[api.ContainerNumberLabel])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// reaped returns whether the resources of the stack are removed by the reaper once the test session ends
+func (d *dockerCompose) reaped() bool {
+	return !d.reuse && !d.skipReaper
+}
+
+// registerWithReaper registers the label of the project with the reaper on the first Up, so that the resources of the
+// stack are removed once the test session ends, even if the tests crashed before tearing it down
+func (d *dockerCompose) registerWithReaper(ctx context.Context) error {
+	if !d.reaped() || d.terminationSignal != nil {
+		return nil
+	}
+
+	provider, err := d.provider.GetProvider(WithLogger(d.logger))
+	if err != nil {
+		return err
+	}
+	dockerProvider, ok := provider.(*DockerProvider)
+	if !ok {
+		return errors.New("the reaper requires a Docker provider")
+	}
+
+	r, err := NewReaper(context.WithValue(ctx, dockerHostContextKey, dockerProvider.host), sessionID().String(), dockerProvider, "")
+	if err != nil {
+		return fmt.Errorf("%w: creating reaper failed", err)
+	}
+
+	d.terminationSignal, err = r.connect(map[string]string{api.ProjectLabel: d.name})
+	if err != nil {
+		return fmt.Errorf("%w: connecting to reaper failed", err)
+	}
+
+	return nil
+}
+
+// sessionLabels returns the labels of the resources created by the current test session
+func sessionLabels() map[string]string {
+	return map[string]string{
+		TestcontainerLabel:          "true",
+		TestcontainerLabelSessionID: sessionID().String(),
+	}
+}
+
+// withSessionLabels adds the labels of the current test session to the given ones
+func withSessionLabels(labels types.Labels) types.Labels {
+	if labels == nil {
+		labels = types.Labels{}
+	}
+	for k, v := range sessionLabels() {
+		labels[k] = v
+	}
+	return labels
+}
+
+func (d *dockerCompose) compileProject(profiles []string) (*types.Project, error) {
+	// the configuration paths point to the temporary directory, written again once the stack was torn down
+	if d.tempDirRemoved && d.writeStackFiles != nil {
+		if err := d.writeStackFiles(d.tempDir); err != nil {
+			return nil, err
+		}
+		d.tempDirRemoved = false
+	}
+
+	const nameDefaultConfigPathAndEnvFiles = 3
+	projectOptions := make([]cli.ProjectOptionsFn, len(d.projectOptions), len(d.projectOptions)+nameDefaultConfigPathAndEnvFiles)
+
+	copy(projectOptions, d.projectOptions)
+	projectOptions = append(projectOptions, cli.WithName(d.name), cli.WithDefaultConfigPath, withEnvFiles(d.envFiles))
+
+	compiledOptions, err := cli.NewProjectOptions(d.configs, projectOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	var proj *types.Project
+	if local, ok := d.composeService.(*localComposeService); ok {
+		proj, err = local.loadProject(compiledOptions, profiles)
+	} else {
+		proj, err = cli.ProjectFromOptions(compiledOptions)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, overrides := range []map[string][]string{d.serviceCommands, d.serviceEntrypoints} {
+		for svc := range overrides {
+			if _, err := proj.GetService(svc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// like docker compose, services with profiles are only enabled if one of them is selected
+	proj.ApplyProfiles(profiles)
+
+	for i, s := range proj.Services {
+		s.CustomLabels = map[string]string{
+			api.ProjectLabel:     proj.Name,
+			api.ServiceLabel:     s.Name,
+			api.VersionLabel:     api.ComposeVersion,
+			api.WorkingDirLabel:  proj.WorkingDir,
+			api.ConfigFilesLabel: strings.Join(proj.ComposeFiles, ","),
+			api.OneoffLabel:      "False", // default, will be overridden by `run` command
+		}
+		if d.podman {
+			s.CustomLabels[podmanComposeProjectLabel] = proj.Name
 		}
 		if compiledOptions.EnvFile != "" {
 			s.CustomLabels[api.EnvironmentFileLabel] = compiledOptions.EnvFile
 		}
+		if s.Image != "" && s.Build == nil {
+			s.Image = substituteImage(s.Image, d.imageSubstitutors)
+		}
+		if cmd, ok := d.serviceCommands[s.Name]; ok {
+			s.Command = types.ShellCommand(cmd)
+		}
+		if entrypoint, ok := d.serviceEntrypoints[s.Name]; ok {
+			s.Entrypoint = types.ShellCommand(entrypoint)
+		}
+		if d.reaped() {
+			for k, v := range sessionLabels() {
+				s.CustomLabels[k] = v
+			}
+		}
+		for k, v := range annotationLabels() {
+			s.CustomLabels[k] = v
+		}
 		proj.Services[i] = s
 	}
 
+	// the networks and volumes declared by the stack are removed with its containers, and annotated like them
+	for k, n := range proj.Networks {
+		if !n.External.External {
+			if d.reaped() {
+				n.Labels = withSessionLabels(n.Labels)
+			}
+			n.Labels = types.Labels(withAnnotations(n.Labels))
+			proj.Networks[k] = n
+		}
+	}
+	for k, v := range proj.Volumes {
+		if !v.External.External {
+			if d.reaped() {
+				v.Labels = withSessionLabels(v.Labels)
+			}
+			v.Labels = types.Labels(withAnnotations(v.Labels))
+			proj.Volumes[k] = v
+		}
+	}
+
 	return proj, nil
 }
 
+// oneOffContainerConfig builds the configuration of a one-off container of the service,
+// the user, working directory and additional environment of the process options take precedence
+func oneOffContainerConfig(project *types.Project, service types.ServiceConfig, exec types2.ExecConfig) (*container.Config, *container.HostConfig) {
+	labels := make(map[string]string, len(service.Labels)+len(service.CustomLabels))
+	for k, v := range service.Labels {
+		labels[k] = v
+	}
+	for k, v := range service.CustomLabels {
+		labels[k] = v
+	}
+	labels[api.OneoffLabel] = "True"
+
+	env := make([]string, 0, len(service.Environment)+len(exec.Env))
+	for k, v := range service.Environment {
+		if v != nil {
+			env = append(env, k+"="+*v)
+		}
+	}
+	env = append(env, exec.Env...)
+
+	config := &container.Config{
+		Image:      api.GetImageNameOrDefault(service, project.Name),
+		Cmd:        exec.Cmd,
+		Entrypoint: strslice.StrSlice(service.Entrypoint),
+		Env:        env,
+		User:       service.User,
+		WorkingDir: service.WorkingDir,
+		Labels:     labels,
+	}
+	if len(config.Cmd) == 0 {
+		config.Cmd = strslice.StrSlice(service.Command)
+	}
+	if exec.User != "" {
+		config.User = exec.User
+	}
+	if exec.WorkingDir != "" {
+		config.WorkingDir = exec.WorkingDir
+	}
+
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode(service.NetworkMode),
+	}
+	for _, v := range service.Volumes {
+		m := mount.Mount{
+			Type:     mount.Type(v.Type),
+			Source:   v.Source,
+			Target:   v.Target,
+			ReadOnly: v.ReadOnly,
+		}
+		if volume, ok := project.Volumes[v.Source]; ok && v.Type == types.VolumeTypeVolume {
+			m.Source = volume.Name
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, m)
+	}
+
+	return config, hostConfig
+}
+
+// oneOffNetworks returns the names of the networks of the service, in a stable order
+func oneOffNetworks(project *types.Project, service types.ServiceConfig) []string {
+	networks := make([]string, 0, len(service.Networks))
+	for key := range service.Networks {
+		name := key
+		if n, ok := project.Networks[key]; ok && n.Name != "" {
+			name = n.Name
+		}
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+
+	return networks
+}
+
+// composeNetworkName returns the name of the network of the project the services are attached to by default
+func composeNetworkName(project *types.Project) (string, error) {
+	if project == nil {
+		return "", errors.New("the compose stack is not up")
+	}
+
+	used := make(map[string]bool)
+	for _, service := range project.Services {
+		for key := range service.Networks {
+			used[key] = true
+		}
+	}
+
+	key := "default"
+	if !used[key] {
+		keys := make([]string, 0, len(used))
+		for k := range used {
+			keys = append(keys, k)
+		}
+		if len(keys) == 0 {
+			return "", errors.New("the services of the compose stack are not attached to any network")
+		}
+		sort.Strings(keys)
+		key = keys[0]
+	}
+
+	if n, ok := project.Networks[key]; ok && n.Name != "" {
+		return n.Name, nil
+	}
+	return fmt.Sprintf("%s_%s", project.Name, key), nil
+}
+
+// scaleServices sets the number of replicas of the services of the project
+func scaleServices(project *types.Project, scale map[string]int) error {
+	for svc, replicas := range scale {
+		if replicas < 0 {
+			return fmt.Errorf("invalid scale %d for service %s", replicas, svc)
+		}
+
+		service, err := project.GetService(svc)
+		if err != nil {
+			return err
+		}
+
+		if service.Deploy == nil {
+			service.Deploy = &types.DeployConfig{}
+		}
+		n := uint64(replicas)
+		service.Deploy.Replicas = &n
+
+		for i := range project.Services {
+			if project.Services[i].Name == svc {
+				project.Services[i] = service
+			}
+		}
+	}
+
+	return nil
+}
+
+// overrideServiceEnv sets environment variables of the services of the project
+func overrideServiceEnv(project *types.Project, env map[string]map[string]string) error {
+	for svc, vars := range env {
+		if _, err := project.GetService(svc); err != nil {
+			return err
+		}
+
+		for i := range project.Services {
+			if project.Services[i].Name != svc {
+				continue
+			}
+
+			if project.Services[i].Environment == nil {
+				project.Services[i].Environment = types.MappingWithEquals{}
+			}
+			for k, v := range vars {
+				v := v
+				project.Services[i].Environment[k] = &v
+			}
+		}
+	}
+
+	return nil
+}
+
+// attachExternalNetworks declares existing networks in the project and attaches all the services to them, except the
+// services with a network_mode
+func attachExternalNetworks(project *types.Project, names []string) error {
+	for _, name := range names {
+		if _, ok := project.Networks[name]; ok {
+			return fmt.Errorf("network %s is already declared by the project", name)
+		}
+
+		if project.Networks == nil {
+			project.Networks = types.Networks{}
+		}
+		project.Networks[name] = types.NetworkConfig{
+			Name:     name,
+			External: types.External{External: true},
+		}
+
+		for i := range project.Services {
+			if project.Services[i].NetworkMode != "" {
+				continue
+			}
+			if project.Services[i].Networks == nil {
+				project.Services[i].Networks = map[string]*types.ServiceNetworkConfig{}
+			}
+			// compose adds the name of the service to its aliases on every network
+			project.Services[i].Networks[name] = nil
+		}
+	}
+
+	return nil
+}
+
+// writeFileObjectContents writes the contents of secrets and configs to the temporary directory of the stack, and
+// points the secrets and configs of the project to them
+func (d *dockerCompose) writeFileObjectContents(secrets map[string][]byte, configs map[string][]byte) error {
+	if len(secrets) == 0 && len(configs) == 0 {
+		return nil
+	}
+
+	if d.tempDir == "" {
+		dir, err := stackTempDir(d.name)
+		if err != nil {
+			return err
+		}
+		d.tempDir = dir
+	}
+
+	for name, data := range secrets {
+		secret, ok := d.project.Secrets[name]
+		if !ok || secret.External.External {
+			return fmt.Errorf("no secret %s declared by the compose files to set the content of", name)
+		}
+
+		path, err := writeFileObjectContent(filepath.Join(d.tempDir, "secrets"), name, data)
+		if err != nil {
+			return err
+		}
+		secret.File = path
+		secret.Environment = ""
+		d.project.Secrets[name] = secret
+	}
+
+	for name, data := range configs {
+		config, ok := d.project.Configs[name]
+		if !ok || config.External.External {
+			return fmt.Errorf("no config %s declared by the compose files to set the content of", name)
+		}
+
+		path, err := writeFileObjectContent(filepath.Join(d.tempDir, "configs"), name, data)
+		if err != nil {
+			return err
+		}
+		config.File = path
+		config.Environment = ""
+		d.project.Configs[name] = config
+	}
+
+	return nil
+}
+
+// writeFileObjectContent writes the content of a secret or config to dir, which is created if needed
+func writeFileObjectContent(dir string, name string, data []byte) (string, error) {
+	// the directory is only accessible to the current user, the file is readable by the users of the containers
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("%w: failed to write the content of %s", err, name)
+	}
+
+	return path, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// withEnvFiles loads the env files into the environment used for interpolation,
+// without overriding the variables that are already set
+func withEnvFiles(paths []string) func(*cli.ProjectOptions) error {
+	return func(options *cli.ProjectOptions) error {
+		if len(paths) == 0 {
+			return nil
+		}
+
+		wd, err := options.GetWorkingDir()
+		if err != nil {
+			return err
+		}
+
+		fromFiles := make(map[string]string)
+		for _, path := range paths {
+			env, err := cli.GetEnvFromFile(options.Environment, wd, path)
+			if err != nil {
+				return err
+			}
+			for k, v := range env {
+				fromFiles[k] = v
+			}
+		}
+
+		for k, v := range fromFiles {
+			if _, ok := options.Environment[k]; !ok {
+				options.Environment[k] = v
+			}
+		}
+
+		options.EnvFile = strings.Join(paths, ",")
+
+		return nil
+	}
+}
+
 func withEnv(env map[string]string) func(*cli.ProjectOptions) error {
 	return func(options *cli.ProjectOptions) error {
 		for k, v := range env {
@@ -345,10 +2342,285 @@ func withEnv(env map[string]string) func(*cli.ProjectOptions) error {
 	}
 }
 
-func makeClient(*command.DockerCli) (client.APIClient, error) {
-	dockerClient, _, _, err := NewDockerClient()
+// makeClient returns the client of the Podman API at the given host if not empty, or the shared Docker client
+func makeClient(podmanHost string) func(*command.DockerCli) (client.APIClient, error) {
+	return func(*command.DockerCli) (client.APIClient, error) {
+		if podmanHost != "" {
+			podmanClient, err := client.NewClientWithOpts(
+				client.WithHTTPClient(newDockerHTTPClient()),
+				client.WithHost(podmanHost),
+				client.WithAPIVersionNegotiation(),
+			)
+			if err != nil {
+				return nil, err
+			}
+			return withAuditTrail(podmanClient), nil
+		}
+
+		dockerClient, _, _, err := getSharedDockerClient()
+		if err != nil {
+			return nil, err
+		}
+		return withAuditTrail(dockerClient), nil
+	}
+}
+
+// podmanComposeProjectLabel is the label podman-compose lists the containers of a project with
+const podmanComposeProjectLabel = "io.podman.compose.project"
+
+// sanitizeProjectName returns the compose project name of a stack identifier: compose only accepts lowercase letters,
+// digits, dashes and underscores, starting with a letter or a digit, so the identifier is lowercased and the other
+// characters are dropped, like compose does
+func sanitizeProjectName(identifier string) (string, error) {
+	name := loader.NormalizeProjectName(identifier)
+	if name == "" {
+		return "", fmt.Errorf("%w: %q has no lowercase letter, digit, dash or underscore to keep", ErrInvalidProjectName, identifier)
+	}
+	return name, nil
+}
+
+// checkProjectName fails with ErrProjectNameInUse if containers of the project of the stack were started by another
+// test session and are still running, which the stack would otherwise recreate or tear down
+func (d *dockerCompose) checkProjectName(ctx context.Context) error {
+	containers, err := d.dockerClient.ContainerList(ctx, types2.ContainerListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, d.name)),
+			filters.Arg("status", "running"),
+		),
+	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	session := sessionID().String()
+	for _, c := range containers {
+		if owner := c.Labels[TestcontainerLabelSessionID]; owner != session {
+			if owner == "" {
+				owner = "none"
+			}
+			return fmt.Errorf("%w: container %s of project %s is running, started by session %s", ErrProjectNameInUse, c.ID, d.name, owner)
+		}
+	}
+
+	return nil
+}
+
+// unsafeFileChars matches the characters of a stack identifier not kept in the name of its temporary directory
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// stackTempDir creates the temporary directory of the files of a stack, unique to the stack and to the run: its name
+// starts with the identifier of the stack, followed by a random nonce, so that the stacks of parallel test runs
+// sharing an identifier do not collide
+func stackTempDir(identifier string) (string, error) {
+	return os.MkdirTemp("", "testcontainers-compose-"+unsafeFileChars.ReplaceAllString(identifier, "_")+"-")
+}
+
+// copyStackFS copies the file system to the directory, failing if one of the compose files is missing
+func copyStackFS(fsys fs.FS, paths []string, dir string) error {
+	if len(paths) == 0 {
+		return ErrNoStackConfigured
+	}
+	for _, p := range paths {
+		if _, err := fs.Stat(fsys, p); err != nil {
+			return fmt.Errorf("%w: compose file %s", err, p)
+		}
+	}
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(p))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		// keeps the scripts of build contexts executable
+		var mode os.FileMode = 0o644
+		if info.Mode()&0o111 != 0 {
+			mode = 0o755
+		}
+		return os.WriteFile(target, content, mode)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: failed to copy the compose files", err)
+	}
+
+	return nil
+}
+
+// readStackReaders reads the content of the compose files of the readers, which can only be read once
+func readStackReaders(readers []io.Reader) ([][]byte, error) {
+	contents := make([][]byte, 0, len(readers))
+	for i, r := range readers {
+		if r == nil {
+			return nil, fmt.Errorf("compose file reader %d is nil", i)
+		}
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read compose file reader %d", err, i)
+		}
+		contents = append(contents, content)
 	}
-	return dockerClient, nil
+
+	return contents, nil
+}
+
+// writeStackReaders writes the content read from the readers to compose files in the directory
+func writeStackReaders(dir string, contents [][]byte) error {
+	for i, content := range contents {
+		if err := os.WriteFile(stackReaderPath(dir, i), content, 0o644); err != nil {
+			return fmt.Errorf("%w: failed to write compose file reader %d", err, i)
+		}
+	}
+
+	return nil
+}
+
+// stackReaderPath returns the path of the compose file of the reader at the index in the directory
+func stackReaderPath(dir string, i int) string {
+	return filepath.Join(dir, fmt.Sprintf("docker-compose-reader-%d.yml", i))
+}
+
+// fetchStackURL returns the path of the cached compose file published at the URL, downloading it if it is not cached
+// or has no checksum
+func fetchStackURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid compose file URL", err)
+	}
+
+	var checksum string
+	if u.Fragment != "" {
+		if !strings.HasPrefix(u.Fragment, "sha256=") {
+			return "", fmt.Errorf("unsupported checksum %s of compose file %s, expected sha256=<hex digest>", u.Fragment, rawURL)
+		}
+		checksum = strings.ToLower(strings.TrimPrefix(u.Fragment, "sha256="))
+		u.Fragment = ""
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	// the file keeps its name, which compose uses as the default working directory and in error messages
+	key := sha256.Sum256([]byte(u.String()))
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "docker-compose.yml"
+	}
+	cachedPath := filepath.Join(cacheDir, "testcontainers", "compose", hex.EncodeToString(key[:]), name)
+
+	if checksum != "" {
+		if content, err := os.ReadFile(cachedPath); err == nil && sha256Hex(content) == checksum {
+			return cachedPath, nil
+		}
+	}
+
+	content, err := downloadStackFile(u.String())
+	if err != nil {
+		return "", err
+	}
+	if checksum != "" && sha256Hex(content) != checksum {
+		return "", fmt.Errorf("checksum mismatch of compose file %s: expected %s, got %s", u, checksum, sha256Hex(content))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachedPath), 0o755); err != nil {
+		return "", err
+	}
+
+	// written to a temporary file first, so that concurrent stacks never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(cachedPath), name+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), cachedPath); err != nil {
+		return "", err
+	}
+
+	return cachedPath, nil
+}
+
+func downloadStackFile(fileURL string) ([]byte, error) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := httpClient.Get(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to download compose file %s", err, fileURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download compose file %s: unexpected status %d", fileURL, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolvePodmanHost returns the host of the Podman API socket the stack uses, empty for Docker.
+// Podman is used when requested with ProviderPodman, or when no Docker host is configured and the default Docker
+// socket does not exist but a Podman socket does, e.g. on a machine only running Podman.
+// An explicit DOCKER_HOST, e.g. pointing to the Podman socket, always takes precedence.
+func resolvePodmanHost(provider ProviderType) string {
+	if os.Getenv("DOCKER_HOST") != "" || configureTC().Host != "" {
+		return ""
+	}
+
+	if provider != ProviderPodman {
+		if _, err := os.Stat(strings.TrimPrefix(client.DefaultDockerHost, "unix://")); err == nil {
+			return ""
+		}
+	}
+
+	for _, socket := range podmanSocketPaths() {
+		if _, err := os.Stat(socket); err == nil {
+			return "unix://" + socket
+		}
+	}
+
+	return ""
+}
+
+// podmanSocketPaths returns the paths of the Podman API socket, the rootless ones of the current user first
+func podmanSocketPaths() []string {
+	var paths []string
+
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		paths = append(paths, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	paths = append(paths, fmt.Sprintf("/run/user/%d/podman/podman.sock", os.Getuid()))
+
+	// the socket forwarded from the virtual machine of podman machine, on macOS
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".local", "share", "containers", "podman", "machine", "podman.sock"))
+		paths = append(paths, filepath.Join(home, ".local", "share", "containers", "podman", "machine", "qemu", "podman.sock"))
+	}
+
+	return append(paths, "/run/podman/podman.sock")
 }
@@ -2,13 +2,32 @@ package testcontainers
 
 import (
 	"context"
+	"embed"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
 	"time"
 
+	"github.com/compose-spec/compose-go/cli"
+	"github.com/compose-spec/compose-go/types"
+	"github.com/docker/compose/v2/pkg/api"
+	types2 "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -51,6 +70,48 @@ func TestDockerComposeAPIStrategyForInvalidService(t *testing.T) {
 	assert.Contains(t, serviceNames, "nginx")
 }
 
+func TestDockerComposeAPIWithWaitTimeout(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	start := time.Now()
+	err = compose.
+		WaitForService("nginx", wait.NewLogStrategy("never logged").WithStartupTimeout(time.Minute), WithWaitTimeout(2*time.Second)).
+		Up(ctx, Wait(true))
+
+	assert.Error(t, err, "Expected error to be thrown because the service never logs the line")
+	assert.Contains(t, err.Error(), "service nginx not ready before the deadline")
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+func TestDockerComposeAPIWithUpTimeout(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	start := time.Now()
+	err = compose.
+		WaitForService("nginx", wait.NewLogStrategy("never logged").WithStartupTimeout(time.Minute)).
+		Up(ctx, Wait(true), WithUpTimeout(10*time.Second))
+
+	assert.Error(t, err, "Expected error to be thrown because the stack is not ready in time")
+	assert.Contains(t, err.Error(), "service nginx not ready")
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
 func TestDockerComposeAPIWithWaitLogStrategy(t *testing.T) {
 	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
 	assert.NoError(t, err, "NewDockerCompose()")
@@ -62,21 +123,1235 @@ func TestDockerComposeAPIWithWaitLogStrategy(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	err = compose.
-		WaitForService("mysql", wait.NewLogStrategy("started").WithStartupTimeout(10*time.Second).WithOccurrence(1)).
-		Up(ctx, Wait(true))
+	err = compose.
+		WaitForService("mysql", wait.NewLogStrategy("started").WithStartupTimeout(10*time.Second).WithOccurrence(1)).
+		Up(ctx, Wait(true))
+
+	assert.NoError(t, err, "compose.Up()")
+
+	serviceNames := compose.Services()
+
+	assert.Equal(t, 2, len(serviceNames))
+	assert.Contains(t, serviceNames, "nginx")
+	assert.Contains(t, serviceNames, "mysql")
+}
+
+func TestDockerComposeAPIWithPullPolicy(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true), WithPullPolicy(PullAlways)), "compose.Up()")
+	assert.NoError(t, compose.Down(ctx), "compose.Down()")
+
+	// the image was pulled by the previous Up
+	assert.NoError(t, compose.Up(ctx, Wait(true), WithPullPolicy(PullNever)), "compose.Up()")
+}
+
+func TestDockerComposeAPIWithLogConsumer(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	consumer := &composeLogConsumer{lines: make(chan string, 256)}
+
+	err = compose.
+		WithLogConsumer("mysql", consumer).
+		WaitForService("mysql", wait.NewLogStrategy("started").WithStartupTimeout(10*time.Second).WithOccurrence(1)).
+		Up(ctx, Wait(true))
+
+	assert.NoError(t, err, "compose.Up()")
+
+	select {
+	case <-consumer.lines:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no log line received from the mysql service")
+	}
+}
+
+type composeLogConsumer struct {
+	lines chan string
+}
+
+func (c *composeLogConsumer) Accept(l Log) {
+	select {
+	case c.lines <- string(l.Content):
+	default:
+	}
+}
+
+func TestDockerComposeAPIWithRunServices(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WaitForService("nginx", wait.NewHTTPStrategy("/").WithPort("80/tcp").WithStartupTimeout(10*time.Second)).
+		Up(ctx, Wait(true), RunServices("nginx"))
+
+	assert.NoError(t, err, "compose.Up()")
+
+	serviceNames := compose.Services()
+
+	_, err = compose.ServiceContainer(context.Background(), "mysql")
+	assert.Error(t, err, "Make sure there is no mysql container")
+
+	assert.Equal(t, 1, len(serviceNames))
+	assert.Contains(t, serviceNames, "nginx")
+}
+
+func TestDockerComposeAPIWithProfiles(t *testing.T) {
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-profiles.yml"), WithProfiles("db"))
+	assert.NoError(t, err, "NewDockerComposeWith()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	serviceNames := compose.Services()
+
+	assert.Equal(t, 2, len(serviceNames))
+	assert.Contains(t, serviceNames, "nginx")
+	assert.Contains(t, serviceNames, "mysql")
+
+	_, err = compose.ServiceContainer(context.Background(), "redis")
+	assert.Error(t, err, "Make sure there is no redis container")
+}
+
+func TestDockerComposeAPIWithProfilesOverriddenOnUp(t *testing.T) {
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-profiles.yml"), WithProfiles("db"))
+	assert.NoError(t, err, "NewDockerComposeWith()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true), WithProfiles("cache")), "compose.Up()")
+
+	serviceNames := compose.Services()
+
+	assert.Equal(t, 2, len(serviceNames))
+	assert.Contains(t, serviceNames, "nginx")
+	assert.Contains(t, serviceNames, "redis")
+}
+
+func TestDockerComposeAPIStopStart(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	require.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WaitForService("nginx", wait.NewHTTPStrategy("/").WithPort("80/tcp").WithStartupTimeout(10*time.Second)).
+		Up(ctx, Wait(true))
+	require.NoError(t, err, "compose.Up()")
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	require.NoError(t, err, "compose.ServiceContainer()")
+
+	require.NoError(t, compose.Stop(ctx, "nginx"), "compose.Stop()")
+
+	state, err := nginx.State(ctx)
+	require.NoError(t, err, "nginx.State()")
+	assert.False(t, state.Running, "nginx should be stopped")
+
+	mysql, err := compose.ServiceContainer(ctx, "mysql")
+	require.NoError(t, err, "compose.ServiceContainer()")
+
+	state, err = mysql.State(ctx)
+	require.NoError(t, err, "mysql.State()")
+	assert.True(t, state.Running, "mysql should not be stopped")
+
+	require.NoError(t, compose.Start(ctx, "nginx"), "compose.Start()")
+
+	state, err = nginx.State(ctx)
+	require.NoError(t, err, "nginx.State()")
+	assert.True(t, state.Running, "nginx should be running again")
+
+	assert.Equal(t, 2, len(compose.Services()))
+}
+
+func TestDockerComposeForTest(t *testing.T) {
+	var stack *dockerCompose
+
+	t.Run("stack", func(t *testing.T) {
+		stack = NewDockerComposeForTest(t, "./testresources/docker-compose-simple.yml")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		assert.NoError(t, stack.Up(ctx, Wait(true)), "compose.Up()")
+	})
+
+	containers, err := stack.dockerClient.ContainerList(context.Background(), types2.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, stack.name))),
+	})
+	assert.NoError(t, err, "ContainerList()")
+	assert.Empty(t, containers, "the stack should be torn down with the test")
+}
+
+func TestDockerComposeAPIWithLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), WithLogger(logger))
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+	assert.Equal(t, logger, compose.logger)
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+	assert.Equal(t, logger, nginx.logger)
+}
+
+func TestDockerComposeAPIKill(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	assert.NoError(t, compose.Kill(ctx, "SIGKILL", "nginx"), "compose.Kill()")
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	state, err := nginx.State(ctx)
+	assert.NoError(t, err, "nginx.State()")
+	assert.False(t, state.Running, "nginx should be killed")
+	assert.Equal(t, 137, state.ExitCode)
+
+	mysql, err := compose.ServiceContainer(ctx, "mysql")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	state, err = mysql.State(ctx)
+	assert.NoError(t, err, "mysql.State()")
+	assert.True(t, state.Running, "mysql should not be killed")
+}
+
+func TestDockerComposeAPIPauseUnpause(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	assert.NoError(t, compose.Pause(ctx, "nginx"), "compose.Pause()")
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	state, err := nginx.State(ctx)
+	assert.NoError(t, err, "nginx.State()")
+	assert.True(t, state.Paused, "nginx should be paused")
+
+	mysql, err := compose.ServiceContainer(ctx, "mysql")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	state, err = mysql.State(ctx)
+	assert.NoError(t, err, "mysql.State()")
+	assert.False(t, state.Paused, "mysql should not be paused")
+
+	assert.NoError(t, compose.Unpause(ctx, "nginx"), "compose.Unpause()")
+
+	state, err = nginx.State(ctx)
+	assert.NoError(t, err, "nginx.State()")
+	assert.False(t, state.Paused, "nginx should be resumed")
+	assert.True(t, state.Running, "nginx should be running")
+}
+
+func TestDockerComposeAPIRestartService(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WaitForService("nginx", wait.NewHTTPStrategy("/").WithPort("80/tcp").WithStartupTimeout(10*time.Second)).
+		Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	before, err := nginx.State(ctx)
+	assert.NoError(t, err, "nginx.State()")
+
+	timeout := 5 * time.Second
+	assert.NoError(t, compose.RestartService(ctx, "nginx", &timeout), "compose.RestartService()")
+
+	nginx, err = compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	after, err := nginx.State(ctx)
+	assert.NoError(t, err, "nginx.State()")
+	assert.True(t, after.Running, "nginx should be running again")
+	assert.NotEqual(t, before.StartedAt, after.StartedAt, "nginx should have been restarted")
+}
+
+func TestDockerComposeAPIEvents(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	require.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	eventsCtx, stopEvents := context.WithTimeout(ctx, 30*time.Second)
+	defer stopEvents()
+
+	events, err := compose.Events(eventsCtx, "nginx")
+	require.NoError(t, err, "compose.Events()")
+
+	assert.NoError(t, compose.Kill(ctx, "SIGKILL", "nginx"), "compose.Kill()")
+	assert.NoError(t, compose.Stop(ctx, "mysql"), "compose.Stop()")
+
+	var die *ComposeEvent
+	for event := range events {
+		assert.Equal(t, "nginx", event.Service, "only the events of the selected services are streamed")
+		if event.Status == "die" && die == nil {
+			event := event
+			die = &event
+			stopEvents()
+		}
+	}
+
+	if assert.NotNil(t, die, "the die event of nginx") {
+		assert.Equal(t, "137", die.Attributes["exitCode"])
+		assert.NotContains(t, die.Attributes, "com.docker.compose.project")
+	}
+}
+
+func TestDockerComposeAPIPs(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	statuses, err := compose.Ps(ctx)
+	assert.NoError(t, err, "compose.Ps()")
+	assert.Equal(t, 2, len(statuses))
+
+	assert.Equal(t, "mysql", statuses[0].Service)
+	assert.Equal(t, "nginx", statuses[1].Service)
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+	assert.Equal(t, nginx.GetContainerID(), statuses[1].ContainerID)
+	assert.Equal(t, "running", statuses[1].State)
+	assert.NotEmpty(t, statuses[1].Ports)
+
+	assert.NoError(t, compose.Stop(ctx, "nginx"), "compose.Stop()")
+
+	statuses, err = compose.Ps(ctx)
+	assert.NoError(t, err, "compose.Ps()")
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, "mysql", statuses[0].Service)
+}
+
+func TestServiceStatuses(t *testing.T) {
+	statuses := serviceStatuses([]api.ContainerSummary{
+		{
+			ID:      "2",
+			Name:    "stack-nginx-2",
+			Service: "nginx",
+			State:   "running",
+			Publishers: api.PortPublishers{
+				{URL: "0.0.0.0", TargetPort: 443, PublishedPort: 9443, Protocol: "tcp"},
+				{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 9080, Protocol: "tcp"},
+			},
+		},
+		{ID: "1", Name: "stack-nginx-1", Service: "nginx", State: "running"},
+		{ID: "3", Name: "stack-mysql-1", Service: "mysql", State: "running", Health: "healthy"},
+	})
+
+	assert.Equal(t, 3, len(statuses))
+	assert.Equal(t, "3", statuses[0].ContainerID)
+	assert.Equal(t, "healthy", statuses[0].Health)
+	assert.Equal(t, "1", statuses[1].ContainerID)
+	assert.Equal(t, "2", statuses[2].ContainerID)
+	assert.Equal(t, []PublishedPort{
+		{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 9080, Protocol: "tcp"},
+		{URL: "0.0.0.0", TargetPort: 443, PublishedPort: 9443, Protocol: "tcp"},
+	}, statuses[2].Ports)
+}
+
+func TestResolvePodmanHost(t *testing.T) {
+	if configureTC().Host != "" {
+		t.Skip("a Docker host is configured in the testcontainers properties")
+	}
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("DOCKER_HOST", "")
+
+	socket := filepath.Join(runtimeDir, "podman", "podman.sock")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(socket), 0o755))
+	assert.NoError(t, os.WriteFile(socket, nil, 0o600))
+
+	assert.Equal(t, "unix://"+socket, resolvePodmanHost(ProviderPodman))
+
+	t.Setenv("DOCKER_HOST", "tcp://localhost:2375")
+	assert.Equal(t, "", resolvePodmanHost(ProviderPodman), "an explicit Docker host takes precedence")
+}
+
+func TestDockerComposeAPIPodmanLabels(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	compose.podman = true
+
+	project, err := compose.compileProject(nil)
+	assert.NoError(t, err, "compileProject()")
+
+	for _, service := range project.Services {
+		assert.Equal(t, project.Name, service.CustomLabels[podmanComposeProjectLabel])
+	}
+}
+
+func TestDockerComposeAPIWithImageSubstitutor(t *testing.T) {
+	compose, err := NewDockerComposeWith(
+		WithStackFiles("./testresources/docker-compose-simple.yml", "./testresources/docker-compose-build.yml"),
+		WithImageSubstitutor(DockerHubMirror("registry.corp/mirror")),
+		WithImageSubstitutor(ImageSubstitutorFunc(func(image string) string {
+			return strings.Replace(image, ":stable-alpine", ":1.24-alpine", 1)
+		})),
+	)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	project, err := compose.compileProject(nil)
+	assert.NoError(t, err, "compileProject()")
+
+	nginx, err := project.GetService("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "registry.corp/mirror/nginx:1.24-alpine", nginx.Image)
+
+	echo, err := project.GetService("echo")
+	assert.NoError(t, err)
+	assert.Empty(t, echo.Image, "the services built are left to compose")
+}
+
+func TestDockerComposeAPIServiceOverrides(t *testing.T) {
+	compose, err := NewDockerComposeWith(
+		WithStackFiles("./testresources/docker-compose-simple.yml"),
+		WithServiceCommand("nginx", []string{"nginx-debug", "-g", "daemon off;"}),
+		WithServiceEntrypoint("nginx", []string{"/docker-entrypoint.sh"}),
+	)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	project, err := compose.compileProject(nil)
+	assert.NoError(t, err, "compileProject()")
+
+	nginx, err := project.GetService("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, types.ShellCommand{"nginx-debug", "-g", "daemon off;"}, nginx.Command)
+	assert.Equal(t, types.ShellCommand{"/docker-entrypoint.sh"}, nginx.Entrypoint)
+
+	unknown, err := NewDockerComposeWith(
+		WithStackFiles("./testresources/docker-compose-simple.yml"),
+		WithServiceCommand("redis", []string{"redis-server"}),
+	)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	_, err = unknown.compileProject(nil)
+	assert.Error(t, err, "unknown service")
+}
+
+func TestDockerComposeAPIWithServiceCommand(t *testing.T) {
+	compose, err := NewDockerComposeWith(
+		WithStackFiles("./testresources/docker-compose-simple.yml"),
+		WithServiceCommand("nginx", []string{"nginx-debug", "-g", "daemon off;"}),
+	)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+
+	inspect, err := nginx.inspectContainer(ctx)
+	assert.NoError(t, err, "nginx.inspectContainer()")
+	assert.Equal(t, []string{"nginx-debug", "-g", "daemon off;"}, []string(inspect.Config.Cmd))
+}
+
+func TestDockerComposeAPISessionLabels(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-volume.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	project, err := compose.compileProject(nil)
+	assert.NoError(t, err, "compileProject()")
+
+	for _, service := range project.Services {
+		assert.Equal(t, sessionID().String(), service.CustomLabels[TestcontainerLabelSessionID], service.Name)
+	}
+	for name, volume := range project.Volumes {
+		assert.Equal(t, sessionID().String(), volume.Labels[TestcontainerLabelSessionID], name)
+	}
+
+	reused, err := NewDockerComposeWith(
+		WithStackFiles("./testresources/docker-compose-volume.yml"),
+		StackIdentifier(testNameHash(t.Name())),
+		WithReuse(true),
+	)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	project, err = reused.compileProject(nil)
+	assert.NoError(t, err, "compileProject()")
+
+	for _, service := range project.Services {
+		assert.NotContains(t, service.CustomLabels, TestcontainerLabelSessionID, "reused stacks outlive the session")
+	}
+}
+
+func TestDockerComposeAPIValidate(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	assert.Nil(t, compose.Project(), "the project is compiled by Validate or Up")
+
+	err = compose.
+		WithEnv(map[string]string{"bar": "BAR"}).
+		Validate(context.Background())
+	assert.NoError(t, err, "Validate()")
+
+	project := compose.Project()
+	assert.NotNil(t, project)
+	assert.Equal(t, []string{"nginx"}, project.ServiceNames())
+
+	nginx, err := project.GetService("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "BAR", *nginx.Environment["bar"])
+}
+
+func TestComposeNetworkName(t *testing.T) {
+	_, err := composeNetworkName(nil)
+	assert.EqualError(t, err, "the compose stack is not up")
+
+	project := &types.Project{
+		Name: "stack",
+		Services: types.Services{
+			{Name: "api", Networks: map[string]*types.ServiceNetworkConfig{"default": nil, "backend": nil}},
+			{Name: "db", Networks: map[string]*types.ServiceNetworkConfig{"backend": nil}},
+		},
+		Networks: types.Networks{"default": {}, "backend": {Name: "shared"}},
+	}
+
+	name, err := composeNetworkName(project)
+	assert.NoError(t, err)
+	assert.Equal(t, "stack_default", name)
+
+	project.Services[0].Networks = map[string]*types.ServiceNetworkConfig{"backend": nil}
+	name, err = composeNetworkName(project)
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", name, "the declared name of the network is used")
+}
+
+func TestDockerComposeAPIWithComposeNetwork(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WithEnv(map[string]string{"bar": "BAR"}).
+		WaitForService("nginx", wait.ForListeningPort("80/tcp")).
+		Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+
+	network, err := compose.Network(ctx)
+	assert.NoError(t, err, "compose.Network()")
+	assert.Equal(t, compose.Project().Name+"_default", network.Name)
+
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:      "docker.io/alpine:latest",
+			Cmd:        []string{"wget", "-q", "-O", "-", "http://nginx:80/"},
+			WaitingFor: wait.ForExit().WithExitCode(0),
+		},
+		Started: true,
+	}
+	WithComposeNetwork(compose, "client")(&req)
+	assert.Equal(t, []string{network.Name}, req.Networks)
+
+	client, err := GenericContainer(ctx, req)
+	assert.NoError(t, err, "the client resolves the service by name")
+	if client != nil {
+		t.Cleanup(func() {
+			assert.NoError(t, client.Terminate(context.Background()))
+		})
+	}
+}
+
+func TestDockerComposeAPINetworks(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.WithEnv(map[string]string{"bar": "BAR"}).Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+
+	networks, err := compose.Networks(ctx)
+	assert.NoError(t, err, "compose.Networks()")
+	require.Len(t, networks, 1)
+	assert.Equal(t, compose.Project().Name+"_default", networks[0].Name)
+
+	resource, err := networks[0].Inspect(ctx)
+	assert.NoError(t, err, "network.Inspect()")
+	assert.NotEmpty(t, resource.IPAM.Config, "the subnet of the network is allocated")
+
+	client, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine:latest",
+			Cmd:   []string{"sleep", "infinity"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Terminate(context.Background()))
+	})
+
+	assert.NoError(t, networks[0].Connect(ctx, client, "client"), "network.Connect()")
+	aliases, err := client.NetworkAliases(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, aliases[networks[0].Name], "client")
+
+	assert.NoError(t, networks[0].Disconnect(ctx, client), "network.Disconnect()")
+	names, err := client.Networks(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, names, networks[0].Name)
+}
+
+func TestDockerComposeAPIValidateErrors(t *testing.T) {
+	invalidFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+	assert.NoError(t, os.WriteFile(invalidFile, []byte("services:\n  nginx:\n    image: [\n"), 0o600))
+
+	compose, err := NewDockerCompose(invalidFile)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	err = compose.Validate(context.Background())
+	assert.Error(t, err, "the compose file is not valid YAML")
+	assert.Nil(t, compose.Project())
+
+	compose, err = NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	err = compose.
+		WaitForService("mysql", wait.ForListeningPort("3306/tcp")).
+		Validate(context.Background())
+	assert.EqualError(t, err, "invalid compose project: no service mysql to wait for")
+}
+
+//go:embed testresources/docker-compose-simple.yml
+var embeddedComposeFiles embed.FS
+
+func TestDockerComposeAPIWithStackFS(t *testing.T) {
+	compose, err := NewDockerComposeWith(ComposeStackFS(embeddedComposeFiles, "testresources/docker-compose-simple.yml"))
+	assert.NoError(t, err, "NewDockerComposeWith()")
+
+	tempDir := compose.tempDir
+	assert.FileExists(t, filepath.Join(tempDir, "testresources", "docker-compose-simple.yml"))
+
+	err = compose.WithEnv(map[string]string{"bar": "BAR"}).Validate(context.Background())
+	assert.NoError(t, err, "Validate()")
+	assert.Equal(t, []string{"nginx"}, compose.Services())
+
+	assert.NoError(t, compose.Down(context.Background()), "compose.Down()")
+	assert.NoDirExists(t, tempDir, "the temporary directory is removed by Down")
+
+	assert.NoError(t, compose.Validate(context.Background()), "Validate() after Down()")
+	assert.FileExists(t, filepath.Join(tempDir, "testresources", "docker-compose-simple.yml"), "the compose files are written again")
+	assert.NoError(t, compose.Down(context.Background()), "compose.Down()")
+}
+
+func TestDockerComposeAPIWithStackFSUpAfterDown(t *testing.T) {
+	compose, err := NewDockerComposeWith(ComposeStackFS(embeddedComposeFiles, "testresources/docker-compose-simple.yml"))
+	require.NoError(t, err, "NewDockerComposeWith()")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	stack := compose.WithEnv(map[string]string{"bar": "BAR"})
+	require.NoError(t, stack.Up(ctx, Wait(true)), "compose.Up()")
+	require.NoError(t, stack.Down(ctx, RemoveOrphans(true)), "compose.Down()")
+
+	require.NoError(t, stack.Up(ctx, Wait(true)), "compose.Up() after Down()")
+	t.Cleanup(func() {
+		assert.NoError(t, stack.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	_, err = stack.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "the stack is started again")
+}
+
+func TestDockerComposeAPIWithStackFSMissingFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docker-compose.yml": &fstest.MapFile{Data: []byte("services:\n  nginx:\n    image: nginx\n")},
+	}
+
+	_, err := NewDockerComposeWith(ComposeStackFS(fsys, "docker-compose.yml", "docker-compose.override.yml"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestDockerComposeAPIWithStackURLs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	content, err := os.ReadFile("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err)
+
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	checksum := sha256Hex(content)
+	fileURL := server.URL + "/stacks/docker-compose-simple.yml"
+
+	for i := 0; i < 2; i++ {
+		compose, err := NewDockerComposeWith(ComposeStackURLs(fileURL + "#sha256=" + checksum))
+		assert.NoError(t, err, "NewDockerComposeWith()")
+
+		err = compose.WithEnv(map[string]string{"bar": "BAR"}).Validate(context.Background())
+		assert.NoError(t, err, "Validate()")
+		assert.Equal(t, []string{"nginx"}, compose.Services())
+		assert.Equal(t, "docker-compose-simple.yml", filepath.Base(compose.configs[0]))
+	}
+	assert.Equal(t, 1, downloads, "the verified file is cached")
+
+	_, err = NewDockerComposeWith(ComposeStackURLs(fileURL))
+	assert.NoError(t, err, "NewDockerComposeWith()")
+	assert.Equal(t, 2, downloads, "files without checksum are downloaded again")
+
+	_, err = NewDockerComposeWith(ComposeStackURLs(server.URL + "/other.yml#sha256=" + sha256Hex([]byte("tampered"))))
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestStackTempDir(t *testing.T) {
+	first, err := stackTempDir("orders/it #1")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(first) })
+
+	second, err := stackTempDir("orders/it #1")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(second) })
+
+	assert.True(t, strings.HasPrefix(filepath.Base(first), "testcontainers-compose-orders_it_1-"), first)
+	assert.NotEqual(t, first, second, "the stacks of parallel runs sharing an identifier get their own directory")
+}
+
+func TestSanitizeProjectName(t *testing.T) {
+	tests := []struct {
+		identifier string
+		expected   string
+	}{
+		{"orders", "orders"},
+		{"Orders_IT-1", "orders_it-1"},
+		{"orders/it #1", "ordersit1"},
+		{"-_orders", "orders"},
+	}
+
+	for _, tt := range tests {
+		name, err := sanitizeProjectName(tt.identifier)
+		assert.NoError(t, err, tt.identifier)
+		assert.Equal(t, tt.expected, name, tt.identifier)
+	}
+
+	_, err := sanitizeProjectName("#!/")
+	assert.ErrorIs(t, err, ErrInvalidProjectName)
+}
+
+func TestDockerComposeAPIProjectNameInUse(t *testing.T) {
+	ctx := context.Background()
+	identifier := testNameHash(t.Name())
+
+	provider, err := NewDockerProvider()
+	assert.NoError(t, err, "NewDockerProvider()")
+
+	// a container of another session running under the name of the project
+	created, err := provider.client.ContainerCreate(ctx, &container.Config{
+		Image:  "docker.io/nginx:stable-alpine",
+		Labels: map[string]string{api.ProjectLabel: string(identifier)},
+	}, nil, nil, nil, "")
+	assert.NoError(t, err, "ContainerCreate()")
+	t.Cleanup(func() {
+		_ = provider.client.ContainerRemove(ctx, created.ID, types2.ContainerRemoveOptions{Force: true})
+	})
+	assert.NoError(t, provider.client.ContainerStart(ctx, created.ID, types2.ContainerStartOptions{}), "ContainerStart()")
+
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), identifier)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	err = compose.WithEnv(map[string]string{"bar": "BAR"}).Up(ctx, Wait(true))
+	assert.ErrorIs(t, err, ErrProjectNameInUse)
+}
+
+func TestDockerComposeAPIWithHooks(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var calls []string
+	var nginxID string
+	dumpErr := errors.New("dump failed")
+
+	err = compose.
+		OnAfterUp(func(ctx context.Context, stack ComposeStack) error {
+			// the hooks can use the stack
+			nginx, err := stack.ServiceContainer(ctx, "nginx")
+			if err != nil {
+				return err
+			}
+			nginxID = nginx.GetContainerID()
+			calls = append(calls, "after-up")
+			return nil
+		}).
+		OnBeforeDown(func(ctx context.Context, stack ComposeStack) error {
+			nginx, err := stack.ServiceContainer(ctx, "nginx")
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, nginxID, nginx.GetContainerID(), "the containers still exist")
+			calls = append(calls, "dump")
+			return dumpErr
+		}).
+		OnBeforeDown(func(ctx context.Context, stack ComposeStack) error {
+			calls = append(calls, "collect logs")
+			return nil
+		}).
+		Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+
+	err = compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal)
+	assert.ErrorIs(t, err, dumpErr, "the failure of a hook is reported")
+	assert.Equal(t, []string{"after-up", "dump", "collect logs"}, calls)
+
+	ps, err := compose.Ps(context.Background())
+	assert.NoError(t, err, "compose.Ps()")
+	assert.Empty(t, ps, "the stack is torn down even if a hook failed")
+}
+
+func TestDockerComposeAPIWithFailingAfterUpHook(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	seedErr := errors.New("seed failed")
+	var next bool
+	err = compose.
+		OnAfterUp(func(ctx context.Context, stack ComposeStack) error {
+			return seedErr
+		}).
+		OnAfterUp(func(ctx context.Context, stack ComposeStack) error {
+			next = true
+			return nil
+		}).
+		Up(ctx, Wait(true))
+	assert.ErrorIs(t, err, seedErr)
+	assert.False(t, next, "the hooks after the failing one are not executed")
+}
+
+func TestDockerComposeAPIWithStackReaders(t *testing.T) {
+	compose, err := NewDockerComposeWith(
+		StackIdentifier("readers"),
+		ComposeStackReaders(strings.NewReader("services:\n  nginx:\n    image: docker.io/nginx:stable-alpine\n")),
+	)
+	assert.NoError(t, err, "NewDockerComposeWith()")
+	assert.True(t, strings.HasPrefix(filepath.Base(compose.tempDir), "testcontainers-compose-readers-"), compose.tempDir)
+
+	assert.NoError(t, compose.Validate(context.Background()), "Validate()")
+	assert.Equal(t, []string{"nginx"}, compose.Services())
+
+	tempDir := compose.tempDir
+	assert.DirExists(t, tempDir)
+	assert.NoError(t, compose.Down(context.Background()), "compose.Down()")
+	assert.NoDirExists(t, tempDir, "the temporary directory is removed by Down")
+
+	assert.NoError(t, compose.Validate(context.Background()), "Validate() after Down()")
+	assert.FileExists(t, filepath.Join(tempDir, "docker-compose-reader-0.yml"), "the compose files are written again")
+	assert.NoError(t, compose.Down(context.Background()), "compose.Down()")
+
+	_, err = NewDockerComposeWith(ComposeStackReaders(iotest.ErrReader(errors.New("connection reset"))))
+	assert.ErrorContains(t, err, "connection reset", "the failure to read a file is reported")
+
+	_, err = NewDockerComposeWith(ComposeStackReaders(nil))
+	assert.Error(t, err)
+}
+
+func TestDockerComposeAPIOneShotServices(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-one-shot.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	err = compose.
+		WaitForService("migrations", wait.ForExit().WithExitCode(0)).
+		WaitForService("nginx", wait.ForExit()).
+		Validate(context.Background())
+	assert.NoError(t, err, "Validate()")
+
+	// nginx is restarted by the daemon, so it is never done
+	assert.Equal(t, map[string]bool{"migrations": true}, compose.oneShotServices())
+}
+
+func TestDockerComposeAPIWaitForOneShotService(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-one-shot.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WaitForService("migrations", wait.ForExit().WithExitCode(0)).
+		Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+}
+
+func TestDockerComposeAPIWaitForFailingOneShotService(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-one-shot.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WithEnv(map[string]string{"MIGRATIONS_EXIT_CODE": "3"}).
+		WaitForService("migrations", wait.ForExit().WithExitCode(0)).
+		Up(ctx, Wait(true))
+	assert.EqualError(t, err, "service migrations not ready: container exited with code 3 instead of 0")
+}
+
+func TestDockerComposeAPIWaitForUnhealthyDependency(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-healthcheck.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.Up(ctx, Wait(true))
+	assert.ErrorContains(t, err, "dependency database of service api is not healthy")
+	assert.ErrorContains(t, err, "last health check exited with code 1: database is not accepting connections")
+}
+
+func TestLastHealthcheck(t *testing.T) {
+	assert.Empty(t, lastHealthcheck(nil))
+	assert.Empty(t, lastHealthcheck(&types2.Health{Status: types2.Starting}))
+
+	health := &types2.Health{
+		Status: types2.Unhealthy,
+		Log: []*types2.HealthcheckResult{
+			{ExitCode: 1, Output: "connection refused\n"},
+			{ExitCode: 2, Output: "timeout\n"},
+		},
+	}
+	assert.Equal(t, ", last health check exited with code 2: timeout", lastHealthcheck(health))
+}
+
+func TestDockerComposeAPIReuseRequiresIdentifier(t *testing.T) {
+	_, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), WithReuse(true))
+	assert.EqualError(t, err, "stack reuse requires a StackIdentifier")
+}
+
+func TestDockerComposeAPIWithReuse(t *testing.T) {
+	identifier := testNameHash(t.Name())
+	env := map[string]string{"bar": "BAR"}
+
+	t.Cleanup(func() {
+		// a stack without reuse tears the shared one down
+		compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), identifier)
+		assert.NoError(t, err, "NewDockerComposeWith()")
+		assert.NoError(t, compose.WithEnv(env).Up(context.Background()), "compose.Up()")
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	containerID := func() string {
+		compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), identifier, WithReuse(true))
+		assert.NoError(t, err, "NewDockerComposeWith()")
+
+		assert.NoError(t, compose.WithEnv(env).Up(ctx, Wait(true)), "compose.Up()")
+		defer func() {
+			assert.NoError(t, compose.Down(ctx), "compose.Down()")
+		}()
+
+		c, err := compose.ServiceContainer(ctx, "nginx")
+		assert.NoError(t, err, "compose.ServiceContainer()")
+
+		return c.GetContainerID()
+	}
+
+	first := containerID()
+	assert.Equal(t, first, containerID(), "the running stack is reused")
+
+	env["bar"] = "BAZ"
+	assert.NotEqual(t, first, containerID(), "the stack is recreated when its configuration changed")
+}
+
+func TestStackDownOptions(t *testing.T) {
+	var options stackDownOptions
+	for _, opt := range []StackDownOption{RemoveOrphans(true), RemoveVolumes(true), WithDownTimeout(30 * time.Second)} {
+		opt.applyToStackDown(&options)
+	}
+
+	assert.True(t, options.RemoveOrphans)
+	assert.True(t, options.Volumes)
+	if assert.NotNil(t, options.Timeout) {
+		assert.Equal(t, 30*time.Second, *options.Timeout)
+	}
+}
+
+func TestDockerComposeAPIWithDownTimeout(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-stop-signal.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	start := time.Now()
+	assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), WithDownTimeout(time.Second)), "compose.Down()")
+	assert.Less(t, time.Since(start), 8*time.Second, "the service ignoring SIGTERM is killed after the timeout")
+}
+
+func TestDockerComposeAPIWithScale(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-no-exposed-ports.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true), WithScale(map[string]int{"nginx": 3})), "compose.Up()")
+
+	replicas, err := compose.ServiceContainers(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainers()")
+	assert.Equal(t, 3, len(replicas))
+
+	first, err := compose.ServiceContainer(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainer()")
+	assert.Equal(t, replicas[0].GetContainerID(), first.GetContainerID())
+
+	stats, err := compose.Stats(ctx, "nginx")
+	assert.NoError(t, err, "compose.Stats()")
+	assert.GreaterOrEqual(t, stats.PIDs, uint64(3), "the usage of the replicas is summed")
+	assert.Greater(t, stats.MemoryUsage, uint64(0))
+
+	for _, replica := range replicas {
+		port, err := replica.MappedPort(ctx, "80/tcp")
+		assert.NoError(t, err, "replica.MappedPort()")
+		assert.NotEmpty(t, port.Port())
+	}
+}
+
+func TestDockerComposeAPIWaitForScaledService(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-no-exposed-ports.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// no replica ever gets ready
+	err = compose.
+		WaitForService("nginx", wait.ForExec([]string{"test", "-f", "/ready"}).WithStartupTimeout(5*time.Second)).
+		Up(ctx, Wait(true), WithScale(map[string]int{"nginx": 3}))
+	assert.ErrorContains(t, err, "service nginx not ready, 3 of 3 replicas failed: replica 1: ")
+}
+
+func TestReplicasNotReadyError(t *testing.T) {
+	notReady := errors.New("connection refused")
+
+	assert.NoError(t, replicasNotReadyError("nginx", []error{nil, nil}, false))
+
+	err := replicasNotReadyError("nginx", []error{notReady}, false)
+	assert.EqualError(t, err, "service nginx not ready: connection refused")
+
+	err = replicasNotReadyError("nginx", []error{nil, notReady, context.DeadlineExceeded}, true)
+	assert.EqualError(t, err, "service nginx not ready before the deadline, 2 of 3 replicas failed: "+
+		"replica 2: connection refused; replica 3: context deadline exceeded")
+	assert.ErrorIs(t, err, notReady)
+}
+
+func TestScaleServices(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			{Name: "nginx"},
+			{Name: "mysql"},
+		},
+	}
+
+	assert.NoError(t, scaleServices(project, map[string]int{"nginx": 2}))
+
+	nginx, err := project.GetService("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), *nginx.Deploy.Replicas)
+
+	mysql, err := project.GetService("mysql")
+	assert.NoError(t, err)
+	assert.Nil(t, mysql.Deploy)
+
+	assert.Error(t, scaleServices(project, map[string]int{"redis": 2}), "unknown service")
+	assert.Error(t, scaleServices(project, map[string]int{"nginx": -1}), "negative scale")
+}
+
+func TestAttachExternalNetworks(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			{Name: "nginx", Networks: map[string]*types.ServiceNetworkConfig{"default": nil}},
+			{Name: "sidecar", NetworkMode: "service:nginx"},
+		},
+		Networks: types.Networks{"default": {}},
+	}
+
+	assert.NoError(t, attachExternalNetworks(project, []string{"shared"}))
+
+	assert.Equal(t, types.NetworkConfig{Name: "shared", External: types.External{External: true}}, project.Networks["shared"])
+	assert.Equal(t, map[string]*types.ServiceNetworkConfig{"default": nil, "shared": nil}, project.Services[0].Networks)
+	assert.Nil(t, project.Services[1].Networks, "the network mode of the service is kept")
+
+	assert.EqualError(t, attachExternalNetworks(project, []string{"default"}), "network default is already declared by the project")
+}
+
+func TestDockerComposeAPIExec(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
 
-	assert.NoError(t, err, "compose.Up()")
+	code, reader, err := compose.Exec(ctx, "nginx", []string{"cat", "/etc/nginx/nginx.conf"}, tcexec.Multiplexed())
+	assert.NoError(t, err, "compose.Exec()")
+	assert.Equal(t, 0, code)
 
-	serviceNames := compose.Services()
+	output, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), "worker_processes")
 
-	assert.Equal(t, 2, len(serviceNames))
-	assert.Contains(t, serviceNames, "nginx")
-	assert.Contains(t, serviceNames, "mysql")
+	_, _, err = compose.Exec(ctx, "mysql", []string{"true"})
+	assert.Error(t, err, "Exec into an unknown service")
 }
 
-func TestDockerComposeAPIWithRunServices(t *testing.T) {
-	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+func TestDockerComposeAPIServiceEndpoint(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
 	assert.NoError(t, err, "NewDockerCompose()")
 
 	t.Cleanup(func() {
@@ -86,19 +1361,124 @@ func TestDockerComposeAPIWithRunServices(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
-	err = compose.
-		WaitForService("nginx", wait.NewHTTPStrategy("/").WithPort("80/tcp").WithStartupTimeout(10*time.Second)).
-		Up(ctx, Wait(true), RunServices("nginx"))
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
 
-	assert.NoError(t, err, "compose.Up()")
+	host, err := compose.ServiceHost(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceHost()")
+	assert.NotEmpty(t, host)
 
-	serviceNames := compose.Services()
+	port, err := compose.ServicePort(ctx, "nginx", "80/tcp")
+	assert.NoError(t, err, "compose.ServicePort()")
+	assert.Equal(t, "9080", port.Port())
 
-	_, err = compose.ServiceContainer(context.Background(), "mysql")
-	assert.Error(t, err, "Make sure there is no mysql container")
+	endpoint, err := compose.Endpoint(ctx, "nginx", "80/tcp", "http")
+	assert.NoError(t, err, "compose.Endpoint()")
+	assert.Equal(t, fmt.Sprintf("http://%s:9080", host), endpoint)
 
-	assert.Equal(t, 1, len(serviceNames))
-	assert.Contains(t, serviceNames, "nginx")
+	_, err = compose.ServicePort(ctx, "nginx", "443/tcp")
+	assert.Error(t, err, "port not published")
+}
+
+func TestDockerComposeAPIServiceAs(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.WithEnv(map[string]string{"bar": "BAR"}).Up(ctx, Wait(true)), "compose.Up()")
+
+	env, err := ServiceAs(ctx, compose, "nginx", ContainerEnv)
+	assert.NoError(t, err, "ServiceAs()")
+	assert.Equal(t, "BAR", env["bar"])
+
+	failure := errors.New("not a database")
+	_, err = ServiceAs(ctx, compose, "nginx", func(context.Context, Container) (string, error) {
+		return "", failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.EqualError(t, err, "not a database: failed to wrap the container of service nginx")
+
+	_, err = ServiceAs(ctx, compose, "mysql", ContainerEnv)
+	assert.Error(t, err, "unknown service")
+}
+
+func TestDockerComposeAPIRunOneOff(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-no-exposed-ports.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	result, err := compose.RunOneOff(ctx, "nginx",
+		[]string{"sh", "-c", "echo $GREETING; echo oops >&2; wget -q -O /dev/null http://nginx && exit 3"},
+		tcexec.WithEnv([]string{"GREETING=hello"}),
+	)
+	assert.NoError(t, err, "compose.RunOneOff()")
+	assert.Equal(t, 3, result.ExitCode, "the one-off container reaches the service through the stack network")
+	assert.Equal(t, "hello\n", string(result.Stdout))
+	assert.Equal(t, "oops\n", string(result.Stderr))
+
+	containers, err := compose.ServiceContainers(ctx, "nginx")
+	assert.NoError(t, err, "compose.ServiceContainers()")
+	assert.Equal(t, 1, len(containers), "the one-off container is not a replica of the service")
+}
+
+func TestOneOffContainerConfig(t *testing.T) {
+	dbURL := "postgres://db"
+	project := &types.Project{
+		Name: "stack",
+		Volumes: types.Volumes{
+			"data": {Name: "stack_data"},
+		},
+		Networks: types.Networks{
+			"default": {Name: "stack_default"},
+			"backend": {Name: "stack_backend"},
+		},
+	}
+	service := types.ServiceConfig{
+		Name:         "migrate",
+		Image:        "migrate:latest",
+		Command:      types.ShellCommand{"up"},
+		Environment:  types.MappingWithEquals{"DB_URL": &dbURL, "UNSET": nil},
+		WorkingDir:   "/app",
+		CustomLabels: map[string]string{api.OneoffLabel: "False", api.ServiceLabel: "migrate"},
+		Volumes: []types.ServiceVolumeConfig{
+			{Type: types.VolumeTypeVolume, Source: "data", Target: "/data"},
+			{Type: types.VolumeTypeBind, Source: "/host/sql", Target: "/sql", ReadOnly: true},
+		},
+		Networks: map[string]*types.ServiceNetworkConfig{"default": nil, "backend": nil},
+	}
+
+	config, hostConfig := oneOffContainerConfig(project, service, types2.ExecConfig{Env: []string{"VERBOSE=1"}, User: "root"})
+
+	assert.Equal(t, "migrate:latest", config.Image)
+	assert.Equal(t, []string{"up"}, []string(config.Cmd), "the command of the service is used by default")
+	assert.ElementsMatch(t, []string{"DB_URL=postgres://db", "VERBOSE=1"}, config.Env)
+	assert.Equal(t, "root", config.User)
+	assert.Equal(t, "/app", config.WorkingDir)
+	assert.Equal(t, "True", config.Labels[api.OneoffLabel])
+	assert.Equal(t, "migrate", config.Labels[api.ServiceLabel])
+
+	assert.Equal(t, 2, len(hostConfig.Mounts))
+	assert.Equal(t, "stack_data", hostConfig.Mounts[0].Source)
+	assert.Equal(t, "/host/sql", hostConfig.Mounts[1].Source)
+	assert.True(t, hostConfig.Mounts[1].ReadOnly)
+
+	assert.Equal(t, []string{"stack_backend", "stack_default"}, oneOffNetworks(project, service))
+
+	config, _ = oneOffContainerConfig(project, service, types2.ExecConfig{Cmd: []string{"down"}})
+	assert.Equal(t, []string{"down"}, []string(config.Cmd))
 }
 
 func TestDockerComposeAPIWithWaitForService(t *testing.T) {
@@ -255,6 +1635,53 @@ func TestDockerComposeAPIWithFailedStrategy(t *testing.T) {
 	assert.Contains(t, serviceNames, "nginx")
 }
 
+func TestDockerComposeAPIWithFailedStrategyLogs(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WithEnv(map[string]string{
+			"bar": "BAR",
+		}).
+		WaitForService("nginx", wait.ForLog("never logged").WithStartupTimeout(5*time.Second)).
+		Up(ctx, Wait(true), WithFailureLogLines(20))
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "service nginx not ready")
+	assert.Contains(t, err.Error(), "--- last 20 log lines of service nginx, replica 1 ---")
+	assert.Contains(t, err.Error(), "Configuration complete; ready for start up")
+}
+
+func TestContainerReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    types2.ContainerState
+		oneShot  bool
+		expected bool
+	}{
+		{"running", types2.ContainerState{Status: "running", Running: true}, false, true},
+		{"healthy", types2.ContainerState{Status: "running", Running: true, Health: &types2.Health{Status: types2.Healthy}}, false, true},
+		{"starting", types2.ContainerState{Status: "running", Running: true, Health: &types2.Health{Status: types2.Starting}}, false, false},
+		{"exited", types2.ContainerState{Status: "exited"}, false, false},
+		{"one-shot completed", types2.ContainerState{Status: "exited"}, true, true},
+		{"one-shot failed", types2.ContainerState{Status: "exited", ExitCode: 1}, true, false},
+		{"one-shot running", types2.ContainerState{Status: "running", Running: true}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, containerReady(&tt.state, tt.oneShot))
+		})
+	}
+}
+
 func TestDockerComposeAPIComplex(t *testing.T) {
 	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
 	assert.NoError(t, err, "NewDockerCompose()")
@@ -275,6 +1702,30 @@ func TestDockerComposeAPIComplex(t *testing.T) {
 	assert.Contains(t, serviceNames, "mysql")
 }
 
+func TestDockerComposeAPIAllServiceContainers(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-complex.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assert.NoError(t, compose.Up(ctx, Wait(true)), "compose.Up()")
+
+	containers, err := compose.AllServiceContainers(ctx)
+	assert.NoError(t, err, "compose.AllServiceContainers()")
+	assert.Len(t, containers, 2)
+
+	for _, svc := range []string{"nginx", "mysql"} {
+		container, err := compose.ServiceContainer(ctx, svc)
+		assert.NoError(t, err, "compose.ServiceContainer()")
+		assert.Same(t, container, containers[svc], "the cached container is returned")
+	}
+}
+
 func TestDockerComposeAPIWithEnvironment(t *testing.T) {
 	identifier := testNameHash(t.Name())
 
@@ -308,6 +1759,212 @@ func TestDockerComposeAPIWithEnvironment(t *testing.T) {
 	assertContainerEnvironmentVariables(t, identifier.String(), "nginx", present, absent)
 }
 
+func TestDockerComposeAPIWithEnvStruct(t *testing.T) {
+	identifier := testNameHash(t.Name())
+
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), identifier)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	config := struct {
+		Bar string `compose:"bar"`
+	}{
+		Bar: "BAR",
+	}
+
+	err = compose.WithEnvStruct(&config).Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+
+	present := map[string]string{
+		"bar": "BAR",
+	}
+	absent := map[string]string{}
+	assertContainerEnvironmentVariables(t, identifier.String(), "nginx", present, absent)
+}
+
+func TestDockerComposeAPIWithServiceEnv(t *testing.T) {
+	identifier := testNameHash(t.Name())
+
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), identifier)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WithEnv(map[string]string{
+			"bar": "BAR",
+		}).
+		Up(ctx, Wait(true), WithServiceEnv("nginx", map[string]string{"bar": "OVERRIDDEN", "foo": "FOO"}))
+	assert.NoError(t, err, "compose.Up()")
+
+	present := map[string]string{
+		"bar": "OVERRIDDEN",
+		"foo": "FOO",
+	}
+	absent := map[string]string{}
+	assertContainerEnvironmentVariables(t, identifier.String(), "nginx", present, absent)
+
+	err = compose.Up(ctx, Wait(true), WithServiceEnv("redis", map[string]string{"foo": "FOO"}))
+	assert.Error(t, err, "unknown service")
+}
+
+func TestDockerComposeAPIWithSecretContent(t *testing.T) {
+	identifier := testNameHash(t.Name())
+
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-secrets.yml"), identifier)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.Up(ctx, Wait(true),
+		WithSecretContent("db_password", []byte("s3cr3t")),
+		WithConfigContent("greeting", []byte("hello")),
+	)
+	assert.NoError(t, err, "compose.Up()")
+
+	for path, expected := range map[string]string{
+		"/run/secrets/db_password":           "s3cr3t",
+		"/usr/share/nginx/html/greeting.txt": "hello",
+	} {
+		code, reader, err := compose.Exec(ctx, "nginx", []string{"cat", path}, tcexec.Multiplexed())
+		assert.NoError(t, err, "compose.Exec()")
+		assert.Equal(t, 0, code)
+
+		output, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(output))
+	}
+
+	err = compose.Up(ctx, WithSecretContent("api_token", []byte("t0k3n")))
+	assert.Error(t, err, "undeclared secret")
+}
+
+func TestWriteFileObjectContents(t *testing.T) {
+	compose := &dockerCompose{
+		name: "secrets",
+		project: &types.Project{
+			Secrets: types.Secrets{
+				"db_password": {File: "/src/db_password.txt"},
+				"api_token":   {Environment: "API_TOKEN"},
+				"external":    {External: types.External{External: true}},
+			},
+			Configs: types.Configs{
+				"greeting": {File: "/src/greeting.txt"},
+			},
+		},
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(compose.tempDir))
+	})
+
+	err := compose.writeFileObjectContents(
+		map[string][]byte{"db_password": []byte("s3cr3t"), "api_token": []byte("t0k3n")},
+		map[string][]byte{"greeting": []byte("hello")},
+	)
+	assert.NoError(t, err)
+
+	for name, expected := range map[string]string{"db_password": "s3cr3t", "api_token": "t0k3n"} {
+		secret := compose.project.Secrets[name]
+		assert.Empty(t, secret.Environment)
+		assert.True(t, strings.HasPrefix(secret.File, compose.tempDir), secret.File)
+		content, err := os.ReadFile(secret.File)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(content))
+	}
+
+	content, err := os.ReadFile(compose.project.Configs["greeting"].File)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	assert.Error(t, compose.writeFileObjectContents(map[string][]byte{"external": nil}, nil), "external secret")
+	assert.Error(t, compose.writeFileObjectContents(nil, map[string][]byte{"missing": nil}), "undeclared config")
+}
+
+func TestOverrideServiceEnv(t *testing.T) {
+	bar := "BAR"
+	project := &types.Project{
+		Services: types.Services{
+			{Name: "nginx", Environment: types.MappingWithEquals{"bar": &bar}},
+			{Name: "mysql"},
+		},
+	}
+
+	assert.NoError(t, overrideServiceEnv(project, map[string]map[string]string{
+		"nginx": {"foo": "FOO"},
+		"mysql": {"MYSQL_DATABASE": "orders"},
+	}))
+
+	nginx, err := project.GetService("nginx")
+	assert.NoError(t, err)
+	assert.Equal(t, "BAR", *nginx.Environment["bar"])
+	assert.Equal(t, "FOO", *nginx.Environment["foo"])
+
+	mysql, err := project.GetService("mysql")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", *mysql.Environment["MYSQL_DATABASE"])
+	assert.NotContains(t, mysql.Environment, "foo")
+
+	assert.Error(t, overrideServiceEnv(project, map[string]map[string]string{"redis": {"foo": "FOO"}}), "unknown service")
+}
+
+func TestDockerComposeAPIWithEnvFile(t *testing.T) {
+	identifier := testNameHash(t.Name())
+
+	compose, err := NewDockerComposeWith(WithStackFiles("./testresources/docker-compose-simple.yml"), identifier)
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WithEnvFile("./testdata/envfiles/compose.env").
+		Up(ctx, Wait(true))
+
+	assert.NoError(t, err, "compose.Up()")
+
+	present := map[string]string{
+		"bar": "FROM_FILE",
+	}
+	absent := map[string]string{}
+	assertContainerEnvironmentVariables(t, identifier.String(), "nginx", present, absent)
+}
+
+func TestWithEnvFiles(t *testing.T) {
+	options, err := cli.NewProjectOptions(nil,
+		withEnv(map[string]string{"APP_PORT": "9090"}),
+		withEnvFiles([]string{"./testdata/envfiles/app.env", "./testdata/envfiles/app.test.env"}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "app", options.Environment["APP_NAME"])
+	assert.Equal(t, "9090", options.Environment["APP_PORT"], "explicit variables take precedence")
+	assert.Equal(t, "test", options.Environment["APP_MODE"], "later files override earlier ones")
+	assert.Equal(t, "true", options.Environment["APP_DEBUG"])
+
+	_, err = cli.NewProjectOptions(nil, withEnvFiles([]string{"./testdata/envfiles/missing.env"}))
+	assert.Error(t, err)
+}
+
 func TestDockerComposeAPIWithMultipleComposeFiles(t *testing.T) {
 	composeFiles := ComposeStackFiles{
 		"testresources/docker-compose-simple.yml",
@@ -365,6 +2022,24 @@ func TestDockerComposeAPIWithVolume(t *testing.T) {
 	assert.NoError(t, err, "compose.Up()")
 }
 
+func TestDockerComposeAPIWithVolumeRemoved(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-volume.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.Up(ctx, Wait(true))
+	assert.NoError(t, err, "compose.Up()")
+
+	err = compose.Down(context.Background(), RemoveOrphans(true), RemoveVolumes(true), RemoveImagesLocal)
+	assert.NoError(t, err, "compose.Down()")
+
+	volumeList, err := compose.dockerClient.VolumeList(ctx, filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, compose.name))))
+	assert.NoError(t, err, "VolumeList()")
+	assert.Empty(t, volumeList.Volumes, "the volumes of the stack are removed")
+}
+
 func TestDockerComposeAPIWithBuild(t *testing.T) {
 	compose, err := NewDockerCompose("./testresources/docker-compose-build.yml")
 	assert.NoError(t, err, "NewDockerCompose()")
@@ -383,6 +2058,24 @@ func TestDockerComposeAPIWithBuild(t *testing.T) {
 	assert.NoError(t, err, "compose.Up()")
 }
 
+func TestDockerComposeAPIWithBuildOptions(t *testing.T) {
+	compose, err := NewDockerCompose("./testresources/docker-compose-build.yml")
+	assert.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	err = compose.
+		WaitForService("echo", wait.ForHTTP("/env").WithPort("8080/tcp")).
+		Up(ctx, Wait(true), WithBuild(api.BuildOptions{NoCache: true, Quiet: true}))
+
+	assert.NoError(t, err, "compose.Up()")
+}
+
 func TestDockerComposeApiWithWaitForShortLifespanService(t *testing.T) {
 	compose, err := NewDockerCompose("./testresources/docker-compose-short-lifespan.yml")
 	assert.NoError(t, err, "NewDockerCompose()")
@@ -0,0 +1,489 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/cli"
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeBackend is the engine running the compose stacks
+type ComposeBackend int
+
+const (
+	// ComposeBackendLibrary runs the stacks with the compose library linked into the tests, the default
+	ComposeBackendLibrary ComposeBackend = iota
+	// ComposeBackendLocal runs the stacks with the docker compose CLI installed on the host, or docker-compose if the
+	// compose plugin is missing, for the compose files using features of the compose specification the compose
+	// library does not support yet
+	ComposeBackendLocal
+)
+
+func (b ComposeBackend) applyToComposeStack(o *composeStackOptions) {
+	o.Backend = b
+}
+
+// localComposeService implements the operations of the compose service used by the stacks with the compose CLI
+type localComposeService struct {
+	// the other operations are not used by the stacks
+	api.Service
+
+	// command running the compose CLI, e.g. docker compose
+	command []string
+
+	// host of the Docker daemon the CLI connects to, the one of the stack
+	dockerHost string
+
+	logger Logging
+}
+
+// newLocalComposeService looks up the compose CLI, preferring the compose plugin of the docker CLI to docker-compose
+func newLocalComposeService(dockerHost string, logger Logging) (*localComposeService, error) {
+	var command []string
+	if docker, err := exec.LookPath("docker"); err == nil && exec.Command(docker, "compose", "version").Run() == nil {
+		command = []string{docker, "compose"}
+	} else if dockerCompose, err := exec.LookPath("docker-compose"); err == nil {
+		command = []string{dockerCompose}
+	} else {
+		return nil, errors.New("the local compose backend requires the docker compose plugin or docker-compose")
+	}
+
+	return &localComposeService{command: command, dockerHost: dockerHost, logger: logger}, nil
+}
+
+// loadProject loads the project as configured by the CLI, which parses the compose files and interpolates their
+// variables. The configuration printed by the CLI is loaded without schema validation, so that the attributes the
+// compose library does not know are ignored instead of failing the stack.
+func (s *localComposeService) loadProject(options *cli.ProjectOptions, profiles []string) (*types.Project, error) {
+	workingDir, err := options.GetWorkingDir()
+	if err != nil {
+		return nil, err
+	}
+	workingDir, err = filepath.Abs(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]string, 0, len(options.ConfigPaths))
+	for _, config := range options.ConfigPaths {
+		abs, err := filepath.Abs(config)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, abs)
+	}
+
+	args := []string{"--project-name", options.Name, "--project-directory", workingDir}
+	for _, config := range configs {
+		args = append(args, "--file", config)
+	}
+	for _, profile := range profiles {
+		args = append(args, "--profile", profile)
+	}
+
+	config, err := s.run(context.Background(), options.Environment, append(args, "config")...)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := loader.Load(types.ConfigDetails{
+		WorkingDir:  workingDir,
+		ConfigFiles: []types.ConfigFile{{Filename: filepath.Join(workingDir, "docker-compose.yml"), Content: config}},
+		Environment: options.Environment,
+	}, func(o *loader.Options) {
+		o.SetProjectName(options.Name, true)
+		o.SkipValidation = true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to load the configuration of the compose CLI", err)
+	}
+
+	project.ComposeFiles = configs
+	project.Environment = options.Environment
+
+	return project, nil
+}
+
+// Build builds the images of the services
+func (s *localComposeService) Build(ctx context.Context, project *types.Project, options api.BuildOptions) error {
+	args, cleanup, err := s.projectArgs(project)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	args = append(args, "build")
+	if options.Pull {
+		args = append(args, "--pull")
+	}
+	if options.NoCache {
+		args = append(args, "--no-cache")
+	}
+	for k, v := range options.Args {
+		if v == nil {
+			args = append(args, "--build-arg", k)
+		} else {
+			args = append(args, "--build-arg", k+"="+*v)
+		}
+	}
+
+	_, err = s.run(ctx, project.Environment, append(args, options.Services...)...)
+	return err
+}
+
+// Up creates and starts the containers of the services in the background
+func (s *localComposeService) Up(ctx context.Context, project *types.Project, options api.UpOptions) error {
+	args, cleanup, err := s.projectArgs(project)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	args = append(args, "up", "--detach")
+	switch options.Create.Recreate {
+	case api.RecreateForce:
+		args = append(args, "--force-recreate")
+	case api.RecreateNever:
+		args = append(args, "--no-recreate")
+	}
+	if options.Create.RecreateDependencies == api.RecreateForce {
+		args = append(args, "--always-recreate-deps")
+	}
+	if options.Create.RemoveOrphans {
+		args = append(args, "--remove-orphans")
+	}
+	if options.Start.Wait {
+		args = append(args, "--wait")
+	}
+	for _, service := range project.Services {
+		if service.Deploy != nil && service.Deploy.Replicas != nil {
+			args = append(args, "--scale", fmt.Sprintf("%s=%d", service.Name, *service.Deploy.Replicas))
+		}
+	}
+
+	_, err = s.run(ctx, project.Environment, append(args, options.Create.Services...)...)
+	return err
+}
+
+// Down removes the containers and networks of the stack
+func (s *localComposeService) Down(ctx context.Context, projectName string, options api.DownOptions) error {
+	args := []string{"--project-name", projectName, "down"}
+	if options.RemoveOrphans {
+		args = append(args, "--remove-orphans")
+	}
+	if options.Volumes {
+		args = append(args, "--volumes")
+	}
+	if options.Images != "" {
+		args = append(args, "--rmi", options.Images)
+	}
+	args = append(args, timeoutArgs(options.Timeout)...)
+
+	_, err := s.run(ctx, nil, args...)
+	return err
+}
+
+// Stop stops the containers of the services
+func (s *localComposeService) Stop(ctx context.Context, projectName string, options api.StopOptions) error {
+	args := append([]string{"--project-name", projectName, "stop"}, timeoutArgs(options.Timeout)...)
+
+	_, err := s.run(ctx, nil, append(args, options.Services...)...)
+	return err
+}
+
+// Start starts the stopped containers of the services
+func (s *localComposeService) Start(ctx context.Context, projectName string, options api.StartOptions) error {
+	_, err := s.run(ctx, nil, append([]string{"--project-name", projectName, "start"}, options.Services...)...)
+	return err
+}
+
+// Restart restarts the containers of the services
+func (s *localComposeService) Restart(ctx context.Context, projectName string, options api.RestartOptions) error {
+	args := append([]string{"--project-name", projectName, "restart"}, timeoutArgs(options.Timeout)...)
+
+	_, err := s.run(ctx, nil, append(args, options.Services...)...)
+	return err
+}
+
+// Kill sends the signal to the containers of the services
+func (s *localComposeService) Kill(ctx context.Context, projectName string, options api.KillOptions) error {
+	args := []string{"--project-name", projectName, "kill"}
+	if options.Signal != "" {
+		args = append(args, "--signal", options.Signal)
+	}
+
+	_, err := s.run(ctx, nil, append(args, options.Services...)...)
+	return err
+}
+
+// Pause pauses the containers of the services
+func (s *localComposeService) Pause(ctx context.Context, projectName string, options api.PauseOptions) error {
+	_, err := s.run(ctx, nil, append([]string{"--project-name", projectName, "pause"}, options.Services...)...)
+	return err
+}
+
+// UnPause resumes the containers of the services
+func (s *localComposeService) UnPause(ctx context.Context, projectName string, options api.PauseOptions) error {
+	_, err := s.run(ctx, nil, append([]string{"--project-name", projectName, "unpause"}, options.Services...)...)
+	return err
+}
+
+// Ps lists the containers of the services
+func (s *localComposeService) Ps(ctx context.Context, projectName string, options api.PsOptions) ([]api.ContainerSummary, error) {
+	args := []string{"--project-name", projectName, "ps", "--format", "json"}
+	if options.All {
+		args = append(args, "--all")
+	}
+
+	out, err := s.run(ctx, nil, append(args, options.Services...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseComposePs(out)
+}
+
+// projectArgs returns the arguments of the commands configuring the project: its name, directory and compose files,
+// then a file overriding them with the settings of the stack, removed by the returned function
+func (s *localComposeService) projectArgs(project *types.Project) ([]string, func(), error) {
+	override, err := composeOverride(project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.CreateTemp("", "testcontainers-compose-override-*.yml")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		_ = os.Remove(f.Name())
+	}
+	_, err = f.Write(override)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	args := []string{"--project-name", project.Name, "--project-directory", project.WorkingDir}
+	for _, config := range project.ComposeFiles {
+		args = append(args, "--file", config)
+	}
+	args = append(args, "--file", f.Name())
+	for _, profile := range project.Services.GetProfiles() {
+		args = append(args, "--profile", profile)
+	}
+
+	return args, cleanup, nil
+}
+
+// run runs the compose CLI with the environment of the process, the given variables and the Docker host of the
+// stack, returning its standard output. Its standard error, the progress of the command, is logged.
+func (s *localComposeService) run(ctx context.Context, env map[string]string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.command[0], append(s.command[1:], args...)...)
+
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	if s.dockerHost != "" {
+		cmd.Env = append(cmd.Env, "DOCKER_HOST="+s.dockerHost)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(&stderr, &loggingWriter{logger: s.logger})
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s %s failed: %s", err, strings.Join(s.command, " "), strings.Join(args, " "),
+			strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// composeOverride returns a compose file applying the settings of the stack to the services, networks and volumes
+// of the project, e.g. the image substitutions, the overridden commands and environments, and the labels of the
+// session. The dollar signs of the values are escaped, the project being interpolated already.
+func composeOverride(project *types.Project) ([]byte, error) {
+	services := map[string]interface{}{}
+	for _, s := range project.Services {
+		service := map[string]interface{}{}
+
+		if s.Image != "" {
+			service["image"] = escapeDollars(s.Image)
+		}
+		if s.Command != nil {
+			service["command"] = escapeDollarsAll(s.Command)
+		}
+		if s.Entrypoint != nil {
+			service["entrypoint"] = escapeDollarsAll(s.Entrypoint)
+		}
+		if s.PullPolicy != "" {
+			service["pull_policy"] = s.PullPolicy
+		}
+
+		if len(s.Environment) > 0 {
+			environment := map[string]interface{}{}
+			for k, v := range s.Environment {
+				if v == nil {
+					environment[k] = nil
+				} else {
+					environment[k] = escapeDollars(*v)
+				}
+			}
+			service["environment"] = environment
+		}
+
+		// the CLI sets its own labels
+		labels := map[string]string{}
+		for k, v := range s.CustomLabels {
+			if !strings.HasPrefix(k, "com.docker.compose.") {
+				labels[k] = escapeDollars(v)
+			}
+		}
+		if len(labels) > 0 {
+			service["labels"] = labels
+		}
+
+		// all the networks of a service attached to an external network are written, the default network the compose
+		// files attach it to implicitly would be lost otherwise
+		if attachedToExternalNetwork(project, s) {
+			serviceNetworks := map[string]interface{}{}
+			for k, n := range s.Networks {
+				if n == nil || len(n.Aliases) == 0 {
+					serviceNetworks[k] = nil
+				} else {
+					serviceNetworks[k] = map[string]interface{}{"aliases": n.Aliases}
+				}
+			}
+			service["networks"] = serviceNetworks
+		}
+
+		services[s.Name] = service
+	}
+
+	override := map[string]interface{}{"services": services}
+
+	networks := map[string]interface{}{}
+	for k, n := range project.Networks {
+		if n.External.External {
+			networks[k] = map[string]interface{}{"name": escapeDollars(n.Name), "external": true}
+		} else if len(n.Labels) > 0 {
+			networks[k] = map[string]interface{}{"labels": n.Labels}
+		}
+	}
+	if len(networks) > 0 {
+		override["networks"] = networks
+	}
+
+	volumes := map[string]interface{}{}
+	for k, v := range project.Volumes {
+		if !v.External.External && len(v.Labels) > 0 {
+			volumes[k] = map[string]interface{}{"labels": v.Labels}
+		}
+	}
+	if len(volumes) > 0 {
+		override["volumes"] = volumes
+	}
+
+	// the secrets and configs whose content is set at Up are written to temporary files
+	secrets := map[string]interface{}{}
+	for k, sc := range project.Secrets {
+		if !sc.External.External && sc.File != "" {
+			secrets[k] = map[string]interface{}{"file": escapeDollars(sc.File)}
+		}
+	}
+	if len(secrets) > 0 {
+		override["secrets"] = secrets
+	}
+
+	configs := map[string]interface{}{}
+	for k, c := range project.Configs {
+		if !c.External.External && c.File != "" {
+			configs[k] = map[string]interface{}{"file": escapeDollars(c.File)}
+		}
+	}
+	if len(configs) > 0 {
+		override["configs"] = configs
+	}
+
+	return yaml.Marshal(override)
+}
+
+// attachedToExternalNetwork reports whether the service is attached to an external network of the project
+func attachedToExternalNetwork(project *types.Project, s types.ServiceConfig) bool {
+	for k := range s.Networks {
+		if project.Networks[k].External.External {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComposePs parses the containers listed by the compose CLI, a JSON array or, for the recent versions,
+// a JSON object per line
+func parseComposePs(out []byte) ([]api.ContainerSummary, error) {
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var summaries []api.ContainerSummary
+	if out[0] == '[' {
+		if err := json.Unmarshal(out, &summaries); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse the containers of the compose CLI", err)
+		}
+		return summaries, nil
+	}
+
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var summary api.ContainerSummary
+		if err := json.Unmarshal(line, &summary); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse the containers of the compose CLI", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// timeoutArgs returns the timeout flag of the CLI, in seconds, if the timeout is set
+func timeoutArgs(timeout *time.Duration) []string {
+	if timeout == nil {
+		return nil
+	}
+	return []string{"--timeout", strconv.Itoa(int(timeout.Seconds()))}
+}
+
+func escapeDollars(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func escapeDollarsAll(values []string) []string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = escapeDollars(v)
+	}
+	return escaped
+}
@@ -0,0 +1,136 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestComposeOverride(t *testing.T) {
+	password := "pa$$word"
+	project := &types.Project{
+		Services: types.Services{
+			{
+				Name:        "postgres",
+				Image:       "registry.corp/mirror/postgres:15",
+				Command:     types.ShellCommand{"postgres", "-c", "log_statement=all"},
+				Environment: types.MappingWithEquals{"POSTGRES_PASSWORD": &password, "TZ": nil},
+				CustomLabels: map[string]string{
+					api.ProjectLabel:            "stack",
+					TestcontainerLabelSessionID: "session",
+				},
+				Networks: map[string]*types.ServiceNetworkConfig{
+					"default":  nil,
+					"external": {Aliases: []string{"db"}},
+				},
+			},
+			{
+				Name:     "client",
+				Image:    "docker.io/alpine",
+				Networks: map[string]*types.ServiceNetworkConfig{"default": nil},
+			},
+		},
+		Networks: types.Networks{
+			"default":  {Labels: types.Labels{TestcontainerLabelSessionID: "session"}},
+			"external": {Name: "shared", External: types.External{External: true}, Labels: types.Labels{"a": "b"}},
+		},
+		Secrets: types.Secrets{
+			"db_password": {File: "/tmp/testcontainers-compose-stack-1/secrets/db_password"},
+			"external":    {External: types.External{External: true}},
+		},
+	}
+
+	override, err := composeOverride(project)
+	require.NoError(t, err)
+
+	var actual map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(override, &actual))
+	assert.Equal(t, map[string]interface{}{
+		"services": map[string]interface{}{
+			"postgres": map[string]interface{}{
+				"image":       "registry.corp/mirror/postgres:15",
+				"command":     []interface{}{"postgres", "-c", "log_statement=all"},
+				"environment": map[string]interface{}{"POSTGRES_PASSWORD": "pa$$$$word", "TZ": nil},
+				"labels":      map[string]interface{}{TestcontainerLabelSessionID: "session"},
+				"networks": map[string]interface{}{
+					"default":  nil,
+					"external": map[string]interface{}{"aliases": []interface{}{"db"}},
+				},
+			},
+			"client": map[string]interface{}{
+				"image": "docker.io/alpine",
+			},
+		},
+		"networks": map[string]interface{}{
+			"default":  map[string]interface{}{"labels": map[string]interface{}{TestcontainerLabelSessionID: "session"}},
+			"external": map[string]interface{}{"name": "shared", "external": true},
+		},
+		"secrets": map[string]interface{}{
+			"db_password": map[string]interface{}{"file": "/tmp/testcontainers-compose-stack-1/secrets/db_password"},
+		},
+	}, actual)
+}
+
+func TestParseComposePs(t *testing.T) {
+	expected := []api.ContainerSummary{
+		{ID: "1", Name: "stack-nginx-1", Service: "nginx", State: "running", Health: "healthy",
+			Publishers: api.PortPublishers{{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 9080, Protocol: "tcp"}}},
+		{ID: "2", Name: "stack-db-1", Service: "db", State: "exited", ExitCode: 1},
+	}
+
+	array := `[{"ID":"1","Name":"stack-nginx-1","Service":"nginx","State":"running","Health":"healthy",` +
+		`"Publishers":[{"URL":"0.0.0.0","TargetPort":80,"PublishedPort":9080,"Protocol":"tcp"}]},` +
+		`{"ID":"2","Name":"stack-db-1","Service":"db","State":"exited","ExitCode":1}]`
+	summaries, err := parseComposePs([]byte(array))
+	require.NoError(t, err)
+	assert.Equal(t, expected, summaries)
+
+	lines := `{"ID":"1","Name":"stack-nginx-1","Service":"nginx","State":"running","Health":"healthy",` +
+		`"Publishers":[{"URL":"0.0.0.0","TargetPort":80,"PublishedPort":9080,"Protocol":"tcp"}]}` + "\n" +
+		`{"ID":"2","Name":"stack-db-1","Service":"db","State":"exited","ExitCode":1}` + "\n"
+	summaries, err = parseComposePs([]byte(lines))
+	require.NoError(t, err)
+	assert.Equal(t, expected, summaries, "recent versions print an object per line")
+
+	summaries, err = parseComposePs([]byte("\n"))
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+}
+
+func TestDockerComposeAPIWithLocalBackend(t *testing.T) {
+	compose, err := NewDockerComposeWith(
+		WithStackFiles("./testresources/docker-compose-simple.yml"),
+		WithServiceCommand("nginx", []string{"nginx-debug", "-g", "daemon off;"}),
+		ComposeBackendLocal,
+	)
+	require.NoError(t, err, "NewDockerCompose()")
+
+	t.Cleanup(func() {
+		assert.NoError(t, compose.Down(context.Background(), RemoveOrphans(true), RemoveImagesLocal), "compose.Down()")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, compose.WithEnv(map[string]string{"bar": "BAR"}).Up(ctx, Wait(true)), "compose.Up()")
+	assert.Equal(t, []string{"nginx"}, compose.Services())
+
+	nginx, err := compose.ServiceContainer(ctx, "nginx")
+	require.NoError(t, err, "compose.ServiceContainer()")
+
+	inspect, err := nginx.inspectContainer(ctx)
+	require.NoError(t, err, "nginx.inspectContainer()")
+	assert.Equal(t, []string{"nginx-debug", "-g", "daemon off;"}, []string(inspect.Config.Cmd))
+	assert.Contains(t, inspect.Config.Env, "bar=BAR")
+	assert.Equal(t, sessionID().String(), inspect.Config.Labels[TestcontainerLabelSessionID])
+
+	statuses, err := compose.Ps(ctx)
+	require.NoError(t, err, "compose.Ps()")
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "nginx", statuses[0].Service)
+}
@@ -0,0 +1,168 @@
+package testcontainers
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// composeEnvTag is the tag naming the interpolation variable of a field, e.g. `compose:"POSTGRES_PORT"`
+const composeEnvTag = "compose"
+
+// flattenEnvStruct returns the interpolation variables of the tagged fields of a struct, or of a pointer to a struct.
+//
+// The tag names the variable of the field, with the omitempty option to leave out the zero value, e.g.
+// `compose:"POSTGRES_PORT,omitempty"`, and "-" or no tag skips the field. The fields of the embedded structs without
+// tag are flattened as if they were fields of the struct, and the fields of a tagged struct are flattened with the tag
+// as prefix, e.g. DB_PORT for the PORT field of a struct tagged DB.
+//
+// The values are formatted with their MarshalText or String method if they have one, e.g. time.Duration as 30s,
+// strings, booleans and numbers as strconv does, and slices as the comma separated values of their elements. Nil
+// pointers are left out.
+func flattenEnvStruct(v interface{}) (map[string]string, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("interpolation variables must be a struct or a pointer to a struct, got %T", v)
+	}
+
+	env := map[string]string{}
+	if err := flattenEnvFields(value, "", env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func flattenEnvFields(value reflect.Value, prefix string, env map[string]string) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := value.Field(i)
+
+		tag, hasTag := field.Tag.Lookup(composeEnvTag)
+		if tag == "-" {
+			continue
+		}
+
+		if !hasTag {
+			if field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct {
+				if fieldValue, ok := indirect(fieldValue); ok {
+					if err := flattenEnvFields(fieldValue, prefix, env); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			return fmt.Errorf("field %s of %s is tagged but not exported", field.Name, t)
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return fmt.Errorf("field %s of %s has no variable name", field.Name, t)
+		}
+		name = prefix + name
+
+		fieldValue, ok := indirect(fieldValue)
+		if !ok || (opts == "omitempty" && fieldValue.IsZero()) {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Struct && !hasTextFormat(fieldValue) {
+			if err := flattenEnvFields(fieldValue, name+"_", env); err != nil {
+				return err
+			}
+			continue
+		}
+
+		formatted, err := formatEnvValue(fieldValue)
+		if err != nil {
+			return fmt.Errorf("%w: field %s of %s", err, field.Name, t)
+		}
+		if _, ok := env[name]; ok {
+			return fmt.Errorf("interpolation variable %s is set by several fields", name)
+		}
+		env[name] = formatted
+	}
+
+	return nil
+}
+
+// formatEnvValue formats a value of a field as an interpolation variable
+func formatEnvValue(value reflect.Value) (string, error) {
+	if value.CanInterface() {
+		switch v := value.Interface().(type) {
+		case encoding.TextMarshaler:
+			text, err := v.MarshalText()
+			return string(text), err
+		case fmt.Stringer:
+			return v.String(), nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), nil
+	case reflect.Slice, reflect.Array:
+		elems := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elem, ok := indirect(value.Index(i))
+			if !ok {
+				continue
+			}
+			formatted, err := formatEnvValue(elem)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, formatted)
+		}
+		return strings.Join(elems, ","), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s for an interpolation variable", value.Type())
+}
+
+// hasTextFormat reports whether the value formats itself with its MarshalText or String method
+func hasTextFormat(value reflect.Value) bool {
+	if !value.CanInterface() {
+		return false
+	}
+	switch value.Interface().(type) {
+	case encoding.TextMarshaler, fmt.Stringer:
+		return true
+	}
+	return false
+}
+
+// indirect dereferences the pointers to the value, reporting false for a nil pointer
+func indirect(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return value, false
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
+
+// indirectType returns the type the pointer type points to, or the type itself if it is not a pointer
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
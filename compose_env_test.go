@@ -0,0 +1,76 @@
+package testcontainers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envDatabase struct {
+	Host string `compose:"HOST"`
+	Port int    `compose:"PORT"`
+}
+
+type envLabels struct {
+	Labels map[string]string `compose:"LABELS"`
+}
+
+type envBase struct {
+	Image string `compose:"IMAGE"`
+}
+
+func TestFlattenEnvStruct(t *testing.T) {
+	tag := "15-alpine"
+	config := struct {
+		envBase
+		Version   *string       `compose:"POSTGRES_VERSION"`
+		Missing   *string       `compose:"MISSING"`
+		Debug     bool          `compose:"DEBUG"`
+		Ratio     float64       `compose:"RATIO"`
+		Timeout   time.Duration `compose:"TIMEOUT"`
+		Profiles  []string      `compose:"PROFILES"`
+		Replicas  uint          `compose:"REPLICAS,omitempty"`
+		DB        envDatabase   `compose:"DB"`
+		Untagged  string
+		Skipped   string `compose:"-"`
+		unrelated int
+	}{
+		envBase:  envBase{Image: "docker.io/postgres"},
+		Version:  &tag,
+		Debug:    true,
+		Ratio:    0.5,
+		Timeout:  30 * time.Second,
+		Profiles: []string{"db", "cache"},
+		DB:       envDatabase{Host: "db", Port: 5432},
+		Untagged: "untagged",
+		Skipped:  "skipped",
+	}
+
+	env, err := flattenEnvStruct(&config)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"IMAGE":            "docker.io/postgres",
+		"POSTGRES_VERSION": "15-alpine",
+		"DEBUG":            "true",
+		"RATIO":            "0.5",
+		"TIMEOUT":          "30s",
+		"PROFILES":         "db,cache",
+		"DB_HOST":          "db",
+		"DB_PORT":          "5432",
+	}, env)
+}
+
+func TestFlattenEnvStructErrors(t *testing.T) {
+	_, err := flattenEnvStruct(map[string]string{"PORT": "5432"})
+	assert.EqualError(t, err, "interpolation variables must be a struct or a pointer to a struct, got map[string]string")
+
+	_, err = flattenEnvStruct(envLabels{Labels: map[string]string{}})
+	assert.EqualError(t, err, "unsupported type map[string]string for an interpolation variable: field Labels of testcontainers.envLabels")
+
+	_, err = flattenEnvStruct(struct {
+		Port  int    `compose:"PORT"`
+		Other string `compose:"PORT"`
+	}{})
+	assert.EqualError(t, err, "interpolation variable PORT is set by several fields")
+}
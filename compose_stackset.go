@@ -0,0 +1,108 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// StackSet manages several compose stacks as one, e.g. the stacks of independently owned projects a test spans. The
+// services of all the stacks are attached to a network shared by the set, on which they resolve each other by service
+// name. The stacks are started in order, each one once the services of the previous ones are ready, and torn down in
+// the reverse order.
+type StackSet struct {
+	stacks []ComposeStack
+
+	// used to synchronize Up and Down
+	lock sync.Mutex
+
+	// network shared by the services of the stacks, nil until the set is started
+	network     Network
+	networkName string
+}
+
+// NewStackSet returns the set of the given stacks, started in the given order
+func NewStackSet(stacks ...ComposeStack) *StackSet {
+	return &StackSet{stacks: stacks}
+}
+
+// Stacks returns the stacks of the set, in the order they are started
+func (s *StackSet) Stacks() []ComposeStack {
+	return s.stacks
+}
+
+// NetworkName returns the name of the network shared by the services of the stacks, empty until the set is started
+func (s *StackSet) NetworkName() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.networkName
+}
+
+// Up creates the network shared by the stacks, and starts the stacks in order with the given options. Each stack is
+// started once the services of the previous ones are ready, according to their wait strategies, and Up returns once
+// the services of all the stacks are ready. The context limits the time to start the whole set. The stacks started
+// before a failure are left running, to be torn down with Down.
+func (s *StackSet) Up(ctx context.Context, opts ...StackUpOption) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.network == nil {
+		name := fmt.Sprintf("testcontainers-stackset-%s", uuid.NewString())
+		network, err := GenericNetwork(ctx, GenericNetworkRequest{
+			NetworkRequest: NetworkRequest{
+				Name:           name,
+				CheckDuplicate: true,
+				Attachable:     true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		s.network = network
+		s.networkName = name
+	}
+
+	stackOpts := append(append([]StackUpOption(nil), opts...), WithExternalNetwork(s.networkName))
+	for i, stack := range s.stacks {
+		if err := stack.Up(ctx, stackOpts...); err != nil {
+			return fmt.Errorf("%w: failed to start stack %s of the set", err, stackSetMember(i, stack))
+		}
+	}
+
+	return nil
+}
+
+// Down tears down the stacks in the reverse order with the given options, then removes the shared network. All the
+// stacks are torn down even if one of them fails to, the first error is returned.
+func (s *StackSet) Down(ctx context.Context, opts ...StackDownOption) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var firstErr error
+	for i := len(s.stacks) - 1; i >= 0; i-- {
+		if err := s.stacks[i].Down(ctx, opts...); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%w: failed to tear down stack %s of the set", err, stackSetMember(i, s.stacks[i]))
+		}
+	}
+
+	if s.network != nil {
+		if err := s.network.Remove(ctx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%w: failed to remove network %s", err, s.networkName)
+		}
+		s.network = nil
+		s.networkName = ""
+	}
+
+	return firstErr
+}
+
+// stackSetMember names a stack of a set in errors, by its project name once it is compiled and by its position
+func stackSetMember(i int, stack ComposeStack) string {
+	if project := stack.Project(); project != nil {
+		return fmt.Sprintf("%s (#%d)", project.Name, i+1)
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
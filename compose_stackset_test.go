@@ -0,0 +1,44 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	types2 "github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestStackSet(t *testing.T) {
+	server, err := NewDockerCompose("./testresources/docker-compose-simple.yml")
+	require.NoError(t, err, "NewDockerCompose()")
+	client, err := NewDockerCompose("./testresources/docker-compose-stackset-client.yml")
+	require.NoError(t, err, "NewDockerCompose()")
+
+	set := NewStackSet(server.WithEnv(map[string]string{"bar": "BAR"}), client)
+	assert.Equal(t, []ComposeStack{server, client}, set.Stacks())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	server.WaitForService("nginx", wait.ForHTTP("/").WithPort("80/tcp"))
+	require.NoError(t, set.Up(ctx, Wait(true)), "set.Up()")
+
+	networkName := set.NetworkName()
+	assert.NotEmpty(t, networkName)
+
+	// the client of the second stack resolves the server of the first one by its service name
+	code, _, err := client.Exec(ctx, "client", []string{"wget", "-q", "-O", "/dev/null", "http://nginx:80/"})
+	require.NoError(t, err, "client.Exec()")
+	assert.Equal(t, 0, code)
+
+	require.NoError(t, set.Down(context.Background(), RemoveOrphans(true)), "set.Down()")
+	assert.Empty(t, set.NetworkName())
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+	_, err = provider.client.NetworkInspect(context.Background(), networkName, types2.NetworkInspectOptions{})
+	assert.Error(t, err, "the shared network is removed")
+}
@@ -5,13 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/compose-spec/compose-go/dotenv"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/go-connections/nat"
 
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -43,18 +47,24 @@ type Container interface {
 	Ports(context.Context) (nat.PortMap, error)                     // get all exposed ports
 	SessionID() string                                              // get session id
 	IsRunning() bool
-	Start(context.Context) error                 // start the container
-	Stop(context.Context, *time.Duration) error  // stop the container
-	Terminate(context.Context) error             // terminate the container
-	Logs(context.Context) (io.ReadCloser, error) // Get logs of the container
+	Start(context.Context) error                                                     // start the container
+	Stop(context.Context, *time.Duration) error                                      // stop the container
+	StopWithSignal(ctx context.Context, signal string, timeout *time.Duration) error // stop the container with a custom signal
+	Terminate(context.Context) error                                                 // terminate the container
+	Logs(context.Context) (io.ReadCloser, error)                                     // Get logs of the container
+	StartupLogs() []string                                                           // get the log lines written until the container was ready
 	FollowOutput(LogConsumer)
 	StartLogProducer(context.Context) error
 	StopLogProducer() error
 	Name(context.Context) (string, error)                        // get container name
 	State(context.Context) (*types.ContainerState, error)        // returns container's running state
+	ExitInfo(context.Context) (*ExitInfo, error)                 // returns how the container exited
+	Stats(context.Context) (*ContainerStats, error)              // returns the resource usage of the container
 	Networks(context.Context) ([]string, error)                  // get container networks
 	NetworkAliases(context.Context) (map[string][]string, error) // get container network aliases for a network
-	Exec(ctx context.Context, cmd []string) (int, io.Reader, error)
+	Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error)
+	// ExecWithResult executes a command and captures its output
+	ExecWithResult(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (*ExecResult, error)
 	ContainerIP(context.Context) (string, error)    // get container ip
 	ContainerIPs(context.Context) ([]string, error) // get all container IPs
 	CopyToContainer(ctx context.Context, fileContent []byte, containerFilePath string, fileMode int64) error
@@ -63,6 +73,44 @@ type Container interface {
 	CopyFileFromContainer(ctx context.Context, filePath string) (io.ReadCloser, error)
 }
 
+// ExecResult holds the exit code and the captured output of a command executed in a container
+type ExecResult struct {
+	ExitCode int
+	Stdout   []byte
+	Stderr   []byte
+	combined []byte
+}
+
+// Combined returns stdout and stderr interleaved in the order the command wrote them
+func (r *ExecResult) Combined() []byte {
+	return r.combined
+}
+
+// ExitInfo describes how the main process of a container terminated
+type ExitInfo struct {
+	Running    bool      // whether the container is still running, the other fields are then meaningless
+	ExitCode   int       // exit code of the main process
+	OOMKilled  bool      // whether the process was killed because the container ran out of memory
+	Error      string    // error reported by the daemon, e.g. when the command could not be executed
+	FinishedAt time.Time // time the process exited, zero if it never did
+}
+
+// String describes the exit cause in a single line, suitable for error messages
+func (e ExitInfo) String() string {
+	if e.Running {
+		return "container is running"
+	}
+
+	msg := fmt.Sprintf("container exited with code %d", e.ExitCode)
+	if e.OOMKilled {
+		msg += ", killed because out of memory"
+	}
+	if e.Error != "" {
+		msg += ": " + e.Error
+	}
+	return msg
+}
+
 // ImageBuildInfo defines what is needed to build an image
 type ImageBuildInfo interface {
 	GetContext() (io.Reader, error)   // the path to the build context
@@ -82,6 +130,8 @@ type FromDockerfile struct {
 	PrintBuildLog  bool               // enable user to print build log
 }
 
+// ContainerFile is a file of the host copied to the container before it starts. The directory of ContainerFilePath
+// must exist in the image, as the parent directories of a copied file are not created.
 type ContainerFile struct {
 	HostFilePath      string
 	ContainerFilePath string
@@ -91,35 +141,45 @@ type ContainerFile struct {
 // ContainerRequest represents the parameters used to get a running container
 type ContainerRequest struct {
 	FromDockerfile
-	Image           string
-	Entrypoint      []string
-	Env             map[string]string
-	ExposedPorts    []string // allow specifying protocol info
-	Cmd             []string
-	Labels          map[string]string
-	Mounts          ContainerMounts
-	Tmpfs           map[string]string
-	RegistryCred    string
-	WaitingFor      wait.Strategy
-	Name            string // for specifying container name
-	Hostname        string
-	ExtraHosts      []string
-	Privileged      bool                // for starting privileged container
-	Networks        []string            // for specifying network names
-	NetworkAliases  map[string][]string // for specifying network aliases
-	NetworkMode     container.NetworkMode
-	Resources       container.Resources
-	Files           []ContainerFile // files which will be copied when container starts
-	User            string          // for specifying uid:gid
-	SkipReaper      bool            // indicates whether we skip setting up a reaper for this
-	ReaperImage     string          // alternative reaper image
-	AutoRemove      bool            // if set to true, the container will be removed from the host when stopped
-	AlwaysPullImage bool            // Always pull image
-	ImagePlatform   string          // ImagePlatform describes the platform which the image runs on.
-	Binds           []string
-	ShmSize         int64    // Amount of memory shared with the host (in bytes)
-	CapAdd          []string // Add Linux capabilities
-	CapDrop         []string // Drop Linux capabilities
+	Image             string
+	ImageArchive      string // path of an image archive, of docker save or an OCI image layout, loaded instead of pulling Image
+	ImageArchiveID    string // expected ID of the image of the archive, e.g. sha256:4f2c..., checked before the archive is loaded
+	Entrypoint        []string
+	Env               map[string]string
+	EnvFiles          []string // dotenv files loaded into the environment, values in Env take precedence
+	ExposedPorts      []string // allow specifying protocol info
+	Cmd               []string
+	WorkingDir        string // working directory of the entrypoint and command, defaults to the one of the image
+	Labels            map[string]string
+	Mounts            ContainerMounts
+	Tmpfs             map[string]string
+	RegistryCred      string
+	WaitingFor        wait.Strategy
+	Name              string // for specifying container name
+	Hostname          string
+	ExtraHosts        []string
+	DNS               []string            // addresses of the DNS servers of the container, the ones of Docker if empty
+	Privileged        bool                // for starting privileged container
+	Networks          []string            // for specifying network names
+	NetworkAliases    map[string][]string // for specifying network aliases
+	NetworkMode       container.NetworkMode
+	Resources         container.Resources
+	Files             []ContainerFile           // files which will be copied when container starts
+	User              string                    // for specifying uid:gid
+	GroupAdd          []string                  // additional groups, by name or gid, the user of the container belongs to
+	SkipReaper        bool                      // indicates whether we skip setting up a reaper for this
+	ReaperImage       string                    // alternative reaper image
+	AutoRemove        bool                      // if set to true, the container will be removed from the host when stopped
+	AlwaysPullImage   bool                      // Always pull image
+	ImagePlatform     string                    // ImagePlatform describes the platform which the image runs on.
+	Binds             []string                  // raw bind strings, prefer Mounts which also supports SELinux relabeling
+	ShmSize           int64                     // Amount of memory shared with the host (in bytes)
+	CapAdd            []string                  // Add Linux capabilities
+	CapDrop           []string                  // Drop Linux capabilities
+	RecordStartupLogs bool                      // record the log lines written until the container is ready, see Container.StartupLogs
+	LifecycleHooks    []ContainerLifecycleHooks // hooks executed during the lifecycle of the container
+	ImageSubstitutors []ImageSubstitutor        // rewrite the image before it is pulled, e.g. to use a registry mirror
+	StartCost         int                       // weight of the start against the limit of concurrent starts, 1 if zero
 }
 
 type (
@@ -187,7 +247,9 @@ func (c *ContainerRequest) Validate() error {
 	validationMethods := []func() error{
 		c.validateContextAndImage,
 		c.validateContextOrImageIsSpecified,
+		c.validateImageArchive,
 		c.validateMounts,
+		c.validateUser,
 	}
 
 	var err error
@@ -201,6 +263,24 @@ func (c *ContainerRequest) Validate() error {
 	return nil
 }
 
+// resolveEnv merges the variables read from the env files with Env, which takes precedence
+func (c *ContainerRequest) resolveEnv() (map[string]string, error) {
+	if len(c.EnvFiles) == 0 {
+		return c.Env, nil
+	}
+
+	env, err := dotenv.Read(c.EnvFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read env files", err)
+	}
+
+	for k, v := range c.Env {
+		env[k] = v
+	}
+
+	return env, nil
+}
+
 // GetContext retrieve the build context for the request
 func (c *ContainerRequest) GetContext() (io.Reader, error) {
 	if c.ContextArchive != nil {
@@ -247,13 +327,28 @@ func (c *ContainerRequest) validateContextAndImage() error {
 }
 
 func (c *ContainerRequest) validateContextOrImageIsSpecified() error {
-	if c.FromDockerfile.Context == "" && c.FromDockerfile.ContextArchive == nil && c.Image == "" {
+	if c.FromDockerfile.Context == "" && c.FromDockerfile.ContextArchive == nil && c.Image == "" && c.ImageArchive == "" {
 		return errors.New("you must specify either a build context or an image")
 	}
 
 	return nil
 }
 
+func (c *ContainerRequest) validateImageArchive() error {
+	if c.ImageArchive == "" {
+		if c.ImageArchiveID != "" {
+			return errors.New("you cannot specify an ImageArchiveID without an ImageArchive in a ContainerRequest")
+		}
+		return nil
+	}
+
+	if c.Image != "" || c.ShouldBuildImage() {
+		return errors.New("you cannot specify both an ImageArchive and an Image or Context in a ContainerRequest")
+	}
+
+	return nil
+}
+
 func (c *ContainerRequest) validateMounts() error {
 	targets := make(map[string]bool, len(c.Mounts))
 
@@ -265,6 +360,40 @@ func (c *ContainerRequest) validateMounts() error {
 		} else {
 			targets[targetPath] = true
 		}
+
+		if relabeler, ok := m.Source.(SELinuxRelabeler); ok {
+			switch label := relabeler.GetSELinuxLabel(); label {
+			case "", SELinuxShared, SELinuxPrivate:
+			default:
+				return fmt.Errorf("%w: %q for %s", ErrInvalidSELinuxLabel, label, targetPath)
+			}
+		}
 	}
 	return nil
 }
+
+// userOrGroupRegex matches a user or group given either by name or by numeric id
+var userOrGroupRegex = regexp.MustCompile(`^([0-9]+|[a-zA-Z_][a-zA-Z0-9_.-]*\$?)$`)
+
+// validateUser checks that User has the user[:group] form and that GroupAdd only lists valid groups
+func (c *ContainerRequest) validateUser() error {
+	if c.User != "" {
+		parts := strings.Split(c.User, ":")
+		if len(parts) > 2 {
+			return fmt.Errorf("%w: %q, expected user[:group]", ErrInvalidUser, c.User)
+		}
+		for _, p := range parts {
+			if !userOrGroupRegex.MatchString(p) {
+				return fmt.Errorf("%w: %q, expected user[:group]", ErrInvalidUser, c.User)
+			}
+		}
+	}
+
+	for _, g := range c.GroupAdd {
+		if !userOrGroupRegex.MatchString(g) {
+			return fmt.Errorf("%w: invalid group %q", ErrInvalidUser, g)
+		}
+	}
+
+	return nil
+}
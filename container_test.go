@@ -12,7 +12,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/client"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -52,6 +54,30 @@ func Test_ContainerValidation(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:          "can set image archive without image",
+			ExpectedError: nil,
+			ContainerRequest: ContainerRequest{
+				ImageArchive:   "my-app.tar",
+				ImageArchiveID: "sha256:4f2c8b5a1e6d9c3b7a0f5e8d2c1b4a7f6e9d0c3b2a5f8e1d4c7b0a3f6e9d2c5b",
+			},
+		},
+		{
+			Name:          "cannot set both image archive and image",
+			ExpectedError: errors.New("you cannot specify both an ImageArchive and an Image or Context in a ContainerRequest"),
+			ContainerRequest: ContainerRequest{
+				ImageArchive: "my-app.tar",
+				Image:        "my-app:ci",
+			},
+		},
+		{
+			Name:          "cannot set image archive ID without image archive",
+			ExpectedError: errors.New("you cannot specify an ImageArchiveID without an ImageArchive in a ContainerRequest"),
+			ContainerRequest: ContainerRequest{
+				Image:          "my-app:ci",
+				ImageArchiveID: "sha256:4f2c8b5a1e6d9c3b7a0f5e8d2c1b4a7f6e9d0c3b2a5f8e1d4c7b0a3f6e9d2c5b",
+			},
+		},
 		{
 			Name:          "Can mount same source to multiple targets",
 			ExpectedError: nil,
@@ -420,3 +446,181 @@ func TestVolumeMount(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerRequestEnvFiles(t *testing.T) {
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Env: map[string]string{"APP_PORT": "9090"},
+		},
+	}
+	WithEnvFile("testdata/envfiles/app.env")(&req)
+	WithEnvFile("testdata/envfiles/app.test.env")(&req)
+
+	env, err := req.resolveEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]string{
+		"APP_NAME":  "app",
+		"APP_PORT":  "9090", // Env takes precedence over the files
+		"APP_MODE":  "test", // later files override earlier ones
+		"APP_DEBUG": "true",
+	}, env)
+}
+
+func TestContainerRequestMissingEnvFile(t *testing.T) {
+	req := GenericContainerRequest{}
+	WithEnvFile("testdata/envfiles/missing.env")(&req)
+
+	_, err := req.resolveEnv()
+	assert.Error(t, err)
+}
+
+func TestContainerRequestUserValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		groupAdd []string
+		valid    bool
+	}{
+		{name: "empty", valid: true},
+		{name: "uid", user: "1000", valid: true},
+		{name: "uid and gid", user: "1000:1000", valid: true},
+		{name: "names", user: "postgres:staff", valid: true},
+		{name: "additional groups", user: "1000", groupAdd: []string{"docker", "999"}, valid: true},
+		{name: "missing group", user: "1000:"},
+		{name: "missing user", user: ":1000"},
+		{name: "too many parts", user: "1000:1000:1000"},
+		{name: "invalid name", user: "-user"},
+		{name: "invalid additional group", groupAdd: []string{"docker group"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := ContainerRequest{
+				Image:    "redis:latest",
+				User:     tt.user,
+				GroupAdd: tt.groupAdd,
+			}
+
+			err := req.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidUser)
+			}
+		})
+	}
+}
+
+func TestExitInfoString(t *testing.T) {
+	tests := []struct {
+		info ExitInfo
+		want string
+	}{
+		{info: ExitInfo{Running: true}, want: "container is running"},
+		{info: ExitInfo{ExitCode: 1}, want: "container exited with code 1"},
+		{info: ExitInfo{ExitCode: 137, OOMKilled: true}, want: "container exited with code 137, killed because out of memory"},
+		{
+			info: ExitInfo{ExitCode: 127, Error: "exec: \"app\": executable file not found in $PATH"},
+			want: "container exited with code 127: exec: \"app\": executable file not found in $PATH",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.info.String())
+	}
+}
+
+func TestPostCreateHooks(t *testing.T) {
+	ctx := context.Background()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:      "docker.io/nginx:alpine",
+			Cmd:        []string{"sh", "-c", "cat /tmp/generated.conf && sleep 60"},
+			WaitingFor: wait.ForLog("generated"),
+		},
+		Started: true,
+	}
+	WithLifecycleHooks(ContainerLifecycleHooks{
+		PostCreates: []ContainerHook{
+			func(ctx context.Context, c Container) error {
+				return c.CopyToContainer(ctx, []byte("generated"), "/tmp/generated.conf", 0o644)
+			},
+		},
+	})(&req)
+
+	container, err := GenericContainer(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terminateContainerOnEnd(t, ctx, container)
+}
+
+func TestPostCreateHookFailureRemovesContainer(t *testing.T) {
+	ctx := context.Background()
+
+	var containerID string
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/nginx:alpine",
+		},
+	}
+	WithLifecycleHooks(ContainerLifecycleHooks{
+		PostCreates: []ContainerHook{
+			func(ctx context.Context, c Container) error {
+				containerID = c.GetContainerID()
+				return errors.New("boom")
+			},
+		},
+	})(&req)
+
+	_, err := GenericContainer(ctx, req)
+	require.Error(t, err)
+	require.NotEmpty(t, containerID)
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+	_, err = provider.client.ContainerInspect(ctx, containerID)
+	assert.True(t, client.IsErrNotFound(err), "the container of the failed hook must be removed: %v", err)
+}
+
+func TestPreTerminateHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/nginx:alpine",
+		},
+		Started: true,
+	}
+	WithLifecycleHooks(ContainerLifecycleHooks{
+		PreTerminates: []ContainerHook{
+			func(ctx context.Context, c Container) error {
+				calls = append(calls, "first")
+				return errors.New("boom")
+			},
+			func(ctx context.Context, c Container) error {
+				// the container still exists
+				_, err := c.State(ctx)
+				calls = append(calls, "second")
+				return err
+			},
+		},
+	})(&req)
+
+	container, err := GenericContainer(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = container.Terminate(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
@@ -0,0 +1,163 @@
+package testcontainers
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CoverageFormat identifies the runtime of the system under test, which defines how coverage is enabled
+type CoverageFormat int
+
+const (
+	// GoCoverage enables the coverage of binaries built with "go build -cover", using GOCOVERDIR
+	GoCoverage CoverageFormat = iota
+	// NodeCoverage enables the V8 coverage of Node.js processes, using NODE_V8_COVERAGE
+	NodeCoverage
+	// JaCoCoCoverage enables the JaCoCo agent of JVM processes, using JAVA_TOOL_OPTIONS
+	JaCoCoCoverage
+)
+
+// defaultCoverageContainerDir exists and is writable by any user in almost all images,
+// which matters because Go does not create GOCOVERDIR
+const defaultCoverageContainerDir = "/tmp"
+
+// CoverageCollector configures the system under test to write coverage data and copies it to the host
+// when the container is terminated, merging the data of several containers in the same directory.
+type CoverageCollector struct {
+	HostDir      string         // directory on the host receiving the coverage files, created if needed
+	ContainerDir string         // directory the coverage files are written to in the container, defaults to /tmp
+	Format       CoverageFormat // runtime of the system under test
+	JaCoCoAgent  string         // path of jacocoagent.jar in the image, required by JaCoCoCoverage
+}
+
+// NewCoverageCollector returns a collector of the coverage of Go binaries copying the files to hostDir,
+// which is typically the GOCOVERDIR of the tests
+func NewCoverageCollector(hostDir string) *CoverageCollector {
+	return &CoverageCollector{
+		HostDir:      hostDir,
+		ContainerDir: defaultCoverageContainerDir,
+		Format:       GoCoverage,
+	}
+}
+
+// WithCoverage sets the environment enabling coverage in the container, and registers a pre-terminate hook
+// stopping the container gracefully, so that the runtime flushes the coverage data, and copying the files out.
+func WithCoverage(collector *CoverageCollector) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		if req.Env == nil {
+			req.Env = map[string]string{}
+		}
+
+		dir := collector.containerDir()
+		switch collector.Format {
+		case GoCoverage:
+			req.Env["GOCOVERDIR"] = dir
+		case NodeCoverage:
+			req.Env["NODE_V8_COVERAGE"] = dir
+		case JaCoCoCoverage:
+			agent := fmt.Sprintf("-javaagent:%s=destfile=%s", collector.JaCoCoAgent, path.Join(dir, "jacoco.exec"))
+			req.Env["JAVA_TOOL_OPTIONS"] = strings.TrimSpace(req.Env["JAVA_TOOL_OPTIONS"] + " " + agent)
+		}
+
+		req.LifecycleHooks = append(req.LifecycleHooks, ContainerLifecycleHooks{
+			PreTerminates: []ContainerHook{
+				func(ctx context.Context, c Container) error {
+					if err := c.Stop(ctx, nil); err != nil {
+						return err
+					}
+					return collector.Collect(ctx, c)
+				},
+			},
+		})
+	}
+}
+
+// Collect copies the coverage files of the container to HostDir. The processes writing them
+// must have exited, as most runtimes only write the coverage data on exit.
+// Files already present in HostDir are kept, so that the data of several containers can be merged.
+func (cc *CoverageCollector) Collect(ctx context.Context, c Container) error {
+	dc, ok := c.(*DockerContainer)
+	if !ok {
+		return errors.New("coverage can only be collected from a container created by the Docker provider")
+	}
+
+	if err := os.MkdirAll(cc.HostDir, 0o755); err != nil {
+		return err
+	}
+
+	rc, _, err := dc.provider.client.CopyFromContainer(ctx, dc.ID, cc.containerDir())
+	if err != nil {
+		return fmt.Errorf("%w: failed to copy the coverage files", err)
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Base(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !cc.isCoverageFile(name) {
+			continue
+		}
+
+		dst := filepath.Join(cc.HostDir, name)
+		if _, err := os.Stat(dst); err == nil {
+			if cc.Format == GoCoverage {
+				// Go names its files after the content hash and the process, an existing file is the same data
+				continue
+			}
+			dst = filepath.Join(cc.HostDir, dc.ID[:12]+"-"+name)
+		}
+
+		if err := writeCoverageFile(dst, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (cc *CoverageCollector) containerDir() string {
+	if cc.ContainerDir == "" {
+		return defaultCoverageContainerDir
+	}
+	return cc.ContainerDir
+}
+
+// isCoverageFile tells the coverage files apart from the other files of the container directory
+func (cc *CoverageCollector) isCoverageFile(name string) bool {
+	switch cc.Format {
+	case GoCoverage:
+		return strings.HasPrefix(name, "covmeta.") || strings.HasPrefix(name, "covcounters.")
+	case NodeCoverage:
+		return strings.HasPrefix(name, "coverage-") && strings.HasSuffix(name, ".json")
+	case JaCoCoCoverage:
+		return strings.HasSuffix(name, ".exec")
+	}
+	return false
+}
+
+func writeCoverageFile(dst string, r io.Reader) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
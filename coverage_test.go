@@ -0,0 +1,87 @@
+package testcontainers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCoverageEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		collector *CoverageCollector
+		env       map[string]string
+		want      map[string]string
+	}{
+		{
+			name:      "go",
+			collector: NewCoverageCollector("coverage"),
+			want:      map[string]string{"GOCOVERDIR": "/tmp"},
+		},
+		{
+			name:      "node",
+			collector: &CoverageCollector{Format: NodeCoverage, ContainerDir: "/coverage"},
+			want:      map[string]string{"NODE_V8_COVERAGE": "/coverage"},
+		},
+		{
+			name:      "jacoco",
+			collector: &CoverageCollector{Format: JaCoCoCoverage, JaCoCoAgent: "/opt/jacocoagent.jar"},
+			env:       map[string]string{"JAVA_TOOL_OPTIONS": "-Xmx256m"},
+			want:      map[string]string{"JAVA_TOOL_OPTIONS": "-Xmx256m -javaagent:/opt/jacocoagent.jar=destfile=/tmp/jacoco.exec"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := GenericContainerRequest{ContainerRequest: ContainerRequest{Env: tt.env}}
+			WithCoverage(tt.collector)(&req)
+
+			assert.Equal(t, tt.want, req.Env)
+			require.Len(t, req.LifecycleHooks, 1)
+			assert.Len(t, req.LifecycleHooks[0].PreTerminates, 1)
+		})
+	}
+}
+
+func TestCoverageCollectorIsCoverageFile(t *testing.T) {
+	goCollector := NewCoverageCollector("coverage")
+	assert.True(t, goCollector.isCoverageFile("covmeta.9d1ecb2c3a4f3f5e"))
+	assert.True(t, goCollector.isCoverageFile("covcounters.9d1ecb2c3a4f3f5e.42.1670000000"))
+	assert.False(t, goCollector.isCoverageFile("app.log"))
+
+	nodeCollector := &CoverageCollector{Format: NodeCoverage}
+	assert.True(t, nodeCollector.isCoverageFile("coverage-42-1670000000-0.json"))
+	assert.False(t, nodeCollector.isCoverageFile("package.json"))
+
+	jacocoCollector := &CoverageCollector{Format: JaCoCoCoverage}
+	assert.True(t, jacocoCollector.isCoverageFile("jacoco.exec"))
+	assert.False(t, jacocoCollector.isCoverageFile("app.jar"))
+}
+
+func TestCoverageCollectedOnTerminate(t *testing.T) {
+	ctx := context.Background()
+	hostDir := t.TempDir()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+			// mimics a binary built with -cover, which writes its counters when it exits
+			Cmd: []string{"sh", "-c", `trap 'echo counters > "$GOCOVERDIR/covcounters.abc.1.1"; exit 0' TERM; while true; do sleep 0.1; done`},
+		},
+		Started: true,
+	}
+	WithCoverage(NewCoverageCollector(hostDir))(&req)
+
+	container, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, container.Terminate(ctx))
+
+	content, err := os.ReadFile(filepath.Join(hostDir, "covcounters.abc.1.1"))
+	require.NoError(t, err)
+	assert.Equal(t, "counters\n", string(content))
+}
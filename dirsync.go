@@ -0,0 +1,267 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultSyncInterval = 500 * time.Millisecond
+
+// SyncOptions configures a DirSync
+type SyncOptions struct {
+	Interval time.Duration // how often the host directory is scanned for changes, defaults to 500 milliseconds
+	Ignore   []string      // patterns of path.Match matched against the names of the files and directories to skip
+	Logger   Logging       // logs the failures to sync the changes, defaults to the global Logger
+}
+
+// DirSync keeps a directory of a running container in sync with a directory of the host, so that long-lived
+// development stacks pick up the changes of code or configuration without being restarted. The host directory is
+// scanned periodically, the files created or modified since the last scan are copied to the container, and the
+// removed ones are deleted from it. The container must provide mkdir and rm.
+type DirSync struct {
+	container    Container
+	hostDir      string
+	containerDir string
+	opts         SyncOptions
+
+	// state of the files and directories of the host directory at the last sync, by relative path
+	synced map[string]syncEntry
+	// whether the container directory was created by the first sync
+	created bool
+	lock    sync.Mutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// syncEntry is the state of a file or a directory used to detect its changes
+type syncEntry struct {
+	dir     bool
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// SyncDir copies the host directory to the directory of the container, then keeps it in sync in the background until
+// Stop is called or the context is cancelled. It fails if the initial copy fails.
+func SyncDir(ctx context.Context, c Container, hostDir string, containerDir string, opts SyncOptions) (*DirSync, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultSyncInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = Logger
+	}
+
+	info, err := os.Stat(hostDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", hostDir)
+	}
+
+	s := &DirSync{
+		container:    c,
+		hostDir:      hostDir,
+		containerDir: containerDir,
+		opts:         opts,
+		synced:       map[string]syncEntry{},
+		done:         make(chan struct{}),
+	}
+
+	if err := s.Sync(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx)
+
+	return s, nil
+}
+
+// Stop stops syncing the directory and waits for the sync in progress to finish
+func (s *DirSync) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Sync copies the changes of the host directory since the last sync to the container right away,
+// e.g. before asserting on the behavior of the container after a change
+func (s *DirSync) Sync(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, err := scanSyncDir(s.hostDir, s.opts.Ignore)
+	if err != nil {
+		return err
+	}
+
+	changed, removed := diffSyncDir(s.synced, current)
+	if s.created && len(changed) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	if len(removed) > 0 {
+		rm := []string{"rm", "-rf"}
+		for _, p := range removed {
+			rm = append(rm, path.Join(s.containerDir, p))
+		}
+		if err := execCommand(ctx, s.container, rm...); err != nil {
+			return fmt.Errorf("%w: failed to remove deleted files", err)
+		}
+		for _, p := range removed {
+			delete(s.synced, p)
+		}
+	}
+
+	var dirs []string
+	if !s.created {
+		dirs = append(dirs, s.containerDir)
+	}
+	for _, p := range changed {
+		if current[p].dir {
+			dirs = append(dirs, path.Join(s.containerDir, p))
+		}
+	}
+	if len(dirs) > 0 {
+		if err := execCommand(ctx, s.container, append([]string{"mkdir", "-p"}, dirs...)...); err != nil {
+			return err
+		}
+		s.created = true
+	}
+
+	for _, p := range changed {
+		entry := current[p]
+		if !entry.dir {
+			content, err := os.ReadFile(filepath.Join(s.hostDir, filepath.FromSlash(p)))
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					// removed since the scan, deleted by the next sync
+					continue
+				}
+				return err
+			}
+
+			err = s.container.CopyToContainer(ctx, content, path.Join(s.containerDir, p), int64(entry.mode.Perm()))
+			if err != nil {
+				return fmt.Errorf("%w: failed to copy %s", err, p)
+			}
+		}
+		s.synced[p] = entry
+	}
+
+	return nil
+}
+
+func (s *DirSync) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.opts.Logger.Printf("failed to sync %s to container %s: %s", s.hostDir, s.container.GetContainerID(), err)
+			}
+		}
+	}
+}
+
+// scanSyncDir returns the regular files and the directories of the host directory by slash separated relative path,
+// skipping the ones whose name matches one of the patterns
+func scanSyncDir(dir string, ignore []string) (map[string]syncEntry, error) {
+	entries := map[string]syncEntry{}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p != dir && errors.Is(err, fs.ErrNotExist) {
+				// removed while walking the directory
+				return nil
+			}
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+
+		for _, pattern := range ignore {
+			matched, err := path.Match(pattern, d.Name())
+			if err != nil {
+				return err
+			}
+			if matched {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		entries[filepath.ToSlash(rel)] = syncEntry{
+			dir:     d.IsDir(),
+			size:    info.Size(),
+			mode:    info.Mode(),
+			modTime: info.ModTime(),
+		}
+		return nil
+	})
+
+	return entries, err
+}
+
+// diffSyncDir returns the paths created or modified since the previous scan, and the ones removed, in lexical order.
+// A file replaced by a directory, or the other way around, is both removed and created.
+func diffSyncDir(previous map[string]syncEntry, current map[string]syncEntry) (changed []string, removed []string) {
+	for p, entry := range current {
+		prev, ok := previous[p]
+		if ok && prev.dir != entry.dir {
+			removed = append(removed, p)
+			ok = false
+		}
+		if !ok || (!entry.dir && prev != entry) {
+			changed = append(changed, p)
+		}
+	}
+	for p := range previous {
+		if _, ok := current[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+
+	sort.Strings(changed)
+	sort.Strings(removed)
+
+	return changed, removed
+}
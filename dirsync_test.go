@@ -0,0 +1,137 @@
+package testcontainers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+func TestScanAndDiffSyncDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "conf", "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "app.yml"), []byte("port: 80"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "node_modules", "dep.js"), []byte("dep"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.py"), []byte("print(1)"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.pyc"), []byte("cache"), 0o644))
+
+	previous, err := scanSyncDir(dir, []string{"node_modules", "*.pyc"})
+	require.NoError(t, err)
+
+	changed, removed := diffSyncDir(nil, previous)
+	assert.Equal(t, []string{"conf", "conf/app.yml", "main.py"}, changed)
+	assert.Empty(t, removed)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "app.yml"), []byte("port: 8080"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "main.py")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "main.py"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0o644))
+
+	current, err := scanSyncDir(dir, []string{"node_modules", "*.pyc"})
+	require.NoError(t, err)
+
+	changed, removed = diffSyncDir(previous, current)
+	assert.Equal(t, []string{"README.md", "conf/app.yml", "main.py"}, changed)
+	assert.Equal(t, []string{"main.py"}, removed, "a file replaced by a directory is removed first")
+
+	changed, removed = diffSyncDir(current, current)
+	assert.Empty(t, changed)
+	assert.Empty(t, removed)
+}
+
+// recordingContainer records the commands executed and the files copied, for the tests of DirSync
+type recordingContainer struct {
+	Container
+	commands [][]string
+	copied   []string
+}
+
+func (c *recordingContainer) ExecWithResult(_ context.Context, cmd []string, _ ...tcexec.ProcessOption) (*ExecResult, error) {
+	c.commands = append(c.commands, cmd)
+	return &ExecResult{}, nil
+}
+
+func (c *recordingContainer) CopyToContainer(_ context.Context, _ []byte, containerFilePath string, _ int64) error {
+	c.copied = append(c.copied, containerFilePath)
+	return nil
+}
+
+func TestDirSyncExecsOnlyOnChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.py"), []byte("print(1)"), 0o644))
+
+	c := &recordingContainer{}
+	s, err := SyncDir(ctx, c, dir, "/app", SyncOptions{Interval: time.Hour})
+	require.NoError(t, err)
+	defer s.Stop()
+
+	assert.Equal(t, [][]string{{"mkdir", "-p", "/app"}}, c.commands, "the container directory is created by the first sync")
+	assert.Equal(t, []string{"/app/main.py"}, c.copied)
+
+	require.NoError(t, s.Sync(ctx))
+	assert.Len(t, c.commands, 1, "nothing is executed without changes")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.py"), []byte("print(2)"), 0o644))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "main.py"), time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, s.Sync(ctx))
+	assert.Len(t, c.commands, 1, "no directory is created for a modified file")
+	assert.Equal(t, []string{"/app/main.py", "/app/main.py"}, c.copied)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conf"), 0o755))
+	require.NoError(t, s.Sync(ctx))
+	assert.Equal(t, []string{"mkdir", "-p", "/app/conf"}, c.commands[len(c.commands)-1])
+}
+
+func TestSyncDir(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "conf"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "app.yml"), []byte("port: 80"), 0o644))
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	s, err := SyncDir(ctx, c, dir, "/srv/app", SyncOptions{Interval: 100 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(s.Stop)
+
+	cat := func(file string) string {
+		result, err := c.ExecWithResult(ctx, []string{"cat", file})
+		require.NoError(t, err)
+		return string(result.Stdout)
+	}
+
+	assert.Equal(t, "port: 80", cat("/srv/app/conf/app.yml"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "app.yml"), []byte("port: 8080"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "extra.yml"), []byte("debug: true"), 0o644))
+
+	assert.Eventually(t, func() bool {
+		return cat("/srv/app/conf/app.yml") == "port: 8080" && cat("/srv/app/conf/extra.yml") == "debug: true"
+	}, 10*time.Second, 100*time.Millisecond, "the changes are synced in the background")
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "conf")))
+	require.NoError(t, s.Sync(ctx))
+
+	result, err := c.ExecWithResult(ctx, []string{"ls", "/srv/app"})
+	require.NoError(t, err)
+	assert.Empty(t, string(result.Stdout), "the removed files are deleted")
+}
@@ -0,0 +1,150 @@
+package testcontainers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/blkiodev"
+)
+
+const (
+	// diskFillFile is the name of the file FillDisk writes to fill a file system
+	diskFillFile = ".testcontainers-disk-fill"
+	// diskFillBlockSize is the size of the blocks written by dd, small enough to reach the target utilization closely
+	diskFillBlockSize = 64 * 1024
+)
+
+// BlkioLimit throttles the IO of the container on a block device of the Docker host, e.g. /dev/sda.
+// Zero limits are not applied.
+type BlkioLimit struct {
+	Device    string
+	ReadBps   uint64 // bytes read per second
+	WriteBps  uint64 // bytes written per second
+	ReadIOps  uint64 // read operations per second
+	WriteIOps uint64 // write operations per second
+}
+
+// WithBlkioLimits throttles the IO of the container on the given devices, to test how it behaves with a slow disk.
+// The limits only apply to direct IO on Linux hosts with the cgroup IO controller, buffered writes are
+// throttled as they are flushed to the device.
+func WithBlkioLimits(limits ...BlkioLimit) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		for _, l := range limits {
+			req.Resources.BlkioDeviceReadBps = appendThrottleDevice(req.Resources.BlkioDeviceReadBps, l.Device, l.ReadBps)
+			req.Resources.BlkioDeviceWriteBps = appendThrottleDevice(req.Resources.BlkioDeviceWriteBps, l.Device, l.WriteBps)
+			req.Resources.BlkioDeviceReadIOps = appendThrottleDevice(req.Resources.BlkioDeviceReadIOps, l.Device, l.ReadIOps)
+			req.Resources.BlkioDeviceWriteIOps = appendThrottleDevice(req.Resources.BlkioDeviceWriteIOps, l.Device, l.WriteIOps)
+		}
+	}
+}
+
+func appendThrottleDevice(devices []*blkiodev.ThrottleDevice, device string, rate uint64) []*blkiodev.ThrottleDevice {
+	if rate == 0 {
+		return devices
+	}
+	return append(devices, &blkiodev.ThrottleDevice{Path: device, Rate: rate})
+}
+
+// DiskUsage is the usage of the file system of a path in a container, in bytes
+type DiskUsage struct {
+	Total     int64
+	Used      int64
+	Available int64
+}
+
+// Percent returns the used space in percent of the total space
+func (u DiskUsage) Percent() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Used) * 100 / float64(u.Total)
+}
+
+// GetDiskUsage returns the usage of the file system the path of the container belongs to, with the df of the image
+func GetDiskUsage(ctx context.Context, c Container, dir string) (*DiskUsage, error) {
+	result, err := c.ExecWithResult(ctx, []string{"df", "-Pk", dir})
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("df exited with code %d: %s", result.ExitCode, result.Combined())
+	}
+
+	return parseDiskUsage(string(result.Stdout))
+}
+
+// FillDisk writes a file to the directory of the container until the file system it belongs to is used at the given
+// percentage, e.g. 100 to test how it behaves with a full disk. The returned function removes the file.
+// The writable layer of a container is usually on the disk of the Docker host: fill a volume or a tmpfs mount with
+// a size instead, e.g. Tmpfs: map[string]string{"/data": "size=64m"}.
+func FillDisk(ctx context.Context, c Container, dir string, percent float64) (func(context.Context) error, error) {
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("invalid disk utilization %.2f%%, expected a percentage between 0 and 100", percent)
+	}
+
+	usage, err := GetDiskUsage(ctx, c, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	file := path.Join(dir, diskFillFile)
+	release := func(ctx context.Context) error {
+		return execCommand(ctx, c, "rm", "-f", file)
+	}
+
+	missing := int64(float64(usage.Total)*percent/100) - usage.Used
+	if missing <= 0 {
+		return release, nil
+	}
+
+	// dd fails with no space left on the device when asked to fill the whole file system, which is the goal
+	count := (missing + diskFillBlockSize - 1) / diskFillBlockSize
+	err = execCommand(ctx, c, "dd", "if=/dev/zero", "of="+file, "bs="+strconv.Itoa(diskFillBlockSize), "count="+strconv.FormatInt(count, 10))
+	if err != nil && percent < 100 {
+		_ = release(ctx)
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// parseDiskUsage parses the POSIX output of df -Pk, in blocks of 1024 bytes
+func parseDiskUsage(output string) (*DiskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected df output: %s", output)
+	}
+
+	// the name of the file system may contain spaces, the columns are counted from the end
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("unexpected df output: %s", output)
+	}
+	fields = fields[len(fields)-5:]
+
+	var values [3]int64
+	for i := range values {
+		v, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unexpected df output: %s", err, output)
+		}
+		values[i] = v * 1024
+	}
+
+	return &DiskUsage{Total: values[0], Used: values[1], Available: values[2]}, nil
+}
+
+func execCommand(ctx context.Context, c Container, cmd ...string) error {
+	result, err := c.ExecWithResult(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s exited with code %d: %s", cmd[0], result.ExitCode, bytes.TrimSpace(result.Combined()))
+	}
+	return nil
+}
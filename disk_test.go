@@ -0,0 +1,75 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/blkiodev"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBlkioLimits(t *testing.T) {
+	req := GenericContainerRequest{}
+
+	WithBlkioLimits(
+		BlkioLimit{Device: "/dev/sda", ReadBps: 1024 * 1024, WriteIOps: 10},
+		BlkioLimit{Device: "/dev/sdb", WriteBps: 512 * 1024},
+	)(&req)
+
+	assert.Equal(t, []*blkiodev.ThrottleDevice{{Path: "/dev/sda", Rate: 1024 * 1024}}, req.Resources.BlkioDeviceReadBps)
+	assert.Equal(t, []*blkiodev.ThrottleDevice{{Path: "/dev/sdb", Rate: 512 * 1024}}, req.Resources.BlkioDeviceWriteBps)
+	assert.Empty(t, req.Resources.BlkioDeviceReadIOps)
+	assert.Equal(t, []*blkiodev.ThrottleDevice{{Path: "/dev/sda", Rate: 10}}, req.Resources.BlkioDeviceWriteIOps)
+}
+
+func TestParseDiskUsage(t *testing.T) {
+	usage, err := parseDiskUsage(`Filesystem           1024-blocks    Used Available Capacity Mounted on
+tmpfs                    10240      2560      7680  25% /data
+`)
+	require.NoError(t, err)
+	assert.Equal(t, DiskUsage{Total: 10240 * 1024, Used: 2560 * 1024, Available: 7680 * 1024}, *usage)
+	assert.Equal(t, 25.0, usage.Percent())
+
+	_, err = parseDiskUsage("df: /missing: No such file or directory")
+	assert.Error(t, err)
+}
+
+func TestFillDisk(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+			Tmpfs: map[string]string{"/data": "size=16m"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	release, err := FillDisk(ctx, c, "/data", 90)
+	require.NoError(t, err)
+
+	usage, err := GetDiskUsage(ctx, c, "/data")
+	require.NoError(t, err)
+	assert.InDelta(t, 90, usage.Percent(), 1)
+
+	require.NoError(t, release(ctx))
+
+	usage, err = GetDiskUsage(ctx, c, "/data")
+	require.NoError(t, err)
+	assert.Less(t, usage.Percent(), 1.0)
+
+	// filling the whole file system leaves no space left
+	_, err = FillDisk(ctx, c, "/data", 100)
+	require.NoError(t, err)
+
+	usage, err = GetDiskUsage(ctx, c, "/data")
+	require.NoError(t, err)
+	assert.Zero(t, usage.Available)
+}
@@ -0,0 +1,378 @@
+package testcontainers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	dnsRegistryImage     = "docker.io/coredns/coredns:1.10.1"
+	dnsRegistryPort      = "53/udp"
+	dnsRegistryHostsFile = "/hosts"
+	dnsRegistryCorefile  = "/Corefile"
+	dnsRegistryTimeout   = 10 * time.Second
+
+	// the records are read again every half second, the names of other domains are resolved by the resolver of Docker
+	dnsRegistryConfig = `.:53 {
+    hosts ` + dnsRegistryHostsFile + ` {
+        reload 500ms
+        fallthrough
+    }
+    forward . /etc/resolv.conf
+    errors
+}
+`
+)
+
+var (
+	sessionDNSRegistry     *DNSRegistry
+	sessionDNSRegistryLock sync.Mutex
+)
+
+// DNSRegistry is a CoreDNS container resolving the names of the containers registered with it, e.g. their names and
+// network aliases, so that the tests and the containers address each other by name regardless of the networks they
+// are attached to. The names of other domains are forwarded to the resolver of Docker.
+type DNSRegistry struct {
+	*DockerContainer
+
+	records    map[string][]string // addresses by name
+	containers map[string][]string // names registered by RegisterContainer, by container ID
+	lock       sync.Mutex
+}
+
+// StartDNSRegistry starts a DNS registry without records, removed by Terminate or by the reaper at the end of the
+// test session. Prefer SessionDNSRegistry to share one registry across the tests.
+func StartDNSRegistry(ctx context.Context) (*DNSRegistry, error) {
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Image:        dnsRegistryImage,
+			Cmd:          []string{"-conf", dnsRegistryCorefile},
+			ExposedPorts: []string{dnsRegistryPort},
+			WaitingFor:   wait.ForLog("CoreDNS-"),
+			LifecycleHooks: []ContainerLifecycleHooks{{
+				PostCreates: []ContainerHook{
+					func(ctx context.Context, c Container) error {
+						if err := c.CopyToContainer(ctx, []byte(dnsRegistryConfig), dnsRegistryCorefile, 0o644); err != nil {
+							return err
+						}
+						return c.CopyToContainer(ctx, nil, dnsRegistryHostsFile, 0o644)
+					},
+				},
+			}},
+		},
+		Started: true,
+	}
+
+	c, err := GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, ok := c.(*DockerContainer)
+	if !ok {
+		_ = c.Terminate(ctx)
+		return nil, errors.New("the DNS registry requires a container created by the Docker provider")
+	}
+
+	return &DNSRegistry{
+		DockerContainer: dc,
+		records:         map[string][]string{},
+		containers:      map[string][]string{},
+	}, nil
+}
+
+// SessionDNSRegistry returns the DNS registry of the test session, starting it on the first call.
+// It is removed by the reaper at the end of the session.
+func SessionDNSRegistry(ctx context.Context) (*DNSRegistry, error) {
+	sessionDNSRegistryLock.Lock()
+	defer sessionDNSRegistryLock.Unlock()
+
+	if sessionDNSRegistry != nil {
+		return sessionDNSRegistry, nil
+	}
+
+	r, err := StartDNSRegistry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessionDNSRegistry = r
+
+	return r, nil
+}
+
+// WithDNSRegistry registers the container with the registry once it is ready, under its name, its network aliases
+// and the given names, and unregisters it before it is terminated. The container resolves names with the registry,
+// which joins the first network of the request before the container is created, to be reachable from the container.
+// The container is not created if the registry fails to join it.
+func WithDNSRegistry(r *DNSRegistry, names ...string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		req.LifecycleHooks = append(req.LifecycleHooks, ContainerLifecycleHooks{
+			PreCreates: []ContainerRequestHook{
+				func(ctx context.Context, req *ContainerRequest) error {
+					address, err := r.addressFor(ctx, req.Networks)
+					if err != nil {
+						return fmt.Errorf("%w: failed to resolve names with the DNS registry", err)
+					}
+					req.DNS = append(req.DNS, address)
+					return nil
+				},
+			},
+			PostReadies: []ContainerHook{
+				func(ctx context.Context, c Container) error {
+					return r.RegisterContainer(ctx, c, names...)
+				},
+			},
+			PreTerminates: []ContainerHook{
+				r.UnregisterContainer,
+			},
+		})
+	}
+}
+
+// Register adds records resolving the name to the addresses, replacing the previous ones of the name, and waits for
+// the registry to serve them
+func (r *DNSRegistry) Register(ctx context.Context, name string, addresses ...string) error {
+	if name == "" || len(addresses) == 0 {
+		return errors.New("a record requires a name and an address")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.records[dnsName(name)] = addresses
+	if err := r.writeRecords(ctx); err != nil {
+		return err
+	}
+
+	return r.awaitRecord(ctx, name, addresses)
+}
+
+// RegisterContainer registers the addresses of the container in its networks under its name, its network aliases
+// and the given names
+func (r *DNSRegistry) RegisterContainer(ctx context.Context, c Container, names ...string) error {
+	addresses, err := c.ContainerIPs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var ips []string
+	for _, ip := range addresses {
+		if ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return fmt.Errorf("container %s has no address", c.GetContainerID())
+	}
+
+	name, err := c.Name(ctx)
+	if err != nil {
+		return err
+	}
+	all := append([]string{strings.TrimPrefix(name, "/")}, names...)
+
+	aliases, err := c.NetworkAliases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, networkAliases := range aliases {
+		for _, alias := range networkAliases {
+			// Docker aliases the containers with their short ID in user defined networks
+			if !strings.HasPrefix(c.GetContainerID(), alias) {
+				all = append(all, alias)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, n := range all {
+		if n == "" || seen[dnsName(n)] {
+			continue
+		}
+		seen[dnsName(n)] = true
+
+		if err := r.Register(ctx, n, ips...); err != nil {
+			return err
+		}
+
+		r.lock.Lock()
+		r.containers[c.GetContainerID()] = append(r.containers[c.GetContainerID()], n)
+		r.lock.Unlock()
+	}
+
+	return nil
+}
+
+// UnregisterContainer removes the records registered for the container by RegisterContainer
+func (r *DNSRegistry) UnregisterContainer(ctx context.Context, c Container) error {
+	r.lock.Lock()
+	names := r.containers[c.GetContainerID()]
+	delete(r.containers, c.GetContainerID())
+	r.lock.Unlock()
+
+	return r.Unregister(ctx, names...)
+}
+
+// Unregister removes the records of the names
+func (r *DNSRegistry) Unregister(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, name := range names {
+		delete(r.records, dnsName(name))
+	}
+
+	return r.writeRecords(ctx)
+}
+
+// Resolver returns a resolver querying the registry from the host, e.g. for the tests to address containers by name
+func (r *DNSRegistry) Resolver(ctx context.Context) (*net.Resolver, error) {
+	host, err := r.Host(ctx)
+	if err != nil {
+		return nil, err
+	}
+	port, err := r.MappedPort(ctx, dnsRegistryPort)
+	if err != nil {
+		return nil, err
+	}
+
+	address := net.JoinHostPort(host, port.Port())
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", address)
+		},
+	}, nil
+}
+
+// addressFor returns the address of the registry in the first of the networks, joining it if needed, or in the
+// default network of Docker if there is none
+func (r *DNSRegistry) addressFor(ctx context.Context, networks []string) (string, error) {
+	inspect, err := r.inspectContainer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(networks) == 0 {
+		return inspect.NetworkSettings.IPAddress, nil
+	}
+
+	if endpoint, ok := inspect.NetworkSettings.Networks[networks[0]]; ok {
+		return endpoint.IPAddress, nil
+	}
+
+	if err := r.provider.client.NetworkConnect(ctx, networks[0], r.ID, &network.EndpointSettings{}); err != nil {
+		return "", fmt.Errorf("%w: failed to join network %s", err, networks[0])
+	}
+
+	inspect, err = r.inspectContainer(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, ok := inspect.NetworkSettings.Networks[networks[0]]
+	if !ok {
+		return "", fmt.Errorf("the DNS registry did not join network %s", networks[0])
+	}
+
+	return endpoint.IPAddress, nil
+}
+
+// writeRecords copies the hosts file of the records to the registry. The file is written with the current time,
+// so that the registry reloads it even if its size did not change.
+func (r *DNSRegistry) writeRecords(ctx context.Context) error {
+	content := hostsFile(r.records)
+
+	buffer := &bytes.Buffer{}
+	tw := tar.NewWriter(buffer)
+	hdr := &tar.Header{
+		Name:    path.Base(dnsRegistryHostsFile),
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+		// keeps the sub-second precision of the modification time
+		Format: tar.FormatPAX,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return r.provider.client.CopyToContainer(ctx, r.ID, path.Dir(dnsRegistryHostsFile), buffer, types.CopyToContainerOptions{})
+}
+
+// awaitRecord waits for the registry to resolve the name to the addresses
+func (r *DNSRegistry) awaitRecord(ctx context.Context, name string, addresses []string) error {
+	resolver, err := r.Resolver(ctx)
+	if err != nil {
+		return err
+	}
+
+	expected := append([]string(nil), addresses...)
+	sort.Strings(expected)
+
+	ctx, cancel := context.WithTimeout(ctx, dnsRegistryTimeout)
+	defer cancel()
+
+	for {
+		resolved, err := resolver.LookupHost(ctx, dnsName(name))
+		if err == nil {
+			sort.Strings(resolved)
+			if strings.Join(resolved, ",") == strings.Join(expected, ",") {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: the DNS registry does not resolve %s to %s", ctx.Err(), name, strings.Join(addresses, ", "))
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// hostsFile returns the records in the hosts file format, sorted by name
+func hostsFile(records map[string][]string) []byte {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		for _, address := range records[name] {
+			fmt.Fprintf(&b, "%s %s\n", address, strings.TrimSuffix(name, "."))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// dnsName returns the fully qualified form of the name, in lower case
+func dnsName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
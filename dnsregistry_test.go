@@ -0,0 +1,106 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestHostsFile(t *testing.T) {
+	records := map[string][]string{
+		dnsName("Web"):         {"172.17.0.3"},
+		dnsName("db.internal"): {"172.17.0.2", "172.20.0.2"},
+	}
+
+	assert.Equal(t, "172.17.0.2 db.internal\n172.20.0.2 db.internal\n172.17.0.3 web\n", string(hostsFile(records)))
+}
+
+func TestDNSRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	registry, err := StartDNSRegistry(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, registry.Terminate(ctx))
+	})
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:        "docker.io/nginx:stable-alpine",
+			ExposedPorts: []string{"80/tcp"},
+			WaitingFor:   wait.ForHTTP("/"),
+		},
+		Started: true,
+	}
+	WithDNSRegistry(registry, "web.internal")(&req)
+
+	// terminated by the test, or by the reaper if it fails
+	web, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+
+	webIP, err := web.ContainerIP(ctx)
+	require.NoError(t, err)
+
+	resolver, err := registry.Resolver(ctx)
+	require.NoError(t, err)
+	addresses, err := resolver.LookupHost(ctx, "web.internal.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{webIP}, addresses, "the host resolves the containers with the registry")
+
+	req = GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+		Started: true,
+	}
+	WithDNSRegistry(registry)(&req)
+
+	client, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Terminate(ctx))
+	})
+
+	result, err := client.ExecWithResult(ctx, []string{"wget", "-q", "-O", "/dev/null", "http://web.internal"})
+	require.NoError(t, err)
+	assert.Zero(t, result.ExitCode, "the containers resolve each other with the registry: %s", result.Combined())
+
+	require.NoError(t, web.Terminate(ctx))
+	assert.Eventually(t, func() bool {
+		_, err := resolver.LookupHost(ctx, "web.internal.")
+		return err != nil
+	}, 5*time.Second, 100*time.Millisecond, "the terminated containers are unregistered")
+}
+
+func TestDNSRegistryMissingNetwork(t *testing.T) {
+	ctx := context.Background()
+
+	registry, err := StartDNSRegistry(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, registry.Terminate(ctx))
+	})
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:    "docker.io/alpine",
+			Cmd:      []string{"sleep", "60"},
+			Networks: []string{"missing-" + uuid.NewString()},
+		},
+		Started: true,
+	}
+	WithDNSRegistry(registry)(&req)
+
+	_, err = GenericContainer(ctx, req)
+	assert.ErrorContains(t, err, "failed to resolve names with the DNS registry")
+}
@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -25,15 +27,18 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/google/uuid"
 	"github.com/magiconair/properties"
 	"github.com/moby/term"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -43,6 +48,14 @@ var (
 
 	logOnce                 sync.Once
 	ErrDuplicateMountTarget = errors.New("duplicate mount target detected")
+	ErrInvalidSELinuxLabel  = errors.New("invalid SELinux label, must be z or Z")
+	ErrInvalidUser          = errors.New("invalid container user")
+	ErrEmptyLabelSelector   = errors.New("at least one label is required to find containers")
+)
+
+const (
+	// defaultStopTimeout is the time the daemon gives a container to exit before killing it
+	defaultStopTimeout = 10 * time.Second
 )
 
 const (
@@ -69,6 +82,9 @@ type DockerContainer struct {
 	raw               *types.ContainerJSON
 	stopProducer      chan bool
 	logger            Logging
+	recordStartupLogs bool
+	startupLogs       []string
+	lifecycleHooks    []ContainerLifecycleHooks
 }
 
 func (c *DockerContainer) GetContainerID() string {
@@ -129,8 +145,50 @@ func (c *DockerContainer) Host(ctx context.Context) (string, error) {
 	return host, nil
 }
 
-// MappedPort gets externally mapped port for a container port
+// defaultPortMappingTimeout is the default time MappedPort waits for the binding of a port to be reported
+const defaultPortMappingTimeout = 5 * time.Second
+
+// errPortNotBound is returned when the port of a running container is not bound yet, the daemon may report its binding
+// a moment later
+var errPortNotBound = errors.New("port not found")
+
+// MappedPort gets externally mapped port for a container port. Right after the container started, the daemon may
+// briefly report no host binding for its ports: as long as the container is running and exposes the port, the lookup
+// is retried until the port mapping timeout of the configuration elapses.
 func (c *DockerContainer) MappedPort(ctx context.Context, port nat.Port) (nat.Port, error) {
+	mapped, err := c.mappedPort(ctx, port)
+	if !errors.Is(err, errPortNotBound) {
+		return mapped, err
+	}
+
+	timeout := configureTC().PortMappingTimeout
+	if timeout == 0 {
+		timeout = defaultPortMappingTimeout
+	}
+	if timeout < 0 {
+		return "", err
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 50 * time.Millisecond
+	b.MaxElapsedTime = timeout
+
+	err = backoff.Retry(func() error {
+		mapped, err = c.mappedPort(ctx, port)
+		if err != nil && !errors.Is(err, errPortNotBound) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(b, ctx))
+	if err != nil {
+		return "", err
+	}
+	return mapped, nil
+}
+
+// mappedPort looks up the host port the container port is bound to, it returns errPortNotBound if the binding may
+// still be reported
+func (c *DockerContainer) mappedPort(ctx context.Context, port nat.Port) (nat.Port, error) {
 	inspect, err := c.inspectContainer(ctx)
 	if err != nil {
 		return "", err
@@ -138,11 +196,18 @@ func (c *DockerContainer) MappedPort(ctx context.Context, port nat.Port) (nat.Po
 	if inspect.ContainerJSONBase.HostConfig.NetworkMode == "host" {
 		return port, nil
 	}
-	ports, err := c.Ports(ctx)
-	if err != nil {
-		return "", err
+
+	if mapped, found := hostPort(inspect.NetworkSettings.Ports, port); found {
+		return mapped, nil
 	}
+	if inspect.State != nil && inspect.State.Running && exposesPort(inspect, port) {
+		return "", errPortNotBound
+	}
+	return "", errors.New("port not found")
+}
 
+// hostPort returns the first host port the container port is bound to
+func hostPort(ports nat.PortMap, port nat.Port) (nat.Port, bool) {
 	for k, p := range ports {
 		if k.Port() != port.Port() {
 			continue
@@ -153,10 +218,33 @@ func (c *DockerContainer) MappedPort(ctx context.Context, port nat.Port) (nat.Po
 		if len(p) == 0 {
 			continue
 		}
-		return nat.NewPort(k.Proto(), p[0].HostPort)
+		mapped, err := nat.NewPort(k.Proto(), p[0].HostPort)
+		return mapped, err == nil
 	}
 
-	return "", errors.New("port not found")
+	return "", false
+}
+
+// exposesPort reports whether the port is exposed or published by the container, i.e. whether it will be bound
+func exposesPort(inspect *types.ContainerJSON, port nat.Port) bool {
+	var candidates []nat.Port
+	if inspect.Config != nil {
+		for k := range inspect.Config.ExposedPorts {
+			candidates = append(candidates, k)
+		}
+	}
+	if inspect.HostConfig != nil {
+		for k := range inspect.HostConfig.PortBindings {
+			candidates = append(candidates, k)
+		}
+	}
+
+	for _, k := range candidates {
+		if k.Port() == port.Port() && (port.Proto() == "" || k.Proto() == port.Proto()) {
+			return true
+		}
+	}
+	return false
 }
 
 // Ports gets the exposed ports for the container.
@@ -179,19 +267,95 @@ func (c *DockerContainer) Start(ctx context.Context) error {
 	c.logger.Printf("Starting container id: %s image: %s", shortID, c.Image)
 
 	if err := c.provider.client.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
-		return err
+		if isPortBindingError(err) {
+			return classifyError(err, ErrPortBinding, c.ID, c.Image)
+		}
+		return classifyError(err, nil, c.ID, c.Image)
 	}
 
 	// if a Wait Strategy has been specified, wait before returning
 	if c.WaitingFor != nil {
 		c.logger.Printf("Waiting for container id %s image: %s", shortID, c.Image)
 		if err := c.WaitingFor.WaitUntilReady(ctx, c); err != nil {
-			return err
+			if c.recordStartupLogs {
+				// the logs are most useful to diagnose why the container did not get ready
+				_ = c.recordLogsSinceStart(ctx)
+			}
+			if errors.Is(err, context.Canceled) {
+				return c.withExitInfo(err)
+			}
+			return classifyError(c.withExitInfo(err), ErrWaitTimeout, c.ID, c.Image)
+		}
+	}
+
+	if c.recordStartupLogs {
+		if err := c.recordLogsSinceStart(ctx); err != nil {
+			return fmt.Errorf("%w: failed to record startup logs", err)
 		}
 	}
+
 	c.logger.Printf("Container is ready id: %s image: %s", shortID, c.Image)
 	c.isRunning = true
-	return nil
+
+	return c.runPostReadyHooks(ctx)
+}
+
+// StartupLogs returns the log lines, stdout and stderr interleaved, written by the container
+// between its last start and its readiness. They are only recorded if the request enabled RecordStartupLogs.
+func (c *DockerContainer) StartupLogs() []string {
+	return c.startupLogs
+}
+
+// recordLogsSinceStart keeps the log lines written by the container since it was last started
+func (c *DockerContainer) recordLogsSinceStart(ctx context.Context) error {
+	inspect, err := c.inspectContainer(ctx)
+	if err != nil {
+		return err
+	}
+
+	// rely on the clock of the daemon, which might not be in sync with the one of the host
+	rc, err := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      inspect.State.StartedAt,
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var logs bytes.Buffer
+	if _, err := stdcopy.StdCopy(&logs, &logs, rc); err != nil {
+		return err
+	}
+
+	c.startupLogs = nil
+	scanner := bufio.NewScanner(&logs)
+	for scanner.Scan() {
+		c.startupLogs = append(c.startupLogs, scanner.Text())
+	}
+
+	return scanner.Err()
+}
+
+// tailLogs returns the last log lines written by the container, stdout and stderr interleaved
+func (c *DockerContainer) tailLogs(ctx context.Context, lines int) (string, error) {
+	rc, err := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       strconv.Itoa(lines),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var logs bytes.Buffer
+	if _, err := stdcopy.StdCopy(&logs, &logs, rc); err != nil {
+		return "", err
+	}
+
+	return logs.String(), nil
 }
 
 // Stop will stop an already started container
@@ -204,6 +368,14 @@ func (c *DockerContainer) Start(ctx context.Context) error {
 // otherwise the engine default. A negative timeout value can be specified,
 // meaning no timeout, i.e. no forceful termination is performed.
 func (c *DockerContainer) Stop(ctx context.Context, timeout *time.Duration) error {
+	return c.StopWithSignal(ctx, "", timeout)
+}
+
+// StopWithSignal stops the container sending it the given signal, e.g. "SIGQUIT", instead of
+// the stop signal of the image, which is used when signal is empty.
+// The container is killed with SIGKILL if it has not exited once the timeout expires,
+// a nil timeout uses the default timeout of the daemon.
+func (c *DockerContainer) StopWithSignal(ctx context.Context, signal string, timeout *time.Duration) error {
 	shortID := c.ID[:12]
 	c.logger.Printf("Stopping container id: %s image: %s", shortID, c.Image)
 
@@ -214,7 +386,15 @@ func (c *DockerContainer) Stop(ctx context.Context, timeout *time.Duration) erro
 		options.Timeout = &timeoutSeconds
 	}
 
-	if err := c.provider.client.ContainerStop(ctx, c.ID, options); err != nil {
+	var err error
+	if signal == "" || versions.GreaterThanOrEqualTo(c.provider.client.ClientVersion(), "1.42") {
+		options.Signal = signal
+		err = c.provider.client.ContainerStop(ctx, c.ID, options)
+	} else {
+		// daemons older than API 1.42 ignore the signal of the stop request
+		err = c.stopWithKill(ctx, signal, timeout)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -223,8 +403,45 @@ func (c *DockerContainer) Stop(ctx context.Context, timeout *time.Duration) erro
 	return nil
 }
 
+// stopWithKill emulates a stop request with a custom signal: the signal is sent to the container,
+// which is killed if it has not exited once the timeout expires
+func (c *DockerContainer) stopWithKill(ctx context.Context, signal string, timeout *time.Duration) error {
+	if err := c.provider.client.ContainerKill(ctx, c.ID, signal); err != nil {
+		return err
+	}
+
+	var waitCtx context.Context
+	var cancel context.CancelFunc
+	if timeout != nil && *timeout < 0 {
+		// no forceful termination, the container is waited for until it exits
+		waitCtx, cancel = context.WithCancel(ctx)
+	} else {
+		gracePeriod := defaultStopTimeout
+		if timeout != nil {
+			gracePeriod = *timeout
+		}
+		waitCtx, cancel = context.WithTimeout(ctx, gracePeriod)
+	}
+	defer cancel()
+
+	statusCh, errCh := c.provider.client.ContainerWait(waitCtx, c.ID, container.WaitConditionNotRunning)
+	select {
+	case <-statusCh:
+		return nil
+	case err := <-errCh:
+		if waitCtx.Err() == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return c.provider.client.ContainerKill(ctx, c.ID, "SIGKILL")
+}
+
 // Terminate is used to kill the container. It is usually triggered by as defer function.
 func (c *DockerContainer) Terminate(ctx context.Context) error {
+	// the container is removed even if a hook failed, the error is reported afterwards
+	hookErr := c.runPreTerminateHooks(ctx)
+
 	select {
 	// close reaper if it was created
 	case c.terminationSignal <- true:
@@ -235,7 +452,7 @@ func (c *DockerContainer) Terminate(ctx context.Context) error {
 		Force:         true,
 	})
 	if err != nil {
-		return err
+		return c.withExitInfo(err)
 	}
 
 	if c.imageWasBuilt {
@@ -248,13 +465,9 @@ func (c *DockerContainer) Terminate(ctx context.Context) error {
 		}
 	}
 
-	if err := c.provider.client.Close(); err != nil {
-		return err
-	}
-
 	c.sessionID = uuid.UUID{}
 	c.isRunning = false
-	return nil
+	return hookErr
 }
 
 // update container raw info
@@ -364,6 +577,64 @@ func (c *DockerContainer) State(ctx context.Context) (*types.ContainerState, err
 	return inspect.State, nil
 }
 
+// ExitInfo returns whether the container exited, with its exit code and cause, without a manual inspect
+func (c *DockerContainer) ExitInfo(ctx context.Context) (*ExitInfo, error) {
+	inspect, err := c.inspectRawContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := inspect.State
+	info := &ExitInfo{
+		Running:   state.Running,
+		ExitCode:  state.ExitCode,
+		OOMKilled: state.OOMKilled,
+		Error:     state.Error,
+	}
+	if finishedAt, err := time.Parse(time.RFC3339Nano, state.FinishedAt); err == nil && finishedAt.After(time.Time{}) {
+		info.FinishedAt = finishedAt
+	}
+
+	return info, nil
+}
+
+// ContainerEnv returns the environment variables of the container, e.g. to read the configuration of a container
+// started by compose. The container must be a Docker container.
+func ContainerEnv(ctx context.Context, c Container) (map[string]string, error) {
+	dc, ok := c.(*DockerContainer)
+	if !ok {
+		return nil, fmt.Errorf("container %s is not a Docker container", c.GetContainerID())
+	}
+
+	inspect, err := dc.inspectContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(inspect.Config.Env))
+	for _, kv := range inspect.Config.Env {
+		name, value, _ := strings.Cut(kv, "=")
+		env[name] = value
+	}
+
+	return env, nil
+}
+
+// withExitInfo adds the exit cause to err if the container is no longer running,
+// which usually explains why it could not get ready
+func (c *DockerContainer) withExitInfo(err error) error {
+	// not using the context of the caller, which might be the one that just expired
+	inspectCtx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+	defer cancel()
+
+	info, inspectErr := c.ExitInfo(inspectCtx)
+	if inspectErr != nil || info.Running {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", err, info)
+}
+
 // Networks gets the names of the networks the container is attached to.
 func (c *DockerContainer) Networks(ctx context.Context) ([]string, error) {
 	inspect, err := c.inspectContainer(ctx)
@@ -438,14 +709,16 @@ func (c *DockerContainer) NetworkAliases(ctx context.Context) (map[string][]stri
 	return a, nil
 }
 
-func (c *DockerContainer) Exec(ctx context.Context, cmd []string) (int, io.Reader, error) {
+// Exec executes a command in the container, returning its exit code and the raw output stream.
+// Unless the Multiplexed option is used, the reader contains the stdout and stderr stream headers.
+func (c *DockerContainer) Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error) {
+	processOptions := tcexec.NewProcessOptions(cmd)
+	for _, o := range options {
+		o.Apply(processOptions)
+	}
+
 	cli := c.provider.client
-	response, err := cli.ContainerExecCreate(ctx, c.ID, types.ExecConfig{
-		Cmd:          cmd,
-		Detach:       false,
-		AttachStdout: true,
-		AttachStderr: true,
-	})
+	response, err := cli.ContainerExecCreate(ctx, c.ID, processOptions.ExecConfig)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -455,22 +728,83 @@ func (c *DockerContainer) Exec(ctx context.Context, cmd []string) (int, io.Reade
 		return 0, nil, err
 	}
 
-	var exitCode int
-	for {
-		execResp, err := cli.ContainerExecInspect(ctx, response.ID)
+	if !processOptions.Multiplexed {
+		exitCode, err := c.waitForExec(ctx, response.ID)
 		if err != nil {
 			return 0, nil, err
 		}
+		return exitCode, hijack.Reader, nil
+	}
+
+	defer hijack.Close()
+
+	// drain the output while the command runs, so a verbose command is not blocked on a full pipe
+	var combined bytes.Buffer
+	if _, err := stdcopy.StdCopy(&combined, &combined, hijack.Reader); err != nil {
+		return 0, nil, err
+	}
+
+	exitCode, err := c.waitForExec(ctx, response.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return exitCode, &combined, nil
+}
+
+// ExecWithResult executes a command in the container, waits for it to complete
+// and returns its exit code along with the demultiplexed output.
+func (c *DockerContainer) ExecWithResult(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (*ExecResult, error) {
+	processOptions := tcexec.NewProcessOptions(cmd)
+	for _, o := range options {
+		o.Apply(processOptions)
+	}
+
+	cli := c.provider.client
+	response, err := cli.ContainerExecCreate(ctx, c.ID, processOptions.ExecConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	hijack, err := cli.ContainerExecAttach(ctx, response.ID, types.ExecStartCheck{})
+	if err != nil {
+		return nil, err
+	}
+	defer hijack.Close()
+
+	// drain the output while the command runs, so a verbose command is not blocked on a full pipe
+	var stdout, stderr, combined bytes.Buffer
+	if _, err := stdcopy.StdCopy(io.MultiWriter(&stdout, &combined), io.MultiWriter(&stderr, &combined), hijack.Reader); err != nil {
+		return nil, err
+	}
+
+	exitCode, err := c.waitForExec(ctx, response.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecResult{
+		ExitCode: exitCode,
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		combined: combined.Bytes(),
+	}, nil
+}
+
+// waitForExec polls the exec instance until its process is no longer running and returns its exit code
+func (c *DockerContainer) waitForExec(ctx context.Context, execID string) (int, error) {
+	for {
+		execResp, err := c.provider.client.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, err
+		}
 
 		if !execResp.Running {
-			exitCode = execResp.ExitCode
-			break
+			return execResp.ExitCode, nil
 		}
 
 		time.Sleep(100 * time.Millisecond)
 	}
-
-	return exitCode, hijack.Reader, nil
 }
 
 type FileFromContainer struct {
@@ -549,7 +883,7 @@ func (c *DockerContainer) CopyFileToContainer(ctx context.Context, hostFilePath
 	return c.CopyToContainer(ctx, fileContent, containerFilePath, fileMode)
 }
 
-// CopyToContainer copies fileContent data to a file in container
+// CopyToContainer copies fileContent data to a file in container, whose directory must exist
 func (c *DockerContainer) CopyToContainer(ctx context.Context, fileContent []byte, containerFilePath string, fileMode int64) error {
 	buffer, err := tarFile(fileContent, containerFilePath, fileMode)
 	if err != nil {
@@ -667,13 +1001,34 @@ func (n *DockerNetwork) Remove(ctx context.Context) error {
 	return n.provider.client.NetworkRemove(ctx, n.ID)
 }
 
+// Inspect returns the current state of the network, e.g. its IPAM configuration and the containers attached to it
+func (n *DockerNetwork) Inspect(ctx context.Context) (types.NetworkResource, error) {
+	return n.provider.client.NetworkInspect(ctx, n.ID, types.NetworkInspectOptions{})
+}
+
+// Connect attaches the container to the network, resolved by the other containers of the network by the given aliases
+func (n *DockerNetwork) Connect(ctx context.Context, c Container, aliases ...string) error {
+	return n.provider.client.NetworkConnect(ctx, n.ID, c.GetContainerID(), &network.EndpointSettings{Aliases: aliases})
+}
+
+// Disconnect detaches the container from the network
+func (n *DockerNetwork) Disconnect(ctx context.Context, c Container) error {
+	return n.provider.client.NetworkDisconnect(ctx, n.ID, c.GetContainerID(), false)
+}
+
 // DockerProvider implements the ContainerProvider interface
+// It is safe for concurrent use, all providers of a process share the same Docker client.
 type DockerProvider struct {
 	*DockerProviderOptions
 	client    client.APIClient
 	host      string
 	hostCache string
 	config    TestContainersConfig
+
+	// guards hostCache
+	hostLock sync.Mutex
+	// guards the lazy initialization of DefaultNetwork
+	networkLock sync.Mutex
 }
 
 var _ ContainerProvider = (*DockerProvider)(nil)
@@ -684,6 +1039,21 @@ type TestContainersConfig struct {
 	TLSVerify      int    `properties:"docker.tls.verify,default=0"`
 	CertPath       string `properties:"docker.cert.path,default="`
 	RyukPrivileged bool   `properties:"ryuk.container.privileged,default=false"`
+	// RyukDryRun keeps the resources of the session once it ends instead of starting Ryuk to remove them
+	RyukDryRun bool `properties:"ryuk.dry.run,default=false"`
+	// MaxConcurrentStarts bounds the containers started concurrently on the machine, unlimited if zero
+	MaxConcurrentStarts int `properties:"container.starts.max,default=0"`
+	// AuditFile is the file the audit trail of the session is appended to as JSON lines, none if empty
+	AuditFile string `properties:"audit.file,default="`
+	// PortMappingTimeout bounds the time MappedPort waits for the daemon to report the binding of a port of a running
+	// container, which may be missing right after the container started. defaultPortMappingTimeout if zero, the lookup
+	// is not retried if negative.
+	PortMappingTimeout time.Duration `properties:"port.mapping.timeout,default=0"`
+	// MaxContainerAge is the age after which a reused container is recreated instead of reused, no limit if zero
+	MaxContainerAge time.Duration `properties:"container.reuse.max.age,default=0"`
+	// ResourceAnnotations are stamped on all the resources created as labels, to attribute the usage of a shared
+	// Docker host, e.g. team=payments,pipeline=1234
+	ResourceAnnotations string `properties:"resource.annotations,default="`
 }
 
 type (
@@ -729,12 +1099,125 @@ func WithDefaultBridgeNetwork(bridgeNetworkName string) DockerProviderOption {
 	})
 }
 
+const (
+	// dockerMaxIdleConns is the number of idle keep-alive connections to the Docker daemon that are kept open,
+	// parallel tests would otherwise constantly open and close connections
+	dockerMaxIdleConns = 100
+	// dockerIdleConnTimeout is the time an idle keep-alive connection to the Docker daemon is kept open
+	dockerIdleConnTimeout = 90 * time.Second
+	// dockerPingTimeout limits the time to check if the Docker daemon is reachable
+	dockerPingTimeout = 10 * time.Second
+)
+
+var (
+	// sharedDockerClient is the Docker client shared by all providers of the process
+	sharedDockerClient     *client.Client
+	sharedDockerHost       string
+	sharedTCConfig         TestContainersConfig
+	sharedDockerClientLock sync.Mutex
+)
+
+// getSharedDockerClient returns the Docker client shared by all providers, creating it on first use
+func getSharedDockerClient() (*client.Client, string, TestContainersConfig, error) {
+	sharedDockerClientLock.Lock()
+	defer sharedDockerClientLock.Unlock()
+
+	if sharedDockerClient != nil {
+		return sharedDockerClient, sharedDockerHost, sharedTCConfig, nil
+	}
+
+	cli, host, tcConfig, err := NewDockerClient()
+	if err != nil {
+		return nil, "", TestContainersConfig{}, err
+	}
+
+	sharedDockerClient, sharedDockerHost, sharedTCConfig = cli, host, tcConfig
+
+	return cli, host, tcConfig, nil
+}
+
+var (
+	// defaultProvider is the provider of the package level functions, e.g. Preflight
+	defaultProvider     *DockerProvider
+	defaultProviderLock sync.Mutex
+)
+
+// defaultDockerProvider returns the provider of the package level functions, created with the default options on
+// first use, so that they do not create a provider and ping the daemon on each call. It is created again once the
+// shared client it uses was replaced.
+func defaultDockerProvider() (*DockerProvider, error) {
+	defaultProviderLock.Lock()
+	defer defaultProviderLock.Unlock()
+
+	if defaultProvider != nil && defaultProvider.usesSharedClient() {
+		return defaultProvider, nil
+	}
+
+	p, err := NewDockerProvider()
+	if err != nil {
+		return nil, err
+	}
+	defaultProvider = p
+
+	return p, nil
+}
+
+// usesSharedClient reports whether the provider uses the current shared client
+func (p *DockerProvider) usesSharedClient() bool {
+	c := p.client
+	if audited, ok := c.(*auditClient); ok {
+		c = audited.APIClient
+	}
+
+	sharedDockerClientLock.Lock()
+	defer sharedDockerClientLock.Unlock()
+
+	return sharedDockerClient != nil && c == client.APIClient(sharedDockerClient)
+}
+
+// replaceSharedDockerClient replaces the shared client which failed to reach the daemon with a new one, created like
+// the first one, so that the next providers do not fail to reach the daemon again. The client is not replaced again if
+// another provider already replaced it. The broken client is not closed, as other providers may still use it.
+func replaceSharedDockerClient(broken *client.Client) (*client.Client, string, TestContainersConfig, error) {
+	sharedDockerClientLock.Lock()
+	defer sharedDockerClientLock.Unlock()
+
+	if sharedDockerClient != broken && sharedDockerClient != nil {
+		return sharedDockerClient, sharedDockerHost, sharedTCConfig, nil
+	}
+
+	cli, host, tcConfig, err := NewDockerClient()
+	if err != nil {
+		return nil, "", TestContainersConfig{}, err
+	}
+
+	sharedDockerClient, sharedDockerHost, sharedTCConfig = cli, host, tcConfig
+
+	return cli, host, tcConfig, nil
+}
+
+// newDockerHTTPClient returns an HTTP client with a transport tuned for many concurrent requests
+// to the Docker daemon, keeping connections alive instead of opening a new one per request
+func newDockerHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:        dockerMaxIdleConns,
+			MaxIdleConnsPerHost: dockerMaxIdleConns,
+			IdleConnTimeout:     dockerIdleConnTimeout,
+		},
+		CheckRedirect: client.CheckRedirect,
+	}
+}
+
+// NewDockerClient creates a new Docker client. Prefer using a DockerProvider, which shares a single client
+// across all providers of the process.
 func NewDockerClient() (cli *client.Client, host string, tcConfig TestContainersConfig, err error) {
 	tcConfig = configureTC()
 
 	host = tcConfig.Host
 
-	opts := []client.Opt{client.FromEnv}
+	// the HTTP client must be configured first, as the host options configure its transport
+	opts := []client.Opt{client.WithHTTPClient(newDockerHTTPClient()), client.FromEnv}
 	if host != "" {
 		opts = append(opts, client.WithHost(host))
 
@@ -750,6 +1233,8 @@ func NewDockerClient() (cli *client.Client, host string, tcConfig TestContainers
 		host = dockerHostEnv
 	} else {
 		host = "unix:///var/run/docker.sock"
+		// the transport of a custom HTTP client is not configured for the default host
+		opts = append(opts, client.WithHost(client.DefaultDockerHost))
 	}
 
 	opts = append(opts, client.WithHTTPHeaders(
@@ -781,25 +1266,27 @@ func NewDockerProvider(provOpts ...DockerProviderOption) (*DockerProvider, error
 		provOpts[idx].ApplyDockerTo(o)
 	}
 
-	c, host, tcConfig, err := NewDockerClient()
+	c, host, tcConfig, err := getSharedDockerClient()
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = c.Ping(context.TODO())
+	pingCtx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+	defer cancel()
+
+	_, err = c.Ping(pingCtx)
 	if err != nil {
-		// fallback to environment
-		c, err = client.NewClientWithOpts(client.FromEnv)
+		// the daemon may be reachable with a new client, e.g. once the environment changed
+		c, host, tcConfig, err = replaceSharedDockerClient(c)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	c.NegotiateAPIVersion(context.Background())
 	p := &DockerProvider{
 		DockerProviderOptions: o,
 		host:                  host,
-		client:                c,
+		client:                withAuditTrail(c),
 		config:                tcConfig,
 	}
 
@@ -817,7 +1304,10 @@ func (p *DockerProvider) logDockerServerInfo() {
   Total Memory: %v MB
 `
 
-	info, err := p.client.Info(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+	defer cancel()
+
+	info, err := p.client.Info(ctx)
 	if err != nil {
 		p.Logger.Printf("failed getting information about docker server: %s", err)
 	}
@@ -838,6 +1328,36 @@ func configureTC() TestContainersConfig {
 			config.RyukPrivileged = ryukPrivilegedEnv == "true"
 		}
 
+		if dryRunEnv := os.Getenv("TESTCONTAINERS_RYUK_DRY_RUN"); dryRunEnv != "" {
+			config.RyukDryRun = dryRunEnv == "true"
+		}
+
+		if maxStartsEnv := os.Getenv("TESTCONTAINERS_MAX_CONCURRENT_STARTS"); maxStartsEnv != "" {
+			if maxStarts, err := strconv.Atoi(maxStartsEnv); err == nil {
+				config.MaxConcurrentStarts = maxStarts
+			}
+		}
+
+		if auditFileEnv := os.Getenv("TESTCONTAINERS_AUDIT_FILE"); auditFileEnv != "" {
+			config.AuditFile = auditFileEnv
+		}
+
+		if timeoutEnv := os.Getenv("TESTCONTAINERS_PORT_MAPPING_TIMEOUT"); timeoutEnv != "" {
+			if timeout, err := time.ParseDuration(timeoutEnv); err == nil {
+				config.PortMappingTimeout = timeout
+			}
+		}
+
+		if maxAgeEnv := os.Getenv("TESTCONTAINERS_MAX_CONTAINER_AGE"); maxAgeEnv != "" {
+			if maxAge, err := time.ParseDuration(maxAgeEnv); err == nil {
+				config.MaxContainerAge = maxAge
+			}
+		}
+
+		if annotationsEnv := os.Getenv("TESTCONTAINERS_RESOURCE_ANNOTATIONS"); annotationsEnv != "" {
+			config.ResourceAnnotations = annotationsEnv
+		}
+
 		return config
 	}
 
@@ -863,6 +1383,11 @@ func configureTC() TestContainersConfig {
 
 // BuildImage will build and image from context and Dockerfile, then return the tag
 func (p *DockerProvider) BuildImage(ctx context.Context, img ImageBuildInfo) (string, error) {
+	return p.buildImage(ctx, img, nil)
+}
+
+// buildImage builds the image with the given labels, then returns its tag
+func (p *DockerProvider) buildImage(ctx context.Context, img ImageBuildInfo, labels map[string]string) (string, error) {
 	repo := uuid.New()
 	tag := uuid.New()
 
@@ -878,6 +1403,7 @@ func (p *DockerProvider) BuildImage(ctx context.Context, img ImageBuildInfo) (st
 		Dockerfile:  img.GetDockerfile(),
 		Context:     buildContext,
 		Tags:        []string{repoTag},
+		Labels:      labels,
 		Remove:      true,
 		ForceRemove: true,
 	}
@@ -909,47 +1435,56 @@ func (p *DockerProvider) BuildImage(ctx context.Context, img ImageBuildInfo) (st
 }
 
 // CreateContainer fulfills a request for a container without starting it
-func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
-	var err error
-
+func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerRequest) (_ Container, err error) {
 	// Make sure that bridge network exists
 	// In case it is disabled we will create reaper_default network
-	if p.DefaultNetwork == "" {
-		p.DefaultNetwork, err = p.getDefaultNetwork(ctx, p.client)
-		if err != nil {
-			return nil, err
-		}
+	defaultNetwork, err := p.ensureDefaultNetwork(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// If default network is not bridge make sure it is attached to the request
 	// as container won't be attached to it automatically
 	// in case of Podman the bridge network is called 'podman' as 'bridge' would conflict
-	if p.DefaultNetwork != p.defaultBridgeNetworkName {
+	if defaultNetwork != p.defaultBridgeNetworkName {
 		isAttached := false
 		for _, net := range req.Networks {
-			if net == p.DefaultNetwork {
+			if net == defaultNetwork {
 				isAttached = true
 				break
 			}
 		}
 
 		if !isAttached {
-			req.Networks = append(req.Networks, p.DefaultNetwork)
+			req.Networks = append(req.Networks, defaultNetwork)
 		}
 	}
 
+	if err := runPreCreateHooks(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	reqEnv, err := req.resolveEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	env := []string{}
-	for envKey, envVar := range req.Env {
+	for envKey, envVar := range reqEnv {
 		env = append(env, envKey+"="+envVar)
 	}
 
 	if req.Labels == nil {
 		req.Labels = make(map[string]string)
 	}
+	req.Labels[TestcontainerLabelCreatedAt] = time.Now().UTC().Format(time.RFC3339)
+	req.Labels = withAnnotations(req.Labels)
 
 	sessionID := sessionID()
 
 	var termSignal chan bool
+	// labels of the resources created with the container, e.g. its image and volumes, so that the reaper removes them
+	var reaperLabels map[string]string
 	// the reaper does not need to start a reaper for itself
 	isReaperContainer := strings.EqualFold(req.Image, reaperImage(req.ReaperImage))
 	if !req.SkipReaper && !isReaperContainer {
@@ -961,7 +1496,8 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		if err != nil {
 			return nil, fmt.Errorf("%w: connecting to reaper failed", err)
 		}
-		for k, v := range r.Labels() {
+		reaperLabels = r.Labels()
+		for k, v := range reaperLabels {
 			if _, ok := req.Labels[k]; !ok {
 				req.Labels[k] = v
 			}
@@ -969,6 +1505,8 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 	} else if !isReaperContainer {
 		p.printReaperBanner("container")
 	}
+	// the image and the volumes created with the container are annotated like it
+	resourceLabels := withAnnotations(reaperLabels)
 
 	if err = req.Validate(); err != nil {
 		return nil, err
@@ -978,12 +1516,17 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 	var platform *specs.Platform
 
 	if req.ShouldBuildImage() {
-		tag, err = p.BuildImage(ctx, &req)
+		tag, err = p.buildImage(ctx, &req, resourceLabels)
 		if err != nil {
 			return nil, err
 		}
+	} else if req.ImageArchive != "" {
+		tag, err = p.loadImageArchive(ctx, &req)
+		if err != nil {
+			return nil, classifyError(err, ErrImageLoad, "", req.ImageArchive)
+		}
 	} else {
-		tag = req.Image
+		tag = substituteImage(req.Image, req.ImageSubstitutors)
 
 		if req.ImagePlatform != "" {
 			p, err := platforms.Parse(req.ImagePlatform)
@@ -1021,7 +1564,7 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 			}
 
 			if err := p.attemptToPullImage(ctx, tag, pullOpt); err != nil {
-				return nil, err
+				return nil, classifyError(err, ErrImagePull, "", tag)
 			}
 		}
 	}
@@ -1051,15 +1594,18 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		Cmd:          req.Cmd,
 		Hostname:     req.Hostname,
 		User:         req.User,
+		WorkingDir:   req.WorkingDir,
 	}
 
 	// prepare mounts
-	mounts := mapToDockerMounts(req.Mounts)
+	mounts := withVolumeLabels(mapToDockerMounts(req.Mounts), resourceLabels)
+	binds := append(mapToDockerBinds(req.Mounts), req.Binds...)
 
 	hostConfig := &container.HostConfig{
 		ExtraHosts:   req.ExtraHosts,
+		DNS:          req.DNS,
 		PortBindings: exposedPortMap,
-		Binds:        req.Binds,
+		Binds:        binds,
 		Mounts:       mounts,
 		Tmpfs:        req.Tmpfs,
 		AutoRemove:   req.AutoRemove,
@@ -1069,6 +1615,7 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		ShmSize:      req.ShmSize,
 		CapAdd:       req.CapAdd,
 		CapDrop:      req.CapDrop,
+		GroupAdd:     req.GroupAdd,
 	}
 
 	endpointConfigs := map[string]*network.EndpointSettings{}
@@ -1100,6 +1647,18 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		return nil, err
 	}
 
+	// the caller gets no handle on the container if it can't be set up, so it is removed here
+	defer func() {
+		if err == nil {
+			return
+		}
+		removeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if removeErr := p.client.ContainerRemove(removeCtx, resp.ID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}); removeErr != nil {
+			p.Logger.Printf("Failed to remove container %s after a failed creation: %v", resp.ID, removeErr)
+		}
+	}()
+
 	// #248: If there is more than one network specified in the request attach newly created container to them one by one
 	if len(req.Networks) > 1 {
 		for _, n := range req.Networks[1:] {
@@ -1129,6 +1688,8 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		skipReaper:        req.SkipReaper,
 		stopProducer:      make(chan bool),
 		logger:            p.Logger,
+		recordStartupLogs: req.RecordStartupLogs,
+		lifecycleHooks:    req.LifecycleHooks,
 	}
 
 	for _, f := range req.Files {
@@ -1138,6 +1699,10 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		}
 	}
 
+	if err := c.runPostCreateHooks(ctx); err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
@@ -1163,6 +1728,16 @@ func (p *DockerProvider) ReuseOrCreateContainer(ctx context.Context, req Contain
 	if err != nil {
 		return nil, err
 	}
+	if c != nil && p.config.MaxContainerAge > 0 {
+		if age := containerAge(c, time.Now()); age > p.config.MaxContainerAge {
+			p.Logger.Printf("Recreating container %s, its age %s exceeds %s", req.Name, age.Round(time.Second), p.config.MaxContainerAge)
+			err := p.client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{RemoveVolumes: true, Force: true})
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to remove container %s", err, req.Name)
+			}
+			c = nil
+		}
+	}
 	if c == nil {
 		return p.CreateContainer(ctx, req)
 	}
@@ -1192,11 +1767,63 @@ func (p *DockerProvider) ReuseOrCreateContainer(ctx context.Context, req Contain
 		stopProducer:      make(chan bool),
 		logger:            p.Logger,
 		isRunning:         c.State == "running",
+		lifecycleHooks:    req.LifecycleHooks,
 	}
 
 	return dc, nil
 }
 
+// containerAge returns the age of the container from its TestcontainerLabelCreatedAt label, or from its creation
+// time if it was created without it
+func containerAge(c *types.Container, now time.Time) time.Duration {
+	created := time.Unix(c.Created, 0)
+	if createdAt, err := time.Parse(time.RFC3339, c.Labels[TestcontainerLabelCreatedAt]); err == nil {
+		created = createdAt
+	}
+
+	return now.Sub(created)
+}
+
+// FindContainers returns handles to the existing containers, running or not, carrying all the given labels.
+// The handles are not tied to a reaper: containers started elsewhere keep their own lifecycle,
+// and terminating a handle removes the container.
+func (p *DockerProvider) FindContainers(ctx context.Context, labelSelector map[string]string) ([]Container, error) {
+	if len(labelSelector) == 0 {
+		return nil, ErrEmptyLabelSelector
+	}
+
+	filter := filters.NewArgs()
+	for k, v := range labelSelector {
+		filter.Add("label", k+"="+v)
+	}
+
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]Container, 0, len(containers))
+	for _, c := range containers {
+		id, err := uuid.Parse(c.Labels[TestcontainerLabelSessionID])
+		if err != nil {
+			id = sessionID()
+		}
+
+		found = append(found, &DockerContainer{
+			ID:           c.ID,
+			Image:        c.Image,
+			sessionID:    id,
+			provider:     p,
+			skipReaper:   true,
+			stopProducer: make(chan bool),
+			logger:       p.Logger,
+			isRunning:    c.State == "running",
+		})
+	}
+
+	return found, nil
+}
+
 // attemptToPullImage tries to pull the image while respecting the ctx cancellations.
 // Besides, if the image cannot be pulled due to ErrorNotFound then no need to retry but terminate immediately.
 func (p *DockerProvider) attemptToPullImage(ctx context.Context, tag string, pullOpt types.ImagePullOptions) error {
@@ -1252,10 +1879,19 @@ func (p *DockerProvider) Config() TestContainersConfig {
 	return p.config
 }
 
+// Close releases the resources of the session held by the provider, i.e. closes the audit file, which the next
+// operation recorded reopens. The Docker client is shared by the providers and stays open.
+func (p *DockerProvider) Close() error {
+	return sessionAuditTrail.closeFile()
+}
+
 // daemonHost gets the host or ip of the Docker daemon where ports are exposed on
 // Warning: this is based on your Docker host setting. Will fail if using an SSH tunnel
 // You can use the "TC_HOST" env variable to set this yourself
 func (p *DockerProvider) daemonHost(ctx context.Context) (string, error) {
+	p.hostLock.Lock()
+	defer p.hostLock.Unlock()
+
 	if p.hostCache != "" {
 		return p.hostCache, nil
 	}
@@ -1298,16 +1934,13 @@ func (p *DockerProvider) daemonHost(ctx context.Context) (string, error) {
 
 // CreateNetwork returns the object representing a new network identified by its name
 func (p *DockerProvider) CreateNetwork(ctx context.Context, req NetworkRequest) (Network, error) {
-	var err error
-
 	// Make sure that bridge network exists
 	// In case it is disabled we will create reaper_default network
-	if p.DefaultNetwork == "" {
-		if p.DefaultNetwork, err = p.getDefaultNetwork(ctx, p.client); err != nil {
-			return nil, err
-		}
+	if _, err := p.ensureDefaultNetwork(ctx); err != nil {
+		return nil, err
 	}
 
+	req.Labels = withAnnotations(req.Labels)
 	if req.Labels == nil {
 		req.Labels = make(map[string]string)
 	}
@@ -1372,14 +2005,11 @@ func (p *DockerProvider) GetNetwork(ctx context.Context, req NetworkRequest) (ty
 
 func (p *DockerProvider) GetGatewayIP(ctx context.Context) (string, error) {
 	// Use a default network as defined in the DockerProvider
-	if p.DefaultNetwork == "" {
-		var err error
-		p.DefaultNetwork, err = p.getDefaultNetwork(ctx, p.client)
-		if err != nil {
-			return "", err
-		}
+	defaultNetwork, err := p.ensureDefaultNetwork(ctx)
+	if err != nil {
+		return "", err
 	}
-	nw, err := p.GetNetwork(ctx, NetworkRequest{Name: p.DefaultNetwork})
+	nw, err := p.GetNetwork(ctx, NetworkRequest{Name: defaultNetwork})
 	if err != nil {
 		return "", err
 	}
@@ -1431,6 +2061,23 @@ func getDefaultGatewayIP() (string, error) {
 	return ip, nil
 }
 
+// ensureDefaultNetwork lazily determines the default network of the provider,
+// it is safe to be called concurrently
+func (p *DockerProvider) ensureDefaultNetwork(ctx context.Context) (string, error) {
+	p.networkLock.Lock()
+	defer p.networkLock.Unlock()
+
+	if p.DefaultNetwork == "" {
+		defaultNetwork, err := p.getDefaultNetwork(ctx, p.client)
+		if err != nil {
+			return "", err
+		}
+		p.DefaultNetwork = defaultNetwork
+	}
+
+	return p.DefaultNetwork, nil
+}
+
 func (p *DockerProvider) getDefaultNetwork(ctx context.Context, cli client.APIClient) (string, error) {
 	// Get list of available networks
 	networkResources, err := cli.NetworkList(ctx, types.NetworkListOptions{})
@@ -1462,7 +2109,8 @@ func (p *DockerProvider) getDefaultNetwork(ctx context.Context, cli client.APICl
 			},
 		})
 
-		if err != nil {
+		// another provider, possibly in a different test process, might have created it concurrently
+		if err != nil && !errdefs.IsConflict(err) {
 			return "", err
 		}
 	}
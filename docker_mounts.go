@@ -1,6 +1,10 @@
 package testcontainers
 
-import "github.com/docker/docker/api/types/mount"
+import (
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+)
 
 var (
 	mountTypeMapping = map[MountType]mount.Type{
@@ -29,6 +33,23 @@ type TmpfsMounter interface {
 	GetTmpfsOptions() *mount.TmpfsOptions
 }
 
+// SELinuxRelabeler can optionally be implemented by bind mount sources
+// that need their host path relabeled on SELinux enabled hosts
+type SELinuxRelabeler interface {
+	GetSELinuxLabel() SELinuxLabel
+}
+
+func (s GenericBindMountSource) GetBindOptions() *mount.BindOptions {
+	if s.Propagation == "" {
+		return nil
+	}
+	return &mount.BindOptions{Propagation: mount.Propagation(s.Propagation)}
+}
+
+func (s GenericBindMountSource) GetSELinuxLabel() SELinuxLabel {
+	return s.SELinuxLabel
+}
+
 type DockerBindMountSource struct {
 	*mount.BindOptions
 
@@ -78,13 +99,24 @@ func (s DockerTmpfsMountSource) GetTmpfsOptions() *mount.TmpfsOptions {
 	return s.TmpfsOptions
 }
 
+// needsSELinuxRelabel reports whether the mount has to be passed as a bind string,
+// because the mount API of the daemon does not support SELinux relabeling
+func needsSELinuxRelabel(m ContainerMount) bool {
+	relabeler, ok := m.Source.(SELinuxRelabeler)
+	return ok && m.Source.Type() == MountTypeBind && relabeler.GetSELinuxLabel() != ""
+}
+
 // mapToDockerMounts maps the given []ContainerMount to the corresponding
-// []mount.Mount for further processing
+// []mount.Mount for further processing.
+// Bind mounts that need SELinux relabeling are skipped, see mapToDockerBinds.
 func mapToDockerMounts(containerMounts ContainerMounts) []mount.Mount {
 	mounts := make([]mount.Mount, 0, len(containerMounts))
 
 	for idx := range containerMounts {
 		m := containerMounts[idx]
+		if needsSELinuxRelabel(m) {
+			continue
+		}
 
 		var mountType mount.Type
 		if mt, ok := mountTypeMapping[m.Source.Type()]; ok {
@@ -114,3 +146,61 @@ func mapToDockerMounts(containerMounts ContainerMounts) []mount.Mount {
 
 	return mounts
 }
+
+// withVolumeLabels adds the given labels to the named volumes of the mounts, which the daemon creates with them
+// if they do not exist yet, without overriding the labels of their options
+func withVolumeLabels(mounts []mount.Mount, labels map[string]string) []mount.Mount {
+	if len(labels) == 0 {
+		return mounts
+	}
+
+	for i := range mounts {
+		if mounts[i].Type != mount.TypeVolume || mounts[i].Source == "" {
+			continue
+		}
+
+		// the options may be shared with the request
+		options := mount.VolumeOptions{}
+		if mounts[i].VolumeOptions != nil {
+			options = *mounts[i].VolumeOptions
+		}
+		volumeLabels := make(map[string]string, len(options.Labels)+len(labels))
+		for k, v := range labels {
+			volumeLabels[k] = v
+		}
+		for k, v := range options.Labels {
+			volumeLabels[k] = v
+		}
+		options.Labels = volumeLabels
+		mounts[i].VolumeOptions = &options
+	}
+
+	return mounts
+}
+
+// mapToDockerBinds maps the bind mounts that need SELinux relabeling
+// to the host:target:options syntax understood by the daemon
+func mapToDockerBinds(containerMounts ContainerMounts) []string {
+	var binds []string
+
+	for idx := range containerMounts {
+		m := containerMounts[idx]
+		if !needsSELinuxRelabel(m) {
+			continue
+		}
+
+		options := []string{string(m.Source.(SELinuxRelabeler).GetSELinuxLabel())}
+		if m.ReadOnly {
+			options = append(options, "ro")
+		}
+		if bindMounter, ok := m.Source.(BindMounter); ok {
+			if bindOptions := bindMounter.GetBindOptions(); bindOptions != nil && bindOptions.Propagation != "" {
+				options = append(options, string(bindOptions.Propagation))
+			}
+		}
+
+		binds = append(binds, m.Source.Source()+":"+m.Target.Target()+":"+strings.Join(options, ","))
+	}
+
+	return binds
+}
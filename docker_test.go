@@ -20,8 +20,10 @@ import (
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
 	"github.com/docker/go-units"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gotest.tools/v3/env"
@@ -35,6 +37,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -497,6 +500,73 @@ func TestContainerStopWithReaper(t *testing.T) {
 	}
 }
 
+func TestContainerStopWithSignal(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+			// the shell is PID 1, so it only reacts to the signals it traps
+			Cmd: []string{"sh", "-c", "trap 'exit 3' QUIT; while true; do sleep 0.1; done"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	stopTimeout := 10 * time.Second
+	start := time.Now()
+	err = container.StopWithSignal(ctx, "SIGQUIT", &stopTimeout)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), stopTimeout, "the container should have exited on SIGQUIT")
+
+	state, err := container.State(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Running)
+	assert.Equal(t, 3, state.ExitCode)
+}
+
+// killRecordingClient records the signals sent to the containers, which exit after a delay, for the tests of
+// stopWithKill
+type killRecordingClient struct {
+	client.APIClient
+	exitAfter time.Duration
+	signals   []string
+}
+
+func (c *killRecordingClient) ContainerKill(_ context.Context, _ string, signal string) error {
+	c.signals = append(c.signals, signal)
+	return nil
+}
+
+func (c *killRecordingClient) ContainerWait(ctx context.Context, _ string, _ container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	statusCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		select {
+		case <-time.After(c.exitAfter):
+			statusCh <- container.WaitResponse{}
+		case <-ctx.Done():
+			errCh <- ctx.Err()
+		}
+	}()
+	return statusCh, errCh
+}
+
+func TestStopWithKillTimeout(t *testing.T) {
+	stop := func(timeout time.Duration) []string {
+		cli := &killRecordingClient{exitAfter: 100 * time.Millisecond}
+		c := &DockerContainer{ID: "stopped", provider: &DockerProvider{client: cli}}
+		require.NoError(t, c.stopWithKill(context.Background(), "SIGQUIT", &timeout))
+		return cli.signals
+	}
+
+	assert.Equal(t, []string{"SIGQUIT", "SIGKILL"}, stop(10*time.Millisecond), "the container is killed once the timeout expires")
+	assert.Equal(t, []string{"SIGQUIT"}, stop(time.Second))
+	assert.Equal(t, []string{"SIGQUIT"}, stop(-time.Second), "a negative timeout waits for the container to exit")
+}
+
 func TestContainerTerminationWithReaper(t *testing.T) {
 	ctx := context.Background()
 
@@ -1549,6 +1619,120 @@ func TestReadTCPropsFile(t *testing.T) {
 					RyukPrivileged: false,
 				},
 			},
+			{
+				`port.mapping.timeout=10s`,
+				map[string]string{},
+				TestContainersConfig{
+					PortMappingTimeout: 10 * time.Second,
+				},
+			},
+			{
+				`port.mapping.timeout=10s`,
+				map[string]string{
+					"TESTCONTAINERS_PORT_MAPPING_TIMEOUT": "-1s",
+				},
+				TestContainersConfig{
+					PortMappingTimeout: -time.Second,
+				},
+			},
+			{
+				`ryuk.dry.run=true`,
+				map[string]string{},
+				TestContainersConfig{
+					RyukDryRun: true,
+				},
+			},
+			{
+				`ryuk.dry.run=true`,
+				map[string]string{
+					"TESTCONTAINERS_RYUK_DRY_RUN": "false",
+				},
+				TestContainersConfig{
+					RyukDryRun: false,
+				},
+			},
+			{
+				`container.starts.max=4`,
+				map[string]string{},
+				TestContainersConfig{
+					MaxConcurrentStarts: 4,
+				},
+			},
+			{
+				`container.starts.max=4`,
+				map[string]string{
+					"TESTCONTAINERS_MAX_CONCURRENT_STARTS": "2",
+				},
+				TestContainersConfig{
+					MaxConcurrentStarts: 2,
+				},
+			},
+			{
+				`container.starts.max=4`,
+				map[string]string{
+					"TESTCONTAINERS_MAX_CONCURRENT_STARTS": "many",
+				},
+				TestContainersConfig{
+					MaxConcurrentStarts: 4,
+				},
+			},
+			{
+				`audit.file=/tmp/audit.jsonl`,
+				map[string]string{},
+				TestContainersConfig{
+					AuditFile: "/tmp/audit.jsonl",
+				},
+			},
+			{
+				`audit.file=/tmp/audit.jsonl`,
+				map[string]string{
+					"TESTCONTAINERS_AUDIT_FILE": "/tmp/session.jsonl",
+				},
+				TestContainersConfig{
+					AuditFile: "/tmp/session.jsonl",
+				},
+			},
+			{
+				`container.reuse.max.age=24h`,
+				map[string]string{},
+				TestContainersConfig{
+					MaxContainerAge: 24 * time.Hour,
+				},
+			},
+			{
+				`container.reuse.max.age=24h`,
+				map[string]string{
+					"TESTCONTAINERS_MAX_CONTAINER_AGE": "30m",
+				},
+				TestContainersConfig{
+					MaxContainerAge: 30 * time.Minute,
+				},
+			},
+			{
+				`container.reuse.max.age=24h`,
+				map[string]string{
+					"TESTCONTAINERS_MAX_CONTAINER_AGE": "a week",
+				},
+				TestContainersConfig{
+					MaxContainerAge: 24 * time.Hour,
+				},
+			},
+			{
+				`resource.annotations=team=payments,pipeline=1234`,
+				map[string]string{},
+				TestContainersConfig{
+					ResourceAnnotations: "team=payments,pipeline=1234",
+				},
+			},
+			{
+				`resource.annotations=team=payments`,
+				map[string]string{
+					"TESTCONTAINERS_RESOURCE_ANNOTATIONS": "team=checkout",
+				},
+				TestContainersConfig{
+					ResourceAnnotations: "team=checkout",
+				},
+			},
 		}
 		for i, tt := range tests {
 			t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
@@ -1647,6 +1831,55 @@ func ExampleContainer_MappedPort() {
 	_, _ = http.Get(fmt.Sprintf("http://%s:%s", ip, port.Port()))
 }
 
+func TestHostPort(t *testing.T) {
+	ports := nat.PortMap{
+		"80/tcp":   {{HostIP: "0.0.0.0", HostPort: "49153"}},
+		"53/udp":   {{HostIP: "0.0.0.0", HostPort: "49154"}},
+		"8080/tcp": {},
+	}
+
+	mapped, found := hostPort(ports, "80/tcp")
+	assert.True(t, found)
+	assert.Equal(t, nat.Port("49153/tcp"), mapped)
+
+	mapped, found = hostPort(ports, "53/udp")
+	assert.True(t, found)
+	assert.Equal(t, nat.Port("49154/udp"), mapped)
+
+	_, found = hostPort(ports, "8080/tcp")
+	assert.False(t, found, "the port is not bound yet")
+
+	_, found = hostPort(ports, "80/udp")
+	assert.False(t, found)
+}
+
+func TestExposesPort(t *testing.T) {
+	inspect := &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			HostConfig: &container.HostConfig{PortBindings: nat.PortMap{"8080/tcp": {{HostPort: "8080"}}}},
+		},
+		Config: &container.Config{ExposedPorts: nat.PortSet{"80/tcp": {}}},
+	}
+
+	assert.True(t, exposesPort(inspect, "80/tcp"))
+	assert.True(t, exposesPort(inspect, "8080/tcp"), "published ports are exposed")
+	assert.False(t, exposesPort(inspect, "80/udp"))
+	assert.False(t, exposesPort(inspect, "443/tcp"))
+}
+
+func TestContainerAge(t *testing.T) {
+	now := time.Date(2022, 11, 7, 12, 0, 0, 0, time.UTC)
+
+	labelled := &types.Container{
+		Created: now.Add(-time.Hour).Unix(),
+		Labels:  map[string]string{TestcontainerLabelCreatedAt: "2022-11-06T12:00:00Z"},
+	}
+	assert.Equal(t, 24*time.Hour, containerAge(labelled, now))
+
+	unlabelled := &types.Container{Created: now.Add(-time.Hour).Unix()}
+	assert.Equal(t, time.Hour, containerAge(unlabelled, now), "the creation time is used without the label")
+}
+
 func TestContainerCreationWithBindAndVolume(t *testing.T) {
 	absPath, err := filepath.Abs("./testresources/hello.sh")
 	if err != nil {
@@ -1736,6 +1969,206 @@ func TestContainerWithTmpFs(t *testing.T) {
 	}
 }
 
+func TestContainerExecWithResult(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	cmd := []string{"sh", "-c", "echo out; echo err >&2; exit 3"}
+
+	result, err := container.ExecWithResult(ctx, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "out\n", string(result.Stdout))
+	assert.Equal(t, "err\n", string(result.Stderr))
+	assert.Equal(t, "out\nerr\n", string(result.Combined()))
+
+	result, err = container.ExecWithResult(ctx, cmd, tcexec.WithoutStderr())
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", string(result.Stdout))
+	assert.Empty(t, result.Stderr)
+
+	result, err = container.ExecWithResult(ctx, []string{"pwd"}, tcexec.WithWorkingDir("/tmp"))
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp\n", string(result.Stdout))
+}
+
+func TestContainerExecMultiplexed(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: nginxAlpineImage,
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	code, reader, err := container.Exec(ctx, []string{"sh", "-c", "echo $FOO"}, tcexec.WithEnv([]string{"FOO=foo"}), tcexec.Multiplexed())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "foo\n", string(output))
+
+	// the output exceeds the buffer of the pipe, the command only exits once it is read
+	execCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	code, reader, err = container.Exec(execCtx, []string{"sh", "-c", "head -c 1048576 /dev/zero"}, tcexec.Multiplexed())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+
+	output, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Len(t, output, 1048576)
+}
+
+func TestContainerWithUserAndGroups(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:    nginxAlpineImage,
+			Cmd:      []string{"sleep", "60"},
+			User:     "1000:1000",
+			GroupAdd: []string{"2000"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	result, err := container.ExecWithResult(ctx, []string{"id"})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Stdout), "uid=1000")
+	assert.Contains(t, string(result.Stdout), "gid=1000")
+	assert.Contains(t, string(result.Stdout), "2000")
+}
+
+func TestContainerWithWorkingDir(t *testing.T) {
+	ctx := context.Background()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:      nginxAlpineImage,
+			Cmd:        []string{"sh", "-c", "pwd > cwd.txt && sleep 60"},
+			WaitingFor: wait.ForExec([]string{"test", "-f", "/var/log/cwd.txt"}),
+		},
+		Started: true,
+	}
+	WithWorkingDir("/var/log")(&req)
+
+	container, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	result, err := container.ExecWithResult(ctx, []string{"cat", "/var/log/cwd.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, "/var/log\n", string(result.Stdout))
+}
+
+func TestDockerProviderFindContainers(t *testing.T) {
+	ctx := context.Background()
+
+	label := "org.testcontainers.golang.test.find"
+	value := uuid.NewString()
+
+	container, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:  nginxAlpineImage,
+			Labels: map[string]string{label: value},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+
+	found, err := provider.FindContainers(ctx, map[string]string{label: value})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, container.GetContainerID(), found[0].GetContainerID())
+	assert.Equal(t, container.SessionID(), found[0].SessionID())
+	assert.True(t, found[0].IsRunning())
+
+	found, err = provider.FindContainers(ctx, map[string]string{label: uuid.NewString()})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestDockerProviderFindContainersRequiresLabels(t *testing.T) {
+	provider := &DockerProvider{}
+
+	_, err := provider.FindContainers(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrEmptyLabelSelector)
+}
+
+func TestContainerStartupLogs(t *testing.T) {
+	ctx := context.Background()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:      nginxAlpineImage,
+			Cmd:        []string{"sh", "-c", "echo booting; echo 'WARN low entropy' >&2; echo ready; sleep 60"},
+			WaitingFor: wait.ForLog("ready"),
+		},
+		Started: true,
+	}
+	WithStartupLogs()(&req)
+
+	container, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, container)
+
+	logs := container.StartupLogs()
+	assert.Contains(t, logs, "booting")
+	assert.Contains(t, logs, "WARN low entropy")
+	assert.Contains(t, logs, "ready")
+}
+
+func TestContainerExitInfoInWaitError(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image:      nginxAlpineImage,
+			Cmd:        []string{"sh", "-c", "exit 5"},
+			WaitingFor: wait.ForLog("never logged").WithStartupTimeout(5 * time.Second),
+		},
+		Started: true,
+	})
+	require.Error(t, err)
+	require.NotNil(t, container)
+	terminateContainerOnEnd(t, ctx, container)
+
+	assert.Contains(t, err.Error(), "container exited with code 5")
+
+	info, err := container.ExitInfo(ctx)
+	require.NoError(t, err)
+	assert.False(t, info.Running)
+	assert.Equal(t, 5, info.ExitCode)
+	assert.False(t, info.OOMKilled)
+	assert.False(t, info.FinishedAt.IsZero())
+}
+
 func TestContainerNonExistentImage(t *testing.T) {
 	t.Run("if the image not found don't propagate the error", func(t *testing.T) {
 		_, err := GenericContainer(context.Background(), GenericContainerRequest{
@@ -2528,3 +2961,102 @@ func TestDockerProviderFindContainerByName(t *testing.T) {
 	require.NotNil(t, c)
 	assert.Contains(t, c.Names, c1Name)
 }
+
+func TestReplaceSharedDockerClient(t *testing.T) {
+	sharedDockerClientLock.Lock()
+	previous, previousHost, previousConfig := sharedDockerClient, sharedDockerHost, sharedTCConfig
+	sharedDockerClientLock.Unlock()
+	t.Cleanup(func() {
+		sharedDockerClientLock.Lock()
+		sharedDockerClient, sharedDockerHost, sharedTCConfig = previous, previousHost, previousConfig
+		sharedDockerClientLock.Unlock()
+	})
+
+	broken, err := client.NewClientWithOpts(client.WithHost("tcp://127.0.0.1:1"))
+	require.NoError(t, err)
+	sharedDockerClientLock.Lock()
+	sharedDockerClient = broken
+	sharedDockerClientLock.Unlock()
+
+	replaced, host, _, err := replaceSharedDockerClient(broken)
+	require.NoError(t, err)
+	assert.NotSame(t, broken, replaced)
+
+	_, expectedHost, _, err := NewDockerClient()
+	require.NoError(t, err)
+	assert.Equal(t, expectedHost, host, "the client is created like the first one")
+
+	shared, _, _, err := getSharedDockerClient()
+	require.NoError(t, err)
+	assert.Same(t, replaced, shared, "the broken client is no longer shared")
+
+	again, _, _, err := replaceSharedDockerClient(broken)
+	require.NoError(t, err)
+	assert.Same(t, replaced, again, "the client replaced by another provider is kept")
+}
+
+func TestDefaultDockerProvider(t *testing.T) {
+	p1, err := defaultDockerProvider()
+	require.NoError(t, err)
+
+	p2, err := defaultDockerProvider()
+	require.NoError(t, err)
+	assert.Same(t, p1, p2, "the default provider is created once")
+
+	sharedDockerClientLock.Lock()
+	previous, previousHost, previousConfig := sharedDockerClient, sharedDockerHost, sharedTCConfig
+	sharedDockerClientLock.Unlock()
+	t.Cleanup(func() {
+		sharedDockerClientLock.Lock()
+		sharedDockerClient, sharedDockerHost, sharedTCConfig = previous, previousHost, previousConfig
+		sharedDockerClientLock.Unlock()
+	})
+
+	_, _, _, err = replaceSharedDockerClient(previous)
+	require.NoError(t, err)
+
+	p3, err := defaultDockerProvider()
+	require.NoError(t, err)
+	assert.NotSame(t, p1, p3, "the default provider is created again once the shared client was replaced")
+}
+
+func TestDockerProvidersShareClient(t *testing.T) {
+	c1, _, _, err := getSharedDockerClient()
+	require.NoError(t, err)
+
+	c2, _, _, err := getSharedDockerClient()
+	require.NoError(t, err)
+
+	assert.Same(t, c1, c2)
+
+	p1, err := NewDockerProvider(WithLogger(TestLogger(t)))
+	require.NoError(t, err)
+
+	p2, err := NewDockerProvider(WithLogger(TestLogger(t)))
+	require.NoError(t, err)
+
+	// the clients of the providers record the operations of the shared client in the audit trail
+	assert.Same(t, p1.client.(*auditClient).APIClient, p2.client.(*auditClient).APIClient)
+}
+
+func TestDockerProviderConcurrentDefaultNetwork(t *testing.T) {
+	provider, err := NewDockerProvider(WithLogger(TestLogger(t)))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	networks := make(chan string, 10)
+	errs := make(chan error, 10)
+
+	for i := 0; i < 10; i++ {
+		go func() {
+			n, err := provider.ensureDefaultNetwork(ctx)
+			networks <- n
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, <-errs)
+		assert.NotEmpty(t, <-networks)
+	}
+}
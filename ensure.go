@@ -0,0 +1,247 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// ErrResourceMismatch is returned when an existing network or volume does not match the request ensuring it, e.g.
+// because it was created with another driver or other labels
+var ErrResourceMismatch = errors.New("existing resource does not match the request")
+
+// VolumeRequest represents the parameters used to create a volume
+type VolumeRequest struct {
+	Name       string
+	Driver     string // driver of the volume, the default one of the daemon if empty
+	DriverOpts map[string]string
+	Labels     map[string]string
+
+	SkipReaper  bool   // indicates whether we skip setting up a reaper for this
+	ReaperImage string // alternative reaper registry
+}
+
+// GenericVolumeRequest represents parameters to a generic volume
+type GenericVolumeRequest struct {
+	VolumeRequest              // embedded request for provider
+	ProviderType  ProviderType // which provider to use, Docker if empty
+}
+
+// DockerVolume represents a volume started using Docker
+type DockerVolume struct {
+	Name              string
+	Driver            string
+	Mountpoint        string
+	Labels            map[string]string
+	provider          *DockerProvider
+	terminationSignal chan bool
+}
+
+// Remove is used to remove the volume, it fails if containers use it
+func (v *DockerVolume) Remove(ctx context.Context) error {
+	select {
+	// close reaper if it was created
+	case v.terminationSignal <- true:
+	default:
+	}
+	return v.provider.client.VolumeRemove(ctx, v.Name, false)
+}
+
+// EnsureNetwork returns the network of the request, creating it if it does not exist and adopting it otherwise, so
+// that the packages of a test run racing to create a shared network do not fail. See DockerProvider.EnsureNetwork.
+func EnsureNetwork(ctx context.Context, req GenericNetworkRequest) (*DockerNetwork, error) {
+	provider, err := dockerProviderOf(req.ProviderType)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.EnsureNetwork(ctx, req.NetworkRequest)
+}
+
+// EnsureVolume returns the volume of the request, creating it if it does not exist and adopting it otherwise, so
+// that the packages of a test run racing to create a shared volume do not fail. See DockerProvider.EnsureVolume.
+func EnsureVolume(ctx context.Context, req GenericVolumeRequest) (*DockerVolume, error) {
+	provider, err := dockerProviderOf(req.ProviderType)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.EnsureVolume(ctx, req.VolumeRequest)
+}
+
+// EnsureNetwork returns the network named by the request, creating it if it does not exist. An existing network, or
+// one created concurrently, is adopted if its driver and labels match the request, ErrResourceMismatch is returned
+// otherwise. The labels of Testcontainers, e.g. the session of the network, are not compared. An adopted network is
+// not registered with the reaper of the session: it is left to the session which created it.
+func (p *DockerProvider) EnsureNetwork(ctx context.Context, req NetworkRequest) (*DockerNetwork, error) {
+	if req.Name == "" {
+		return nil, errors.New("a network to ensure must have a name")
+	}
+
+	resource, err := p.client.NetworkInspect(ctx, req.Name, types.NetworkInspectOptions{})
+	if client.IsErrNotFound(err) {
+		// the daemon only refuses a network whose name is taken if asked to
+		req.CheckDuplicate = true
+		created, err := p.CreateNetwork(ctx, req)
+		if err == nil {
+			return created.(*DockerNetwork), nil
+		}
+		if !errdefs.IsConflict(err) {
+			return nil, err
+		}
+		// created concurrently
+		resource, err = p.client.NetworkInspect(ctx, req.Name, types.NetworkInspectOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Driver != "" && req.Driver != resource.Driver {
+		return nil, fmt.Errorf("%w: network %s has driver %s instead of %s", ErrResourceMismatch, req.Name, resource.Driver, req.Driver)
+	}
+	if err := reconcileLabels(resource.Labels, req.Labels); err != nil {
+		return nil, fmt.Errorf("%w: network %s", err, req.Name)
+	}
+
+	return &DockerNetwork{
+		ID:       resource.ID,
+		Driver:   resource.Driver,
+		Name:     resource.Name,
+		provider: p,
+	}, nil
+}
+
+// EnsureVolume returns the volume named by the request, creating it if it does not exist. An existing volume, or one
+// created concurrently, is adopted if its driver and labels match the request, ErrResourceMismatch is returned
+// otherwise. The labels of Testcontainers, e.g. the session of the volume, are not compared. An adopted volume is not
+// registered with the reaper of the session: it is left to the session which created it.
+func (p *DockerProvider) EnsureVolume(ctx context.Context, req VolumeRequest) (*DockerVolume, error) {
+	if req.Name == "" {
+		return nil, errors.New("a volume to ensure must have a name")
+	}
+
+	existing, err := p.client.VolumeInspect(ctx, req.Name)
+	if client.IsErrNotFound(err) {
+		return p.createVolume(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Driver != "" && req.Driver != existing.Driver {
+		return nil, fmt.Errorf("%w: volume %s has driver %s instead of %s", ErrResourceMismatch, req.Name, existing.Driver, req.Driver)
+	}
+	if err := reconcileLabels(existing.Labels, req.Labels); err != nil {
+		return nil, fmt.Errorf("%w: volume %s", err, req.Name)
+	}
+
+	return &DockerVolume{
+		Name:       existing.Name,
+		Driver:     existing.Driver,
+		Mountpoint: existing.Mountpoint,
+		Labels:     existing.Labels,
+		provider:   p,
+	}, nil
+}
+
+// createVolume creates the volume of the request, registered with the reaper of the session unless skipped. The
+// daemon returns the existing volume if it was created concurrently, its labels are reconciled with the request.
+func (p *DockerProvider) createVolume(ctx context.Context, req VolumeRequest) (*DockerVolume, error) {
+	labels := withAnnotations(copyLabels(req.Labels))
+
+	var termSignal chan bool
+	if !req.SkipReaper {
+		r, err := NewReaper(context.WithValue(ctx, dockerHostContextKey, p.host), sessionID().String(), p, req.ReaperImage)
+		if err != nil {
+			return nil, fmt.Errorf("%w: creating volume reaper failed", err)
+		}
+		termSignal, err = r.Connect()
+		if err != nil {
+			return nil, fmt.Errorf("%w: connecting to volume reaper failed", err)
+		}
+		for k, v := range r.Labels() {
+			if _, ok := labels[k]; !ok {
+				labels[k] = v
+			}
+		}
+	} else {
+		p.printReaperBanner("volume")
+	}
+
+	created, err := p.client.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       req.Name,
+		Driver:     req.Driver,
+		DriverOpts: req.DriverOpts,
+		Labels:     labels,
+	})
+	if err == nil {
+		err = reconcileLabels(created.Labels, req.Labels)
+	}
+	if err != nil {
+		if termSignal != nil {
+			termSignal <- true
+		}
+		return nil, fmt.Errorf("%w: volume %s", err, req.Name)
+	}
+
+	return &DockerVolume{
+		Name:              created.Name,
+		Driver:            created.Driver,
+		Mountpoint:        created.Mountpoint,
+		Labels:            created.Labels,
+		provider:          p,
+		terminationSignal: termSignal,
+	}, nil
+}
+
+// reconcileLabels returns ErrResourceMismatch if the existing labels of a resource miss or differ from the requested
+// ones, the labels of Testcontainers excepted
+func reconcileLabels(existing map[string]string, requested map[string]string) error {
+	var mismatches []string
+	for k, v := range requested {
+		if strings.HasPrefix(k, TestcontainerLabel) {
+			continue
+		}
+		if actual, ok := existing[k]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("label %s is missing", k))
+		} else if actual != v {
+			mismatches = append(mismatches, fmt.Sprintf("label %s is %q instead of %q", k, actual, v))
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	sort.Strings(mismatches)
+	return fmt.Errorf("%w: %s", ErrResourceMismatch, strings.Join(mismatches, ", "))
+}
+
+// copyLabels returns a copy of the labels, never nil
+func copyLabels(labels map[string]string) map[string]string {
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	return copied
+}
+
+// dockerProviderOf returns the Docker provider of the provider type
+func dockerProviderOf(providerType ProviderType) (*DockerProvider, error) {
+	provider, err := providerType.GetProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerProvider, ok := provider.(*DockerProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider %T does not support networks and volumes", provider)
+	}
+	return dockerProvider, nil
+}
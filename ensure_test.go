@@ -0,0 +1,82 @@
+package testcontainers
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLabels(t *testing.T) {
+	existing := map[string]string{
+		"team":                      "payments",
+		TestcontainerLabelSessionID: "another-session",
+	}
+
+	assert.NoError(t, reconcileLabels(existing, map[string]string{"team": "payments"}))
+	assert.NoError(t, reconcileLabels(existing, map[string]string{TestcontainerLabelSessionID: "this-session"}),
+		"the labels of Testcontainers are not compared")
+
+	err := reconcileLabels(existing, map[string]string{"team": "checkout", "pipeline": "1234"})
+	assert.ErrorIs(t, err, ErrResourceMismatch)
+	assert.EqualError(t, err, `existing resource does not match the request: label pipeline is missing, label team is "payments" instead of "checkout"`)
+}
+
+func TestEnsureNetwork(t *testing.T) {
+	ctx := context.Background()
+	name := "testcontainers-ensure-" + string(testNameHash(t.Name()))
+	req := GenericNetworkRequest{
+		NetworkRequest: NetworkRequest{Name: name, Labels: map[string]string{"team": "payments"}},
+	}
+
+	// packages racing to create the shared network
+	networks := make([]*DockerNetwork, 4)
+	errs := make([]error, len(networks))
+	var wg sync.WaitGroup
+	for i := range networks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			networks[i], errs[i] = EnsureNetwork(ctx, req)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range networks {
+		require.NoError(t, errs[i])
+		assert.Equal(t, networks[0].ID, networks[i].ID, "the network is adopted")
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, networks[0].Remove(ctx))
+	})
+
+	req.Labels["team"] = "checkout"
+	_, err := EnsureNetwork(ctx, req)
+	assert.ErrorIs(t, err, ErrResourceMismatch)
+}
+
+func TestEnsureVolume(t *testing.T) {
+	ctx := context.Background()
+	name := "testcontainers-ensure-" + string(testNameHash(t.Name()))
+	req := GenericVolumeRequest{
+		VolumeRequest: VolumeRequest{Name: name, Labels: map[string]string{"team": "payments"}},
+	}
+
+	created, err := EnsureVolume(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, created.Remove(ctx))
+	})
+	assert.Equal(t, "payments", created.Labels["team"])
+	assert.Equal(t, CurrentSession().ID(), created.Labels[TestcontainerLabelSessionID], "the created volume is reaped")
+
+	adopted, err := EnsureVolume(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, created.Mountpoint, adopted.Mountpoint)
+
+	req.Labels["team"] = "checkout"
+	_, err = EnsureVolume(ctx, req)
+	assert.ErrorIs(t, err, ErrResourceMismatch)
+}
@@ -0,0 +1,94 @@
+package testcontainers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// The classes of the failures of the containers. A failure is reported as a ContainerError, which matches its class
+// with errors.Is, e.g. errors.Is(err, ErrPortBinding), and wraps the error that caused it.
+var (
+	// ErrImagePull is the class of the failures to pull the image of a container
+	ErrImagePull = errors.New("failed to pull image")
+	// ErrImageLoad is the class of the failures to load the image archive of a container, e.g. because it does not
+	// hold the expected image
+	ErrImageLoad = errors.New("failed to load image archive")
+	// ErrPortBinding is the class of the failures to publish the ports of a container on the host, e.g. because
+	// another process already listens on one of them
+	ErrPortBinding = errors.New("failed to bind the ports of the container")
+	// ErrWaitTimeout is the class of the failures of the wait strategies, i.e. the container did not get ready before
+	// the timeout of its wait strategy
+	ErrWaitTimeout = errors.New("container did not get ready in time")
+	// ErrDaemonUnavailable is the class of the failures to reach the Docker daemon
+	ErrDaemonUnavailable = errors.New("docker daemon is unavailable")
+)
+
+// portBindingFailures are the messages of the daemon when it cannot publish the ports of a container
+var portBindingFailures = []string{
+	"port is already allocated",
+	"address already in use",
+	"ports are not available",
+}
+
+// ContainerError is a failure of a container classified by one of ErrImagePull, ErrImageLoad, ErrPortBinding,
+// ErrWaitTimeout and ErrDaemonUnavailable, with the container and the image it happened to. It can be retrieved with
+// errors.As.
+type ContainerError struct {
+	Class       error  // class of the failure, e.g. ErrImagePull
+	ContainerID string // ID of the container, empty if it was not created
+	Image       string // image of the container
+	Err         error  // error that caused the failure
+}
+
+func (e *ContainerError) Error() string {
+	if e.ContainerID == "" {
+		return fmt.Sprintf("%s: image %s: %s", e.Class, e.Image, e.Err)
+	}
+	return fmt.Sprintf("%s: container %s (image %s): %s", e.Class, e.ContainerID, e.Image, e.Err)
+}
+
+// Unwrap returns the error that caused the failure
+func (e *ContainerError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the class of the failure
+func (e *ContainerError) Is(target error) bool {
+	return target == e.Class
+}
+
+// classifyError returns err as a ContainerError of the given class, or of ErrDaemonUnavailable if the daemon could not
+// be reached. err is returned as is if it is nil, already classified, or if class is nil and the daemon was reached.
+func classifyError(err error, class error, containerID string, image string) error {
+	if err == nil {
+		return nil
+	}
+
+	var classified *ContainerError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	if client.IsErrConnectionFailed(err) {
+		class = ErrDaemonUnavailable
+	}
+	if class == nil {
+		return err
+	}
+
+	return &ContainerError{Class: class, ContainerID: containerID, Image: image, Err: err}
+}
+
+// isPortBindingError reports whether err is the failure of the daemon to publish the ports of a container
+func isPortBindingError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, failure := range portBindingFailures {
+		if strings.Contains(msg, failure) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	cause := errors.New("manifest unknown")
+
+	err := classifyError(cause, ErrImagePull, "", "docker.io/alpine:404")
+
+	assert.True(t, errors.Is(err, ErrImagePull))
+	assert.False(t, errors.Is(err, ErrPortBinding))
+	assert.True(t, errors.Is(err, cause), "the cause is wrapped")
+	assert.EqualError(t, err, "failed to pull image: image docker.io/alpine:404: manifest unknown")
+
+	var containerErr *ContainerError
+	require.True(t, errors.As(fmt.Errorf("%w: failed to create container", err), &containerErr))
+	assert.Equal(t, "docker.io/alpine:404", containerErr.Image)
+}
+
+func TestClassifyErrorDaemonUnavailable(t *testing.T) {
+	cause := client.ErrorConnectionFailed("unix:///var/run/docker.sock")
+
+	err := classifyError(fmt.Errorf("%w: failed to start", cause), nil, "0123456789ab", "docker.io/alpine")
+
+	assert.True(t, errors.Is(err, ErrDaemonUnavailable))
+	assert.EqualError(t, err, "docker daemon is unavailable: container 0123456789ab (image docker.io/alpine): "+
+		"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?: failed to start")
+}
+
+func TestClassifyErrorKeepsClass(t *testing.T) {
+	assert.Nil(t, classifyError(nil, ErrImagePull, "", "docker.io/alpine"))
+
+	unclassified := errors.New("no such container")
+	assert.Equal(t, unclassified, classifyError(unclassified, nil, "0123456789ab", "docker.io/alpine"))
+
+	classified := classifyError(context.DeadlineExceeded, ErrWaitTimeout, "0123456789ab", "docker.io/alpine")
+	err := classifyError(fmt.Errorf("%w: failed to start container", classified), nil, "0123456789ab", "docker.io/alpine")
+	assert.True(t, errors.Is(err, ErrWaitTimeout), "the class of the first classification is kept")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestIsPortBindingError(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected bool
+	}{
+		{"driver failed programming external connectivity on endpoint db: Bind for 0.0.0.0:5432 failed: port is already allocated", true},
+		{"driver failed programming external connectivity on endpoint db: listen tcp4 0.0.0.0:5432: bind: address already in use", true},
+		{"Ports are not available: exposing port TCP 0.0.0.0:5432 -> 0.0.0.0:0: listen tcp 0.0.0.0:5432: bind: Only one usage of each socket address is normally permitted.", true},
+		{"OCI runtime create failed: executable file not found in $PATH", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isPortBindingError(errors.New(tt.msg)), tt.msg)
+	}
+}
+
+func TestImagePullError(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/testcontainers/does-not-exist:404",
+		},
+		Started: true,
+	})
+	require.Error(t, err)
+
+	assert.True(t, errors.Is(err, ErrImagePull))
+	var containerErr *ContainerError
+	require.True(t, errors.As(err, &containerErr))
+	assert.Equal(t, "docker.io/testcontainers/does-not-exist:404", containerErr.Image)
+}
@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"github.com/docker/docker/api/types"
+)
+
+// ProcessOptions defines how a command is executed in a container and how its output is captured
+type ProcessOptions struct {
+	ExecConfig types.ExecConfig
+	// Multiplexed strips the stream headers from the output, returning stdout and stderr as a single stream
+	Multiplexed bool
+}
+
+// NewProcessOptions returns the default options for the given command: both stdout and stderr are attached
+func NewProcessOptions(cmd []string) *ProcessOptions {
+	return &ProcessOptions{
+		ExecConfig: types.ExecConfig{
+			Cmd:          cmd,
+			Detach:       false,
+			AttachStdout: true,
+			AttachStderr: true,
+		},
+	}
+}
+
+// ProcessOption defines a common interface to modify the options of a process executed in a container
+type ProcessOption interface {
+	Apply(opts *ProcessOptions)
+}
+
+// ProcessOptionFunc is a shorthand to implement the ProcessOption interface
+type ProcessOptionFunc func(opts *ProcessOptions)
+
+// Apply implements ProcessOption.Apply
+func (fn ProcessOptionFunc) Apply(opts *ProcessOptions) {
+	fn(opts)
+}
+
+// WithUser runs the command as the given user, in the user[:group] format
+func WithUser(user string) ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		opts.ExecConfig.User = user
+	})
+}
+
+// WithWorkingDir runs the command from the given directory
+func WithWorkingDir(workingDir string) ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		opts.ExecConfig.WorkingDir = workingDir
+	})
+}
+
+// WithEnv adds environment variables, in the KEY=VALUE format, to the command
+func WithEnv(env []string) ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		opts.ExecConfig.Env = append(opts.ExecConfig.Env, env...)
+	})
+}
+
+// WithoutStdout does not capture the standard output of the command
+func WithoutStdout() ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		opts.ExecConfig.AttachStdout = false
+	})
+}
+
+// WithoutStderr does not capture the standard error of the command
+func WithoutStderr() ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		opts.ExecConfig.AttachStderr = false
+	})
+}
+
+// Multiplexed returns the output of the command without the stream headers,
+// so stdout and stderr can be read as plain text from a single reader
+func Multiplexed() ProcessOption {
+	return ProcessOptionFunc(func(opts *ProcessOptions) {
+		opts.Multiplexed = true
+	})
+}
@@ -0,0 +1,48 @@
+package exec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewProcessOptions(t *testing.T) {
+	opts := NewProcessOptions([]string{"echo", "hello"})
+
+	if !opts.ExecConfig.AttachStdout || !opts.ExecConfig.AttachStderr {
+		t.Fatal("expected stdout and stderr to be attached by default")
+	}
+	if opts.Multiplexed {
+		t.Fatal("expected the output not to be multiplexed by default")
+	}
+}
+
+func TestProcessOptions(t *testing.T) {
+	opts := NewProcessOptions([]string{"pwd"})
+
+	for _, o := range []ProcessOption{
+		WithUser("nobody"),
+		WithWorkingDir("/tmp"),
+		WithEnv([]string{"FOO=foo"}),
+		WithEnv([]string{"BAR=bar"}),
+		WithoutStderr(),
+		Multiplexed(),
+	} {
+		o.Apply(opts)
+	}
+
+	if opts.ExecConfig.User != "nobody" {
+		t.Fatalf("unexpected user %q", opts.ExecConfig.User)
+	}
+	if opts.ExecConfig.WorkingDir != "/tmp" {
+		t.Fatalf("unexpected working dir %q", opts.ExecConfig.WorkingDir)
+	}
+	if !reflect.DeepEqual(opts.ExecConfig.Env, []string{"FOO=foo", "BAR=bar"}) {
+		t.Fatalf("unexpected env %v", opts.ExecConfig.Env)
+	}
+	if !opts.ExecConfig.AttachStdout || opts.ExecConfig.AttachStderr {
+		t.Fatal("expected only stdout to be attached")
+	}
+	if !opts.Multiplexed {
+		t.Fatal("expected the output to be multiplexed")
+	}
+}
@@ -0,0 +1,60 @@
+package testcontainers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultFakeTimeLibrary is the path of libfaketime installed by the faketime package of Debian and Ubuntu on amd64
+const DefaultFakeTimeLibrary = "/usr/lib/x86_64-linux-gnu/faketime/libfaketime.so.1"
+
+// fakeTimeFormat is the absolute time format understood by libfaketime
+const fakeTimeFormat = "2006-01-02 15:04:05"
+
+// WithClockSkew shifts the clock of the container by the given offset, which might be negative,
+// while letting it tick at the normal pace.
+// The clock is faked by preloading libfaketime, which must be available in the image, see WithFakeTimeLibrary.
+// It has no effect on statically linked binaries, like most Go programs.
+func WithClockSkew(offset time.Duration) CustomizeRequestOption {
+	return withFakeTime(fmt.Sprintf("%+ds", int64(offset/time.Second)))
+}
+
+// WithFrozenClock stops the clock of the container at the given time, expressed in UTC
+// which is the timezone of most images. Like WithClockSkew, it requires libfaketime in the image.
+func WithFrozenClock(t time.Time) CustomizeRequestOption {
+	return withFakeTime(t.UTC().Format(fakeTimeFormat))
+}
+
+// WithFakeTimeLibrary overrides the path of libfaketime in the image, DefaultFakeTimeLibrary by default
+func WithFakeTimeLibrary(path string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		if req.Env == nil {
+			req.Env = map[string]string{}
+		}
+
+		preloads := []string{path}
+		for _, lib := range strings.Split(req.Env["LD_PRELOAD"], ":") {
+			if lib != "" && !strings.Contains(lib, "libfaketime") {
+				preloads = append(preloads, lib)
+			}
+		}
+		req.Env["LD_PRELOAD"] = strings.Join(preloads, ":")
+	}
+}
+
+func withFakeTime(spec string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		if req.Env == nil {
+			req.Env = map[string]string{}
+		}
+
+		req.Env["FAKETIME"] = spec
+		// timeouts and sleeps rely on the monotonic clock, which must keep its pace
+		req.Env["FAKETIME_DONT_FAKE_MONOTONIC"] = "1"
+
+		if !strings.Contains(req.Env["LD_PRELOAD"], "libfaketime") {
+			WithFakeTimeLibrary(DefaultFakeTimeLibrary)(req)
+		}
+	}
+}
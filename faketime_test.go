@@ -0,0 +1,51 @@
+package testcontainers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithClockSkew(t *testing.T) {
+	tests := []struct {
+		offset time.Duration
+		want   string
+	}{
+		{offset: 90 * time.Minute, want: "+5400s"},
+		{offset: -48 * time.Hour, want: "-172800s"},
+		{offset: 0, want: "+0s"},
+	}
+
+	for _, tt := range tests {
+		req := GenericContainerRequest{}
+		WithClockSkew(tt.offset)(&req)
+
+		assert.Equal(t, tt.want, req.Env["FAKETIME"])
+		assert.Equal(t, "1", req.Env["FAKETIME_DONT_FAKE_MONOTONIC"])
+		assert.Equal(t, DefaultFakeTimeLibrary, req.Env["LD_PRELOAD"])
+	}
+}
+
+func TestWithFrozenClock(t *testing.T) {
+	req := GenericContainerRequest{}
+	frozen := time.Date(2030, time.January, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+	WithFrozenClock(frozen)(&req)
+
+	assert.Equal(t, "2030-01-02 14:04:05", req.Env["FAKETIME"])
+}
+
+func TestWithFakeTimeLibrary(t *testing.T) {
+	req := GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			Env: map[string]string{"LD_PRELOAD": "/usr/lib/libjemalloc.so"},
+		},
+	}
+	WithFakeTimeLibrary("/usr/lib/faketime/libfaketime.so.1")(&req)
+	WithClockSkew(time.Hour)(&req)
+
+	assert.Equal(t, "/usr/lib/faketime/libfaketime.so.1:/usr/lib/libjemalloc.so", req.Env["LD_PRELOAD"])
+
+	WithFakeTimeLibrary("/opt/libfaketimeMT.so.1")(&req)
+	assert.Equal(t, "/opt/libfaketimeMT.so.1:/usr/lib/libjemalloc.so", req.Env["LD_PRELOAD"])
+}
@@ -54,6 +54,13 @@ func GenericContainer(ctx context.Context, req GenericContainerRequest) (Contain
 		return nil, err
 	}
 
+	// the container counts against the limit of concurrent starts until it is started
+	release, err := newStartLimiter(configureTC()).acquire(ctx, req.StartCost)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	var c Container
 	if req.Reuse {
 		c, err = provider.ReuseOrCreateContainer(ctx, req.ContainerRequest)
@@ -61,12 +68,12 @@ func GenericContainer(ctx context.Context, req GenericContainerRequest) (Contain
 		c, err = provider.CreateContainer(ctx, req.ContainerRequest)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to create container", err)
+		return nil, fmt.Errorf("%w: failed to create container", classifyError(err, nil, "", req.Image))
 	}
 
 	if req.Started && !c.IsRunning() {
 		if err := c.Start(ctx); err != nil {
-			return c, fmt.Errorf("%w: failed to start container", err)
+			return c, fmt.Errorf("%w: failed to start container", classifyError(err, nil, c.GetContainerID(), req.Image))
 		}
 	}
 	return c, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -97,3 +98,28 @@ func TestGenericReusableContainer(t *testing.T) {
 		})
 	}
 }
+
+func TestGenericReusableContainerMaxAge(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+	provider.config.MaxContainerAge = time.Nanosecond
+
+	req := ContainerRequest{
+		Image:        nginxAlpineImage,
+		ExposedPorts: []string{nginxDefaultPort},
+		WaitingFor:   wait.ForListeningPort(nginxDefaultPort),
+		Name:         reusableContainerName + "_max_age",
+	}
+
+	n1, err := provider.ReuseOrCreateContainer(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, n1.Start(ctx))
+
+	n2, err := provider.ReuseOrCreateContainer(ctx, req)
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, n2)
+
+	require.NotEqual(t, n1.GetContainerID(), n2.GetContainerID(), "the container older than the maximum age is recreated")
+}
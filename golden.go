@@ -0,0 +1,159 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// envUpdateGolden rewrites the golden files with the actual output when set to true
+const envUpdateGolden = "TESTCONTAINERS_UPDATE_GOLDEN"
+
+const defaultGoldenDir = "testdata"
+
+const timestampPattern = `\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`
+
+var (
+	leadingTimestamps = regexp.MustCompile(`(?m)^\[?` + timestampPattern + `\]?[ \t]*`)
+	timestamps        = regexp.MustCompile(timestampPattern)
+	uuids             = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+)
+
+// Mask replaces the values of an output matching a pattern, e.g. the host name or a port of the environment,
+// so that it is compared with a golden file regardless of where it was produced
+type Mask struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// MaskValue masks the occurrences of a literal value, e.g. the mapped port of a container
+func MaskValue(value string, replacement string) Mask {
+	return Mask{Pattern: regexp.MustCompile(regexp.QuoteMeta(value)), Replacement: replacement}
+}
+
+// MaskPattern masks the matches of a regular expression, which may refer to its groups in the replacement
+func MaskPattern(pattern string, replacement string) Mask {
+	return Mask{Pattern: regexp.MustCompile(pattern), Replacement: replacement}
+}
+
+// Golden compares the normalized output of containers with golden files, to catch regressions of the behavior of
+// a CLI or of an image. The output is normalized by stripping the timestamps the lines start with, replacing the
+// other timestamps with <timestamp> and UUIDs with <uuid>, then applying the masks. Set Update, or the
+// TESTCONTAINERS_UPDATE_GOLDEN environment variable to true, to write the actual output to the golden files.
+type Golden struct {
+	Dir    string // directory of the golden files, testdata if empty
+	Masks  []Mask // masks of the values specific to the environment, applied in order after the default ones
+	Update bool   // writes the golden files instead of comparing them
+}
+
+// Normalize returns the output with line endings, timestamps and the values specific to the environment normalized
+func (g Golden) Normalize(output []byte) string {
+	s := strings.ReplaceAll(string(output), "\r\n", "\n")
+	s = leadingTimestamps.ReplaceAllString(s, "")
+	s = timestamps.ReplaceAllString(s, "<timestamp>")
+	s = uuids.ReplaceAllString(s, "<uuid>")
+
+	for _, m := range g.Masks {
+		s = m.Pattern.ReplaceAllString(s, m.Replacement)
+	}
+
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// Assert compares the normalized output with the golden file of the given name, e.g. the output of
+// Container.ExecWithResult, failing the test if they differ or if the golden file is missing
+func (g Golden) Assert(t testing.TB, name string, output []byte) {
+	t.Helper()
+
+	actual := g.Normalize(output)
+	path := filepath.Join(g.dir(), name+".golden")
+
+	if g.update() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create the directory of golden file %s: %s", path, err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatalf("failed to update golden file %s: %s", path, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("golden file %s is missing, set %s=true to create it, the actual output is:\n%s", path, envUpdateGolden, actual)
+			return
+		}
+		t.Fatalf("failed to read golden file %s: %s", path, err)
+	}
+
+	if string(expected) != actual {
+		t.Errorf("output differs from golden file %s at line %d, set %s=true to update it\n--- expected\n%s--- actual\n%s",
+			path, firstDifferentLine(string(expected), actual), envUpdateGolden, expected, actual)
+	}
+}
+
+// AssertLogs compares the normalized logs of the container, stdout and stderr, with the golden file of the given
+// name. The ID and the host name of the container are masked as <container>.
+func (g Golden) AssertLogs(ctx context.Context, t testing.TB, c Container, name string) {
+	t.Helper()
+
+	rc, err := c.Logs(ctx)
+	if err != nil {
+		t.Fatalf("failed to read the logs of container %s: %s", c.GetContainerID(), err)
+	}
+	defer rc.Close()
+
+	logs, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read the logs of container %s: %s", c.GetContainerID(), err)
+	}
+
+	id := c.GetContainerID()
+	masks := []Mask{MaskValue(id, "<container>")}
+	if len(id) > 12 {
+		// the default host name of the container
+		masks = append(masks, MaskValue(id[:12], "<container>"))
+	}
+	g.Masks = append(masks, g.Masks...)
+
+	g.Assert(t, name, logs)
+}
+
+func (g Golden) dir() string {
+	if g.Dir == "" {
+		return defaultGoldenDir
+	}
+	return g.Dir
+}
+
+func (g Golden) update() bool {
+	return g.Update || os.Getenv(envUpdateGolden) == "true"
+}
+
+// firstDifferentLine returns the number of the first line that differs between the outputs, starting at 1
+func firstDifferentLine(expected string, actual string) int {
+	e := strings.Split(expected, "\n")
+	a := strings.Split(actual, "\n")
+
+	for i := 0; i < len(e) && i < len(a); i++ {
+		if e[i] != a[i] {
+			return i + 1
+		}
+	}
+	if len(e) < len(a) {
+		return len(e) + 1
+	}
+	return len(a) + 1
+}
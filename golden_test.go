@@ -0,0 +1,99 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB records the failures of a golden assertion instead of failing the test
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestGoldenNormalize(t *testing.T) {
+	g := Golden{
+		Masks: []Mask{
+			MaskValue("localhost:55012", "<endpoint>"),
+			MaskPattern(`took \d+ms`, "took <duration>"),
+		},
+	}
+
+	output := "2023-03-01T10:15:30.123456Z starting server  \r\n" +
+		"[2023-03-01 10:15:30,123] INFO connected to localhost:55012\n" +
+		"2023/03/01 10:15:31 request 0f8fad5b-d9cb-469f-a165-70867728950e took 12ms at 2023-03-01T10:15:31+01:00\n\n"
+
+	assert.Equal(t, "starting server\n"+
+		"INFO connected to <endpoint>\n"+
+		"request <uuid> took <duration> at <timestamp>\n", g.Normalize([]byte(output)))
+}
+
+func TestGoldenAssert(t *testing.T) {
+	t.Setenv(envUpdateGolden, "")
+	g := Golden{Dir: t.TempDir()}
+
+	missing := &recordingTB{TB: t}
+	g.Assert(missing, "version", []byte("v1.2.3\n"))
+	require.Len(t, missing.errors, 1)
+	assert.Contains(t, missing.errors[0], "version.golden is missing, set TESTCONTAINERS_UPDATE_GOLDEN=true to create it")
+
+	g.Update = true
+	g.Assert(t, "version", []byte("v1.2.3\n"))
+
+	content, err := os.ReadFile(filepath.Join(g.Dir, "version.golden"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3\n", string(content))
+
+	g.Update = false
+	g.Assert(t, "version", []byte("v1.2.3"))
+
+	changed := &recordingTB{TB: t}
+	g.Assert(changed, "version", []byte("v1.2.3\nv1.2.4\n"))
+	require.Len(t, changed.errors, 1)
+	assert.Contains(t, changed.errors[0], "at line 2")
+}
+
+func TestGoldenAssertLogs(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sh", "-c", `echo "$(date -u +%Y-%m-%dT%H:%M:%SZ) started on $(hostname)"; echo done >&2`},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	state, err := c.State(ctx)
+	require.NoError(t, err)
+	for state.Running {
+		state, err = c.State(ctx)
+		require.NoError(t, err)
+	}
+
+	g := Golden{Dir: t.TempDir()}
+	require.NoError(t, os.WriteFile(filepath.Join(g.Dir, "startup.golden"), []byte("started on <container>\ndone\n"), 0o644))
+
+	g.AssertLogs(ctx, t, c, "startup")
+}
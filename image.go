@@ -0,0 +1,65 @@
+package testcontainers
+
+import (
+	"strings"
+)
+
+// ImageSubstitutor rewrites the name of an image before it is pulled, e.g. to pull the images of Docker Hub through
+// the registry mirror of a corporate network. It applies to the ImageSubstitutors of a ContainerRequest, and to the
+// services of a compose stack with WithImageSubstitutor.
+type ImageSubstitutor interface {
+	Substitute(image string) string
+}
+
+// ImageSubstitutorFunc adapts a function to an ImageSubstitutor
+type ImageSubstitutorFunc func(image string) string
+
+// Substitute calls the function
+func (f ImageSubstitutorFunc) Substitute(image string) string {
+	return f(image)
+}
+
+// DockerHubMirror returns a substitutor pulling the images of Docker Hub from the registry path of a mirror,
+// e.g. postgres:15 becomes registry.corp/mirror/postgres:15 with the registry.corp/mirror prefix, and
+// docker.io/bitnami/kafka becomes registry.corp/mirror/bitnami/kafka. The images of other registries are kept.
+func DockerHubMirror(prefix string) ImageSubstitutor {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return ImageSubstitutorFunc(func(image string) string {
+		name, ok := dockerHubName(image)
+		if !ok {
+			return image
+		}
+		return prefix + "/" + name
+	})
+}
+
+// dockerHubName returns the name of the image relative to Docker Hub, without the library namespace of the official
+// images, and whether the image is hosted by Docker Hub. Like Docker, the first component of the name is a registry
+// if it contains a dot or a colon, or is localhost.
+func dockerHubName(image string) (string, bool) {
+	if image == "" {
+		return "", false
+	}
+
+	i := strings.IndexRune(image, '/')
+	if i >= 0 {
+		registry := image[:i]
+		switch {
+		case registry == "docker.io" || registry == "index.docker.io" || registry == "registry-1.docker.io":
+			image = image[i+1:]
+		case strings.ContainsAny(registry, ".:") || registry == "localhost":
+			return "", false
+		}
+	}
+
+	return strings.TrimPrefix(image, "library/"), true
+}
+
+// substituteImage applies the substitutors to the image in order
+func substituteImage(image string, substitutors []ImageSubstitutor) string {
+	for _, s := range substitutors {
+		image = s.Substitute(image)
+	}
+	return image
+}
@@ -0,0 +1,220 @@
+package testcontainers
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// dockerArchiveManifest lists the images of a docker save archive
+	dockerArchiveManifest = "manifest.json"
+	// ociArchiveIndex lists the manifests of an OCI image layout
+	ociArchiveIndex = "index.json"
+	// dockerManifestMediaType is the media type of the image manifests of Docker, also found in OCI image layouts
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// dockerArchiveImage is an image of the manifest.json of a docker save archive
+type dockerArchiveImage struct {
+	Config   string   // path of the configuration of the image in the archive
+	RepoTags []string // tags of the image, loaded with it
+}
+
+// loadImageArchive returns the ID of the image of the archive of the request, loading the archive unless the image is
+// already present, e.g. loaded by a previous test. The ID is checked against the one expected by the request before
+// the archive is loaded, and against the image present once it is loaded.
+func (p *DockerProvider) loadImageArchive(ctx context.Context, req *ContainerRequest) (string, error) {
+	id, err := imageArchiveID(req.ImageArchive)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to read image archive %s", err, req.ImageArchive)
+	}
+	if req.ImageArchiveID != "" && id.String() != req.ImageArchiveID {
+		return "", fmt.Errorf("image archive %s holds image %s instead of %s", req.ImageArchive, id, req.ImageArchiveID)
+	}
+
+	_, _, err = p.client.ImageInspectWithRaw(ctx, id.String())
+	if err == nil {
+		return id.String(), nil
+	}
+	if !client.IsErrNotFound(err) {
+		return "", err
+	}
+
+	archive, err := os.Open(req.ImageArchive)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	resp, err := p.client.ImageLoad(ctx, archive, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// the load finishes at EOF of the response, which reports the failures of the daemon
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, io.Discard, 0, false, nil); err != nil {
+		return "", err
+	}
+
+	_, _, err = p.client.ImageInspectWithRaw(ctx, id.String())
+	if client.IsErrNotFound(err) {
+		return "", fmt.Errorf("image %s of archive %s is missing once the archive is loaded", id, req.ImageArchive)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// imageArchiveID returns the ID of the image of an archive, which is the digest of its configuration. The archive is
+// either the one of docker save, listing its images in manifest.json, or an OCI image layout, listing its manifests in
+// index.json, and may be compressed with gzip. It must hold a single image.
+func imageArchiveID(archivePath string) (digest.Digest, error) {
+	files, err := readArchiveFiles(archivePath, dockerArchiveManifest, ociArchiveIndex)
+	if err != nil {
+		return "", err
+	}
+
+	if manifest, ok := files[dockerArchiveManifest]; ok {
+		var images []dockerArchiveImage
+		if err := json.Unmarshal(manifest, &images); err != nil {
+			return "", fmt.Errorf("%w: invalid %s", err, dockerArchiveManifest)
+		}
+		if len(images) != 1 {
+			return "", fmt.Errorf("%s lists %d images instead of one", dockerArchiveManifest, len(images))
+		}
+		return dockerArchiveConfigDigest(images[0].Config)
+	}
+
+	index, ok := files[ociArchiveIndex]
+	if !ok {
+		return "", fmt.Errorf("neither %s nor %s found, not an image archive", dockerArchiveManifest, ociArchiveIndex)
+	}
+
+	var idx v1.Index
+	if err := json.Unmarshal(index, &idx); err != nil {
+		return "", fmt.Errorf("%w: invalid %s", err, ociArchiveIndex)
+	}
+	if len(idx.Manifests) != 1 {
+		return "", fmt.Errorf("%s lists %d manifests instead of one", ociArchiveIndex, len(idx.Manifests))
+	}
+	desc := idx.Manifests[0]
+	if desc.MediaType != v1.MediaTypeImageManifest && desc.MediaType != dockerManifestMediaType {
+		return "", fmt.Errorf("%s lists a manifest of type %s instead of an image manifest, e.g. an index of several platforms", ociArchiveIndex, desc.MediaType)
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("%w: invalid manifest digest in %s", err, ociArchiveIndex)
+	}
+
+	blob := ociBlobPath(desc.Digest)
+	files, err = readArchiveFiles(archivePath, blob)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := files[blob]; !ok {
+		return "", fmt.Errorf("manifest %s listed in %s not found", desc.Digest, ociArchiveIndex)
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(files[blob], &manifest); err != nil {
+		return "", fmt.Errorf("%w: invalid manifest %s", err, desc.Digest)
+	}
+	if err := manifest.Config.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("%w: invalid config digest in manifest %s", err, desc.Digest)
+	}
+
+	return manifest.Config.Digest, nil
+}
+
+// dockerArchiveConfigDigest returns the digest of the configuration of an image of a docker save archive from its
+// path, either <hex>.json in the archives of the legacy format or blobs/<algorithm>/<hex>
+func dockerArchiveConfigDigest(config string) (digest.Digest, error) {
+	dir, file := path.Split(config)
+
+	algorithm := digest.SHA256
+	if dir != "" {
+		algorithm = digest.Algorithm(path.Base(dir))
+	}
+
+	d := digest.NewDigestFromEncoded(algorithm, strings.TrimSuffix(file, ".json"))
+	if err := d.Validate(); err != nil {
+		return "", fmt.Errorf("%w: invalid config %q in %s", err, config, dockerArchiveManifest)
+	}
+	return d, nil
+}
+
+// ociBlobPath returns the path of a blob in an OCI image layout
+func ociBlobPath(d digest.Digest) string {
+	return path.Join("blobs", d.Algorithm().String(), d.Encoded())
+}
+
+// readArchiveFiles returns the content of the named files of a tar archive, compressed with gzip or not, by name. The
+// files missing in the archive are missing in the result.
+func readArchiveFiles(archivePath string, names ...string) (map[string][]byte, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var r io.Reader = br
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	files := make(map[string][]byte, len(names))
+	tr := tar.NewReader(r)
+	for len(files) < len(wanted) {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(hdr.Name)
+		if !hdr.FileInfo().Mode().IsRegular() || !wanted[name] {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+
+	return files, nil
+}
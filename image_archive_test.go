@@ -0,0 +1,168 @@
+package testcontainers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	archiveConfigHex   = "4f2c8b5a1e6d9c3b7a0f5e8d2c1b4a7f6e9d0c3b2a5f8e1d4c7b0a3f6e9d2c5b"
+	archiveManifestHex = "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b"
+)
+
+// writeTestArchive writes a tar archive of the given files, compressed with gzip or not
+func writeTestArchive(t *testing.T, compressed bool, files map[string]string) string {
+	archivePath := filepath.Join(t.TempDir(), "image.tar")
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var w io.Writer = f
+	if compressed {
+		gz := gzip.NewWriter(f)
+		defer gz.Close()
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	return archivePath
+}
+
+func TestImageArchiveID(t *testing.T) {
+	ociIndex := `{"schemaVersion":2,"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:` + archiveManifestHex + `","size":123}]}`
+	ociManifest := `{"schemaVersion":2,"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:` + archiveConfigHex + `","size":456}}`
+
+	tests := []struct {
+		name       string
+		compressed bool
+		files      map[string]string
+		id         string
+		err        string
+	}{
+		{
+			name:  "docker save",
+			files: map[string]string{"manifest.json": `[{"Config":"` + archiveConfigHex + `.json","RepoTags":["my-app:ci"]}]`},
+			id:    "sha256:" + archiveConfigHex,
+		},
+		{
+			name: "docker save with blobs",
+			files: map[string]string{
+				"manifest.json": `[{"Config":"blobs/sha256/` + archiveConfigHex + `","RepoTags":["my-app:ci"]}]`,
+				"index.json":    ociIndex,
+			},
+			id: "sha256:" + archiveConfigHex,
+		},
+		{
+			name: "OCI image layout",
+			files: map[string]string{
+				"oci-layout":                         `{"imageLayoutVersion":"1.0.0"}`,
+				"index.json":                         ociIndex,
+				"blobs/sha256/" + archiveManifestHex: ociManifest,
+			},
+			id: "sha256:" + archiveConfigHex,
+		},
+		{
+			name:       "compressed OCI image layout",
+			compressed: true,
+			files: map[string]string{
+				"./index.json":                         ociIndex,
+				"./blobs/sha256/" + archiveManifestHex: ociManifest,
+			},
+			id: "sha256:" + archiveConfigHex,
+		},
+		{
+			name:  "several images",
+			files: map[string]string{"manifest.json": `[{"Config":"a.json"},{"Config":"b.json"}]`},
+			err:   "manifest.json lists 2 images instead of one",
+		},
+		{
+			name: "index of several platforms",
+			files: map[string]string{
+				"index.json": `{"schemaVersion":2,"manifests":[{"mediaType":"application/vnd.oci.image.index.v1+json","digest":"sha256:` + archiveManifestHex + `","size":123}]}`,
+			},
+			err: "index.json lists a manifest of type application/vnd.oci.image.index.v1+json instead of an image manifest, e.g. an index of several platforms",
+		},
+		{
+			name:  "missing manifest",
+			files: map[string]string{"index.json": ociIndex},
+			err:   "manifest sha256:" + archiveManifestHex + " listed in index.json not found",
+		},
+		{
+			name:  "not an image archive",
+			files: map[string]string{"Dockerfile": "FROM alpine"},
+			err:   "neither manifest.json nor index.json found, not an image archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := imageArchiveID(writeTestArchive(t, tt.compressed, tt.files))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.id, id.String())
+		})
+	}
+}
+
+func TestContainerFromImageArchive(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+
+	image := "docker.io/alpine:latest"
+	require.NoError(t, provider.attemptToPullImage(ctx, image, types.ImagePullOptions{}))
+	inspect, _, err := provider.client.ImageInspectWithRaw(ctx, image)
+	require.NoError(t, err)
+
+	saved, err := provider.client.ImageSave(ctx, []string{image})
+	require.NoError(t, err)
+	defer saved.Close()
+	archivePath := filepath.Join(t.TempDir(), "alpine.tar")
+	archive, err := os.Create(archivePath)
+	require.NoError(t, err)
+	_, err = io.Copy(archive, saved)
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			ImageArchive:   archivePath,
+			ImageArchiveID: inspect.ID,
+			Cmd:            []string{"echo", "loaded"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+	assert.Equal(t, inspect.ID, c.(*DockerContainer).Image)
+
+	_, err = GenericContainer(ctx, GenericContainerRequest{
+		ContainerRequest: ContainerRequest{
+			ImageArchive:   archivePath,
+			ImageArchiveID: "sha256:" + archiveConfigHex,
+		},
+	})
+	assert.ErrorIs(t, err, ErrImageLoad)
+}
@@ -0,0 +1,62 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerHubMirror(t *testing.T) {
+	mirror := DockerHubMirror("registry.corp/mirror/")
+
+	tests := map[string]string{
+		"postgres:15":                                "registry.corp/mirror/postgres:15",
+		"library/postgres:15":                        "registry.corp/mirror/postgres:15",
+		"docker.io/postgres:15":                      "registry.corp/mirror/postgres:15",
+		"docker.io/library/redis@sha256:0123abcd":    "registry.corp/mirror/redis@sha256:0123abcd",
+		"docker.io/bitnami/kafka:3.4":                "registry.corp/mirror/bitnami/kafka:3.4",
+		"bitnami/kafka":                              "registry.corp/mirror/bitnami/kafka",
+		"quay.io/keycloak/keycloak:21.0":             "quay.io/keycloak/keycloak:21.0",
+		"localhost/app:latest":                       "localhost/app:latest",
+		"localhost:5000/app":                         "localhost:5000/app",
+		"mcr.microsoft.com/mssql/server:2022-latest": "mcr.microsoft.com/mssql/server:2022-latest",
+		"": "",
+	}
+
+	for image, expected := range tests {
+		assert.Equal(t, expected, mirror.Substitute(image), image)
+	}
+}
+
+func TestContainerRequestImageSubstitutors(t *testing.T) {
+	ctx := context.Background()
+
+	var substituted string
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine:does-not-exist",
+			Cmd:   []string{"sleep", "60"},
+			ImageSubstitutors: []ImageSubstitutor{
+				ImageSubstitutorFunc(func(image string) string {
+					return image[:len(image)-len(":does-not-exist")]
+				}),
+				ImageSubstitutorFunc(func(image string) string {
+					substituted = image
+					return image
+				}),
+			},
+		},
+		Started: true,
+	}
+
+	c, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	assert.Equal(t, "docker.io/alpine", substituted)
+}
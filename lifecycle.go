@@ -0,0 +1,79 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContainerHook is a function executed at a given point of the lifecycle of a container
+type ContainerHook func(ctx context.Context, container Container) error
+
+// ContainerRequestHook is a function executed before the container is created, which may complete its request
+type ContainerRequestHook func(ctx context.Context, req *ContainerRequest) error
+
+// ContainerLifecycleHooks groups the hooks executed during the lifecycle of a container.
+// Each group of hooks is executed in order, the groups in the order they are defined in the request.
+type ContainerLifecycleHooks struct {
+	PreCreates    []ContainerRequestHook // executed before the container is created, once the options are applied
+	PostCreates   []ContainerHook        // executed once the container is created, before it is started
+	PostReadies   []ContainerHook        // executed once the container is started and its wait strategy succeeded
+	PreTerminates []ContainerHook        // executed before the container is removed, while its file system still exists
+}
+
+// WithLifecycleHooks appends the given hooks to the ones of the request
+func WithLifecycleHooks(hooks ...ContainerLifecycleHooks) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		req.LifecycleHooks = append(req.LifecycleHooks, hooks...)
+	}
+}
+
+// runPreCreateHooks executes the pre-create hooks of the request, stopping at the first failure
+func runPreCreateHooks(ctx context.Context, req *ContainerRequest) error {
+	for _, lifecycleHooks := range req.LifecycleHooks {
+		for _, hook := range lifecycleHooks.PreCreates {
+			if err := hook(ctx, req); err != nil {
+				return fmt.Errorf("%w: pre-create hook failed", err)
+			}
+		}
+	}
+	return nil
+}
+
+// runPostCreateHooks executes the post-create hooks, stopping at the first failure
+func (c *DockerContainer) runPostCreateHooks(ctx context.Context) error {
+	for _, lifecycleHooks := range c.lifecycleHooks {
+		for _, hook := range lifecycleHooks.PostCreates {
+			if err := hook(ctx, c); err != nil {
+				return fmt.Errorf("%w: post-create hook failed", err)
+			}
+		}
+	}
+	return nil
+}
+
+// runPostReadyHooks executes the post-ready hooks, stopping at the first failure
+// as the container is not in the state the next hooks expect
+func (c *DockerContainer) runPostReadyHooks(ctx context.Context) error {
+	for _, lifecycleHooks := range c.lifecycleHooks {
+		for _, hook := range lifecycleHooks.PostReadies {
+			if err := hook(ctx, c); err != nil {
+				return fmt.Errorf("%w: post-ready hook failed", err)
+			}
+		}
+	}
+	return nil
+}
+
+// runPreTerminateHooks executes all the pre-terminate hooks, even if some of them fail,
+// so that a failing hook does not prevent the others from releasing their resources
+func (c *DockerContainer) runPreTerminateHooks(ctx context.Context) error {
+	var firstErr error
+	for _, lifecycleHooks := range c.lifecycleHooks {
+		for _, hook := range lifecycleHooks.PreTerminates {
+			if err := hook(ctx, c); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%w: pre-terminate hook failed", err)
+			}
+		}
+	}
+	return firstErr
+}
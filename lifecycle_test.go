@@ -0,0 +1,80 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLifecycleHooks(t *testing.T) {
+	first := ContainerLifecycleHooks{PostCreates: []ContainerHook{func(context.Context, Container) error { return nil }}}
+	second := ContainerLifecycleHooks{PreTerminates: []ContainerHook{func(context.Context, Container) error { return nil }}}
+
+	req := GenericContainerRequest{}
+	WithLifecycleHooks(first)(&req)
+	WithLifecycleHooks(second)(&req)
+
+	assert.Len(t, req.LifecycleHooks, 2)
+	assert.Len(t, req.LifecycleHooks[0].PostCreates, 1)
+	assert.Len(t, req.LifecycleHooks[1].PreTerminates, 1)
+}
+
+func TestRunPostCreateHooks(t *testing.T) {
+	var calls []string
+	hook := func(name string, err error) ContainerHook {
+		return func(ctx context.Context, c Container) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	c := &DockerContainer{
+		lifecycleHooks: []ContainerLifecycleHooks{
+			{PostCreates: []ContainerHook{hook("first", nil), hook("second", nil)}},
+			{PostCreates: []ContainerHook{hook("third", nil)}},
+		},
+	}
+	assert.NoError(t, c.runPostCreateHooks(context.Background()))
+	assert.Equal(t, []string{"first", "second", "third"}, calls)
+
+	calls = nil
+	boom := errors.New("boom")
+	c.lifecycleHooks = []ContainerLifecycleHooks{
+		{PostCreates: []ContainerHook{hook("first", boom)}},
+		{PostCreates: []ContainerHook{hook("second", nil)}},
+	}
+	err := c.runPostCreateHooks(context.Background())
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{"first"}, calls)
+}
+
+func TestRunPreCreateHooks(t *testing.T) {
+	req := &ContainerRequest{
+		Networks: []string{"integration"},
+		LifecycleHooks: []ContainerLifecycleHooks{
+			{PreCreates: []ContainerRequestHook{
+				func(ctx context.Context, req *ContainerRequest) error {
+					// the hooks see the request once all the options are applied
+					req.DNS = append(req.DNS, req.Networks[0]+".dns")
+					return nil
+				},
+			}},
+		},
+	}
+	assert.NoError(t, runPreCreateHooks(context.Background(), req))
+	assert.Equal(t, []string{"integration.dns"}, req.DNS)
+
+	boom := errors.New("boom")
+	called := false
+	req.LifecycleHooks = []ContainerLifecycleHooks{
+		{PreCreates: []ContainerRequestHook{func(context.Context, *ContainerRequest) error { return boom }}},
+		{PreCreates: []ContainerRequestHook{func(context.Context, *ContainerRequest) error {
+			called = true
+			return nil
+		}}},
+	}
+	assert.ErrorIs(t, runPreCreateHooks(context.Background(), req), boom)
+	assert.False(t, called, "the next hooks are not executed")
+}
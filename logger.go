@@ -1,8 +1,11 @@
 package testcontainers
 
 import (
+	"bytes"
 	"log"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -21,7 +24,8 @@ func TestLogger(tb testing.TB) Logging {
 	return testLogger{TB: tb}
 }
 
-// WithLogger is a generic option that implements GenericProviderOption, DockerProviderOption and LocalDockerComposeOption
+// WithLogger is a generic option that implements GenericProviderOption, DockerProviderOption, LocalDockerComposeOption
+// and ComposeStackOption
 // It replaces the global Logging implementation with a user defined one e.g. to aggregate logs from testcontainers
 // with the logs of specific test case
 func WithLogger(logger Logging) LoggerOption {
@@ -46,6 +50,38 @@ func (o LoggerOption) ApplyToLocalCompose(opts *LocalDockerComposeOptions) {
 	opts.Logger = o.logger
 }
 
+func (o LoggerOption) applyToComposeStack(opts *composeStackOptions) {
+	opts.Logger = o.logger
+}
+
+// loggingWriter writes each line written to it to the logger, e.g. to route the output of the Docker CLI
+type loggingWriter struct {
+	logger Logging
+	lock   sync.Mutex
+	buf    []byte
+}
+
+func (w *loggingWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := strings.TrimRight(string(w.buf[:i]), "\r")
+		w.buf = w.buf[i+1:]
+		if line != "" {
+			w.logger.Printf("%s", line)
+		}
+	}
+
+	return len(p), nil
+}
+
 type testLogger struct {
 	testing.TB
 }
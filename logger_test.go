@@ -0,0 +1,27 @@
+package testcontainers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestLoggingWriter(t *testing.T) {
+	logger := &recordingLogger{}
+	w := &loggingWriter{logger: logger}
+
+	_, _ = w.Write([]byte("Pulling nginx\r\nPull"))
+	assert.Equal(t, []string{"Pulling nginx"}, logger.lines)
+
+	_, _ = w.Write([]byte("ed nginx\n\n%d\n"))
+	assert.Equal(t, []string{"Pulling nginx", "Pulled nginx", "%d"}, logger.lines)
+}
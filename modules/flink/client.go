@@ -0,0 +1,211 @@
+package flink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Overview sums up the resources and the jobs of the cluster
+type Overview struct {
+	TaskManagers   int `json:"taskmanagers"`
+	SlotsTotal     int `json:"slots-total"`
+	SlotsAvailable int `json:"slots-available"`
+	JobsRunning    int `json:"jobs-running"`
+	JobsFinished   int `json:"jobs-finished"`
+	JobsCancelled  int `json:"jobs-cancelled"`
+	JobsFailed     int `json:"jobs-failed"`
+}
+
+// Job is a job of the cluster and its state, e.g. RUNNING, FINISHED or FAILED
+type Job struct {
+	ID    string `json:"jid"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// RunOptions configure how a job is run from an uploaded jar
+type RunOptions struct {
+	// EntryClass is the main class of the job, empty to use the one of the jar manifest
+	EntryClass string `json:"entryClass,omitempty"`
+	// ProgramArgs are the arguments passed to the main method of the job
+	ProgramArgs []string `json:"programArgsList,omitempty"`
+	// Parallelism is the parallelism of the job, zero to use the default one of the cluster
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+type errorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// Client is a client of the Flink REST API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the REST API of the job manager at baseURL, e.g. of a session cluster started
+// outside of the module
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Overview returns the number of task managers, slots and jobs of the cluster
+func (c *Client) Overview(ctx context.Context) (*Overview, error) {
+	var overview Overview
+	if err := c.do(ctx, http.MethodGet, "/overview", "", nil, &overview); err != nil {
+		return nil, err
+	}
+
+	return &overview, nil
+}
+
+// Jobs lists the jobs of the cluster, running or not
+func (c *Client) Jobs(ctx context.Context) ([]Job, error) {
+	var result struct {
+		Jobs []Job `json:"jobs"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/jobs/overview", "", nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Jobs, nil
+}
+
+// Job returns the job with the given ID
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), "", nil, &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// UploadJar uploads a jar holding jobs and returns its ID, to run them with RunJar
+func (c *Client) UploadJar(ctx context.Context, filename string, jar io.Reader) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("jarfile", path.Base(filename))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, jar); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Filename string `json:"filename"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/jars/upload", writer.FormDataContentType(), &body, &result); err != nil {
+		return "", err
+	}
+
+	// the ID is the name the jar is stored with on the job manager
+	return path.Base(result.Filename), nil
+}
+
+// RunJar runs a job of an uploaded jar and returns the ID of the job
+func (c *Client) RunJar(ctx context.Context, jarID string, options RunOptions) (string, error) {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		JobID string `json:"jobid"`
+	}
+	err = c.do(ctx, http.MethodPost, "/jars/"+url.PathEscape(jarID)+"/run", "application/json", bytes.NewReader(data), &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.JobID, nil
+}
+
+// SubmitJar uploads a jar and runs a job of it, returning the ID of the job
+func (c *Client) SubmitJar(ctx context.Context, filename string, jar io.Reader, options RunOptions) (string, error) {
+	jarID, err := c.UploadJar(ctx, filename, jar)
+	if err != nil {
+		return "", err
+	}
+
+	return c.RunJar(ctx, jarID, options)
+}
+
+// CancelJob cancels a running job
+func (c *Client) CancelJob(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPatch, "/jobs/"+url.PathEscape(id)+"?mode=cancel", "", nil, nil)
+}
+
+// WaitForJobState polls the job until it reaches one of the given states, e.g. RUNNING for a streaming job
+// or FINISHED for a batch job
+func (c *Client) WaitForJobState(ctx context.Context, id string, states ...string) (*Job, error) {
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, state := range states {
+			if job.State == state {
+				return job, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: job %s is %s instead of %s", ctx.Err(), id, job.State, strings.Join(states, " or "))
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, contentType string, body io.Reader, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var r errorResponse
+		if err := json.Unmarshal(respBody, &r); err == nil && len(r.Errors) > 0 {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.Join(r.Errors, ", "))
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, data)
+}
@@ -0,0 +1,84 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientSubmitJar(t *testing.T) {
+	var runOptions RunOptions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/jars/upload":
+			file, header, err := r.FormFile("jarfile")
+			if err != nil || header.Filename != "job.jar" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			content, _ := io.ReadAll(file)
+			if string(content) != "jar content" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"filename":"/tmp/flink-web/flink-web-upload/1234_job.jar","status":"success"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/jars/1234_job.jar/run":
+			_ = json.NewDecoder(r.Body).Decode(&runOptions)
+			_, _ = w.Write([]byte(`{"jobid":"abcd"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":["Not found: /jars/unknown/run"]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL + "/")
+
+	jobID, err := client.SubmitJar(context.Background(), "target/job.jar", strings.NewReader("jar content"), RunOptions{
+		EntryClass:  "com.example.Job",
+		ProgramArgs: []string{"--input", "/data"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobID != "abcd" {
+		t.Fatalf("unexpected job ID %s", jobID)
+	}
+	if runOptions.EntryClass != "com.example.Job" || len(runOptions.ProgramArgs) != 2 {
+		t.Fatalf("unexpected run options %+v", runOptions)
+	}
+
+	_, err = client.RunJar(context.Background(), "unknown", RunOptions{})
+	if err == nil || err.Error() != "unexpected status 404: Not found: /jars/unknown/run" {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
+
+func TestClientWaitForJobState(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		state := "RUNNING"
+		if calls > 2 {
+			state = "FINISHED"
+		}
+		_, _ = w.Write([]byte(`{"jid":"abcd","name":"word count","state":"` + state + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := NewClient(server.URL).WaitForJobState(ctx, "abcd", "FINISHED", "FAILED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != "FINISHED" || job.Name != "word count" {
+		t.Fatalf("unexpected job %+v", job)
+	}
+}
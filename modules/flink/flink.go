@@ -0,0 +1,147 @@
+// Package flink provides an Apache Flink session cluster, a job manager and a task manager on a dedicated network,
+// with a client of the REST API to submit jobs, to integration test streaming and batch pipelines
+package flink
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage    = "docker.io/flink:1.16.0-scala_2.12-java11"
+	restPort        = "8081/tcp"
+	jobManagerAlias = "jobmanager"
+	propertiesEnv   = "FLINK_PROPERTIES"
+)
+
+// FlinkCluster represents a Flink session cluster made of a job manager and a task manager
+type FlinkCluster struct {
+	JobManager  testcontainers.Container
+	TaskManager testcontainers.Container
+	network     testcontainers.Network
+}
+
+// RunCluster creates a network and starts a job manager and a task manager on it. The options customize the
+// requests of both containers, e.g. testcontainers.WithImage to use another version of Flink.
+// The cluster is returned once the task manager registered at the job manager.
+func RunCluster(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*FlinkCluster, error) {
+	networkName := "flink-" + uuid.NewString()
+
+	network, err := testcontainers.GenericNetwork(ctx, testcontainers.GenericNetworkRequest{
+		NetworkRequest: testcontainers.NetworkRequest{
+			Name:           networkName,
+			CheckDuplicate: true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &FlinkCluster{network: network}
+
+	jobManagerReq := clusterRequest(networkName, "jobmanager", wait.ForHTTP("/overview").WithPort(restPort), opts)
+	jobManagerReq.ExposedPorts = []string{restPort}
+	jobManagerReq.NetworkAliases = map[string][]string{networkName: {jobManagerAlias}}
+
+	cluster.JobManager, err = testcontainers.GenericContainer(ctx, jobManagerReq)
+	if err != nil {
+		_ = cluster.Terminate(ctx)
+		return nil, fmt.Errorf("%w: failed to start the job manager", err)
+	}
+
+	taskManagerReq := clusterRequest(networkName, "taskmanager", wait.ForLog("Successful registration at resource manager"), opts)
+
+	cluster.TaskManager, err = testcontainers.GenericContainer(ctx, taskManagerReq)
+	if err != nil {
+		_ = cluster.Terminate(ctx)
+		return nil, fmt.Errorf("%w: failed to start the task manager", err)
+	}
+
+	return cluster, nil
+}
+
+// clusterRequest returns the request of a container of the cluster running the given component
+func clusterRequest(networkName string, component string, strategy wait.Strategy, opts []testcontainers.CustomizeRequestOption) testcontainers.GenericContainerRequest {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:      defaultImage,
+			Cmd:        []string{component},
+			Networks:   []string{networkName},
+			Env:        map[string]string{propertiesEnv: "jobmanager.rpc.address: " + jobManagerAlias + "\n"},
+			WaitingFor: strategy,
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	return req
+}
+
+// WithProperties adds entries to the Flink configuration of the cluster, e.g. "state.backend": "rocksdb"
+func WithProperties(properties map[string]string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		for k, v := range properties {
+			req.Env[propertiesEnv] += k + ": " + v + "\n"
+		}
+	}
+}
+
+// WithTaskSlots sets the number of task slots of the task manager, the maximum parallelism of the jobs
+func WithTaskSlots(slots int) testcontainers.CustomizeRequestOption {
+	return WithProperties(map[string]string{"taskmanager.numberOfTaskSlots": strconv.Itoa(slots)})
+}
+
+// URL returns the base URL of the REST API of the job manager, also serving the web dashboard
+func (c *FlinkCluster) URL(ctx context.Context) (string, error) {
+	host, err := c.JobManager.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.JobManager.MappedPort(ctx, restPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, mappedPort.Port())), nil
+}
+
+// Client returns a client of the REST API of the job manager
+func (c *FlinkCluster) Client(ctx context.Context) (*Client, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
+
+// Terminate terminates the containers of the cluster and removes its network, returning the first error
+func (c *FlinkCluster) Terminate(ctx context.Context) error {
+	var firstErr error
+
+	for _, container := range []testcontainers.Container{c.TaskManager, c.JobManager} {
+		if container == nil {
+			continue
+		}
+		if err := container.Terminate(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if err := c.network.Remove(ctx); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	return firstErr
+}
@@ -0,0 +1,57 @@
+package flink
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFlinkCluster(t *testing.T) {
+	ctx := context.Background()
+
+	cluster, err := RunCluster(ctx, WithTaskSlots(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := cluster.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate cluster: %s", err)
+		}
+	})
+
+	client, err := cluster.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overview, err := client.Overview(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview.TaskManagers != 1 || overview.SlotsTotal != 2 {
+		t.Fatalf("unexpected overview %+v", overview)
+	}
+
+	// the image ships example jobs
+	jar, err := cluster.JobManager.CopyFileFromContainer(ctx, "/opt/flink/examples/batch/WordCount.jar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jar.Close()
+
+	jobID, err := client.SubmitJar(ctx, "WordCount.jar", jar, RunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	job, err := client.WaitForJobState(waitCtx, jobID, "FINISHED", "FAILED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != "FINISHED" {
+		t.Fatalf("expected the job to finish, got %s", job.State)
+	}
+}
@@ -0,0 +1,130 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Repository is a git repository hosted by Gitea
+type Repository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// CreateRepositoryOptions configure a repository created with Client.CreateRepository
+type CreateRepositoryOptions struct {
+	Name    string `json:"name"`
+	Private bool   `json:"private"`
+	// AutoInit creates an initial commit with a README, so that the repository can be cloned with a branch
+	AutoInit      bool   `json:"auto_init"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// Client is a client of the Gitea API, authenticated with basic authentication
+type Client struct {
+	baseURL    string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the Gitea instance at baseURL, authenticating its requests as the given user with
+// basic authentication
+func NewClient(baseURL string, user string, password string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		user:       user,
+		password:   password,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CreateRepository creates a repository owned by the authenticated user
+func (c *Client) CreateRepository(ctx context.Context, options CreateRepositoryOptions) (*Repository, error) {
+	var repo Repository
+	if err := c.do(ctx, http.MethodPost, "/api/v1/user/repos", options, &repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// Repository returns the repository of the owner with the given name
+func (c *Client) Repository(ctx context.Context, owner string, name string) (*Repository, error) {
+	var repo Repository
+	if err := c.do(ctx, http.MethodGet, "/api/v1/repos/"+url.PathEscape(owner)+"/"+url.PathEscape(name), nil, &repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// DeleteRepository deletes the repository of the owner with the given name
+func (c *Client) DeleteRepository(ctx context.Context, owner string, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/repos/"+url.PathEscape(owner)+"/"+url.PathEscape(name), nil, nil)
+}
+
+// AddSSHKey adds an SSH public key in the authorized keys format to the authenticated user,
+// to clone and push over SSH
+func (c *Client) AddSSHKey(ctx context.Context, title string, publicKey string) error {
+	key := map[string]string{"title": title, "key": publicKey}
+	return c.do(ctx, http.MethodPost, "/api/v1/user/keys", key, nil)
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, body interface{}, data interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.user, c.password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var r errorResponse
+		if err := json.Unmarshal(respBody, &r); err == nil && r.Message != "" {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, r.Message)
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, data)
+}
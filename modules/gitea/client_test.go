@@ -0,0 +1,52 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCreateRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"user does not exist"}`))
+			return
+		}
+
+		var options CreateRepositoryOptions
+		if r.URL.Path != "/api/v1/user/repos" || json.NewDecoder(r.Body).Decode(&options) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Repository{
+			Name:          options.Name,
+			FullName:      user + "/" + options.Name,
+			Private:       options.Private,
+			DefaultBranch: "main",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	repo, err := NewClient(server.URL, "admin", "secret").CreateRepository(context.Background(), CreateRepositoryOptions{
+		Name:     "infra",
+		Private:  true,
+		AutoInit: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.FullName != "admin/infra" || !repo.Private {
+		t.Fatalf("unexpected repository %+v", repo)
+	}
+
+	_, err = NewClient(server.URL, "admin", "wrong").CreateRepository(context.Background(), CreateRepositoryOptions{Name: "infra"})
+	if err == nil || err.Error() != "unexpected status 401: user does not exist" {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Package gitea provides a container running the Gitea git server, with an admin user bootstrapped on startup,
+// helpers to create repositories and the clone URLs of the repositories, to test tools interacting with git remotes
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage    = "docker.io/gitea/gitea:1.17.3"
+	defaultUser     = "gitea"
+	defaultPassword = "gitea"
+	httpPort        = "3000/tcp"
+	sshPort         = "22/tcp"
+)
+
+// GiteaContainer represents the Gitea container type used in the module
+type GiteaContainer struct {
+	testcontainers.Container
+	user     string
+	password string
+}
+
+// RunContainer creates an instance of the Gitea container type, with an admin user
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*GiteaContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{httpPort, sshPort},
+			Env: map[string]string{
+				// skips the installation page, using the default SQLite database
+				"GITEA__security__INSTALL_LOCK": "true",
+				"GITEA_ADMIN_USER":              defaultUser,
+				"GITEA_ADMIN_PASSWORD":          defaultPassword,
+			},
+			WaitingFor: wait.ForHTTP("/api/healthz").WithPort(httpPort),
+			LifecycleHooks: []testcontainers.ContainerLifecycleHooks{
+				{
+					PostReadies: []testcontainers.ContainerHook{createAdmin},
+				},
+			},
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GiteaContainer{
+		Container: container,
+		user:      req.Env["GITEA_ADMIN_USER"],
+		password:  req.Env["GITEA_ADMIN_PASSWORD"],
+	}, nil
+}
+
+// WithAdminCredentials sets the name and the password of the admin user created on startup
+func WithAdminCredentials(user string, password string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env["GITEA_ADMIN_USER"] = user
+		req.Env["GITEA_ADMIN_PASSWORD"] = password
+	}
+}
+
+// createAdmin creates the admin user with the Gitea CLI, which must be run as the git user
+func createAdmin(ctx context.Context, c testcontainers.Container) error {
+	// the credentials are expanded by the shell of the container, from the environment of the request
+	cmd := []string{"sh", "-c", `gitea admin user create --admin --username "$GITEA_ADMIN_USER" --password "$GITEA_ADMIN_PASSWORD" ` +
+		`--email "$GITEA_ADMIN_USER@example.com" --must-change-password=false`}
+
+	result, err := c.ExecWithResult(ctx, cmd, tcexec.WithUser("git"))
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("failed to create the admin user, exit code %d: %s", result.ExitCode, result.Combined())
+	}
+
+	return nil
+}
+
+// Credentials returns the name and the password of the admin user
+func (c *GiteaContainer) Credentials() (string, string) {
+	return c.user, c.password
+}
+
+// URL returns the base URL of the web interface and of the API
+func (c *GiteaContainer) URL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, httpPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// HTTPCloneURL returns the URL to clone the repository over HTTP, e.g. HTTPCloneURL(ctx, "gitea", "my-repo").
+// The credentials of the admin user are included, so that pushes are authenticated.
+func (c *GiteaContainer) HTTPCloneURL(ctx context.Context, owner string, repo string) (string, error) {
+	address, err := c.address(ctx, httpPort)
+	if err != nil {
+		return "", err
+	}
+
+	cloneURL := url.URL{
+		Scheme: "http",
+		User:   url.UserPassword(c.user, c.password),
+		Host:   address,
+		Path:   fmt.Sprintf("/%s/%s.git", owner, repo),
+	}
+
+	return cloneURL.String(), nil
+}
+
+// SSHCloneURL returns the URL to clone the repository over SSH, authenticating with a key added with Client.AddSSHKey
+func (c *GiteaContainer) SSHCloneURL(ctx context.Context, owner string, repo string) (string, error) {
+	address, err := c.address(ctx, sshPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ssh://git@%s/%s/%s.git", address, owner, repo), nil
+}
+
+// Client returns a client of the Gitea API authenticated as the admin user
+func (c *GiteaContainer) Client(ctx context.Context) (*Client, error) {
+	baseURL, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(baseURL, c.user, c.password), nil
+}
+
+func (c *GiteaContainer) address(ctx context.Context, port nat.Port) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mappedPort.Port()), nil
+}
@@ -0,0 +1,58 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGitea(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx, WithAdminCredentials("admin", "s3cr3t!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := client.CreateRepository(ctx, CreateRepositoryOptions{Name: "infra", AutoInit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.FullName != "admin/infra" {
+		t.Fatalf("unexpected repository %+v", repo)
+	}
+
+	cloneURL, err := container.HTTPCloneURL(ctx, "admin", "infra")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first step of a clone over the smart HTTP protocol
+	resp, err := http.Get(cloneURL + "/info/refs?service=git-upload-pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d listing the refs", resp.StatusCode)
+	}
+
+	sshURL, err := container.SSHCloneURL(ctx, "admin", "infra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sshURL, "ssh://git@") || !strings.HasSuffix(sshURL, "/admin/infra.git") {
+		t.Fatalf("unexpected SSH clone URL %s", sshURL)
+	}
+}
@@ -0,0 +1,187 @@
+package httprecorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Exchange is a request sent through the proxy and the response of the upstream
+type Exchange struct {
+	Request  Request  `json:"httpRequest"`
+	Response Response `json:"httpResponse"`
+}
+
+// Request is a request recorded by the proxy
+type Request struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"queryStringParameters,omitempty"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    Body                `json:"body,omitempty"`
+}
+
+// Response is a response of the upstream recorded by the proxy
+type Response struct {
+	StatusCode int                 `json:"statusCode"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       Body                `json:"body,omitempty"`
+}
+
+// Body is the content of a recorded request or response, whatever its type
+type Body []byte
+
+// UnmarshalJSON decodes the body from the representations of the proxy: a plain string,
+// or an object with the string, the JSON document or the base64 encoded bytes
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+
+	var typed struct {
+		String      *string         `json:"string"`
+		JSON        json.RawMessage `json:"json"`
+		Base64Bytes string          `json:"base64Bytes"`
+		RawBytes    string          `json:"rawBytes"`
+	}
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return err
+	}
+
+	switch {
+	case typed.String != nil:
+		*b = Body(*typed.String)
+	case len(typed.JSON) > 0:
+		// the document is either embedded as is, or as a string
+		if err := json.Unmarshal(typed.JSON, &s); err == nil {
+			*b = Body(s)
+		} else {
+			*b = Body(typed.JSON)
+		}
+	case typed.Base64Bytes != "" || typed.RawBytes != "":
+		encoded := typed.Base64Bytes
+		if encoded == "" {
+			encoded = typed.RawBytes
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return err
+		}
+		*b = decoded
+	default:
+		*b = Body(data)
+	}
+
+	return nil
+}
+
+// String returns the body as text
+func (b Body) String() string {
+	return string(b)
+}
+
+// Header returns the first value of the header of the request, matched case-insensitively
+func (r Request) Header(name string) string {
+	return firstValue(r.Headers, name)
+}
+
+// Header returns the first value of the header of the response, matched case-insensitively
+func (r Response) Header(name string) string {
+	return firstValue(r.Headers, name)
+}
+
+func firstValue(headers map[string][]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// Client is a client of the control plane of the proxy, to retrieve and clear the recorded traffic
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the proxy served at the given URL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Exchanges returns the requests sent through the proxy since it started or was reset, with their responses,
+// in the order they were received
+func (c *Client) Exchanges(ctx context.Context) ([]Exchange, error) {
+	query := url.Values{"type": {"REQUEST_RESPONSES"}, "format": {"JSON"}}
+
+	var exchanges []Exchange
+	if err := c.do(ctx, http.MethodPut, "/mockserver/retrieve?"+query.Encode(), &exchanges); err != nil {
+		return nil, err
+	}
+
+	return exchanges, nil
+}
+
+// ExchangesFor returns the recorded exchanges with the given method and path, any method matches if empty
+func (c *Client) ExchangesFor(ctx context.Context, method string, path string) ([]Exchange, error) {
+	exchanges, err := c.Exchanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Exchange
+	for _, e := range exchanges {
+		if (method == "" || strings.EqualFold(e.Request.Method, method)) && e.Request.Path == path {
+			matched = append(matched, e)
+		}
+	}
+
+	return matched, nil
+}
+
+// Reset clears the recorded traffic, e.g. between the subtests sharing the proxy
+func (c *Client) Reset(ctx context.Context) error {
+	return c.do(ctx, http.MethodPut, "/mockserver/reset", nil)
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	if data == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, data)
+}
@@ -0,0 +1,82 @@
+package httprecorder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/mockserver/retrieve" ||
+			r.URL.Query().Get("type") != "REQUEST_RESPONSES" || r.URL.Query().Get("format") != "JSON" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("incorrect request format"))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[
+  {
+    "httpRequest": {
+      "method": "POST",
+      "path": "/orders",
+      "headers": {"Content-Type": ["application/json"]},
+      "body": {"type": "JSON", "json": {"id": 1}}
+    },
+    "httpResponse": {
+      "statusCode": 201,
+      "headers": {"Location": ["/orders/1"]},
+      "body": {"type": "STRING", "string": "created"}
+    }
+  },
+  {
+    "httpRequest": {"method": "GET", "path": "/orders/1", "queryStringParameters": {"expand": ["items"]}},
+    "httpResponse": {"statusCode": 200, "body": {"type": "BINARY", "base64Bytes": "aGVsbG8="}}
+  },
+  {
+    "httpRequest": {"method": "GET", "path": "/health"},
+    "httpResponse": {"statusCode": 200, "body": "ok"}
+  }
+]`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL + "/")
+
+	exchanges, err := client.Exchanges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 3 {
+		t.Fatalf("expected 3 exchanges, got %d", len(exchanges))
+	}
+
+	created := exchanges[0]
+	if created.Request.Header("content-type") != "application/json" || created.Request.Body.String() != `{"id": 1}` {
+		t.Fatalf("unexpected request %+v", created.Request)
+	}
+	if created.Response.StatusCode != http.StatusCreated || created.Response.Header("Location") != "/orders/1" ||
+		created.Response.Body.String() != "created" {
+		t.Fatalf("unexpected response %+v", created.Response)
+	}
+
+	if exchanges[1].Request.Query["expand"][0] != "items" || exchanges[1].Response.Body.String() != "hello" {
+		t.Fatalf("unexpected exchange %+v", exchanges[1])
+	}
+	if exchanges[2].Response.Body.String() != "ok" {
+		t.Fatalf("unexpected exchange %+v", exchanges[2])
+	}
+
+	matched, err := client.ExchangesFor(context.Background(), http.MethodGet, "/orders/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 1 || matched[0].Response.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected exchanges %+v", matched)
+	}
+
+	if err := NewClient(server.URL).Reset(context.Background()); err == nil || err.Error() != "unexpected status 400: incorrect request format" {
+		t.Fatalf("expected the proxy error, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Package httprecorder provides a container running an HTTP proxy in front of a dependency of the system under test,
+// recording the requests and the responses going through it, to assert on the HTTP traffic at the network level
+// instead of mocking the client of the dependency in code
+package httprecorder
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/mockserver/mockserver:5.14.0"
+	proxyPort    = "1080/tcp"
+)
+
+// RecorderContainer represents the recording proxy container type used in the module
+type RecorderContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the recording proxy, forwarding all the requests to the upstream host and port,
+// e.g. the network alias of the dependency container on a network set with WithNetwork. Point the system under test
+// to the proxy, e.g. with the alias it used to reach the dependency, to record its traffic.
+func RunContainer(ctx context.Context, upstreamHost string, upstreamPort int, opts ...testcontainers.CustomizeRequestOption) (*RecorderContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{proxyPort},
+			Env: map[string]string{
+				"MOCKSERVER_PROXY_REMOTE_HOST": upstreamHost,
+				"MOCKSERVER_PROXY_REMOTE_PORT": strconv.Itoa(upstreamPort),
+			},
+			// the control plane is served by the proxy itself, the other paths are forwarded
+			WaitingFor: wait.ForHTTP("/mockserver/status").WithMethod(http.MethodPut).WithPort(proxyPort),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecorderContainer{Container: container}, nil
+}
+
+// WithNetwork attaches the proxy to the network of the dependency, with the given aliases. Giving the proxy the alias
+// the system under test uses to reach the dependency, while the dependency gets another one, inserts the proxy
+// between them without reconfiguring the system under test.
+func WithNetwork(network string, aliases ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Networks = append(req.Networks, network)
+		if len(aliases) == 0 {
+			return
+		}
+
+		if req.NetworkAliases == nil {
+			req.NetworkAliases = map[string][]string{}
+		}
+		req.NetworkAliases[network] = append(req.NetworkAliases[network], aliases...)
+	}
+}
+
+// URL returns the base URL of the proxy from the host, to send requests to the upstream through it
+func (c *RecorderContainer) URL(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, proxyPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port.Port())), nil
+}
+
+// Client returns a client of the proxy, to retrieve the recorded traffic
+func (c *RecorderContainer) Client(ctx context.Context) (*Client, error) {
+	baseURL, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(baseURL), nil
+}
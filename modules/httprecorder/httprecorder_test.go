@@ -0,0 +1,106 @@
+package httprecorder
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+
+	networkName := "httprecorder-" + uuid.NewString()
+	network, err := testcontainers.GenericNetwork(ctx, testcontainers.GenericNetworkRequest{
+		NetworkRequest: testcontainers.NetworkRequest{Name: networkName, CheckDuplicate: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := network.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	upstream, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "docker.io/hashicorp/http-echo:0.2.3",
+			Cmd:            []string{"-text=hello from upstream"},
+			ExposedPorts:   []string{"5678/tcp"},
+			Networks:       []string{networkName},
+			NetworkAliases: map[string][]string{networkName: {"upstream"}},
+			WaitingFor:     wait.ForListeningPort("5678/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := upstream.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	container, err := RunContainer(ctx, "upstream", 5678, WithNetwork(networkName, "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	url, err := container.URL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(url + "/greeting?lang=en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello from upstream\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exchanges, err := client.ExchangesFor(ctx, http.MethodGet, "/greeting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 1 {
+		t.Fatalf("expected 1 exchange, got %d", len(exchanges))
+	}
+	if exchanges[0].Request.Query["lang"][0] != "en" || exchanges[0].Response.StatusCode != http.StatusOK ||
+		exchanges[0].Response.Body.String() != "hello from upstream\n" {
+		t.Fatalf("unexpected exchange %+v", exchanges[0])
+	}
+
+	if err := client.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	exchanges, err = client.Exchanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 0 {
+		t.Fatalf("expected the recorded traffic to be cleared, got %+v", exchanges)
+	}
+}
@@ -0,0 +1,205 @@
+package kafkaconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrConnectorFailed is returned when waiting for a connector or one of its tasks which failed
+var ErrConnectorFailed = errors.New("connector failed")
+
+// Connector states reported by Kafka Connect
+const (
+	StateRunning = "RUNNING"
+	StatePaused  = "PAUSED"
+	StateFailed  = "FAILED"
+)
+
+// ConnectorStatus is the state of a connector and of its tasks
+type ConnectorStatus struct {
+	Name      string `json:"name"`
+	Connector struct {
+		State    string `json:"state"`
+		WorkerID string `json:"worker_id"`
+		Trace    string `json:"trace"`
+	} `json:"connector"`
+	Tasks []TaskStatus `json:"tasks"`
+}
+
+// TaskStatus is the state of a task of a connector, with the stack trace of its failure if any
+type TaskStatus struct {
+	ID       int    `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace"`
+}
+
+type errorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Client is a client of the Kafka Connect REST API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the REST API of the Connect worker at baseURL. Its requests time out after 30 seconds,
+// as validating the configuration of a connector may take a while.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateConnector registers the connector with the given configuration, or updates its configuration if it exists,
+// e.g. CreateConnector(ctx, "inventory", map[string]string{"connector.class": "io.debezium.connector.postgresql.PostgresConnector", ...})
+func (c *Client) CreateConnector(ctx context.Context, name string, config map[string]string) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPut, "/connectors/"+url.PathEscape(name)+"/config", bytes.NewReader(data), nil)
+}
+
+// DeleteConnector stops and removes the connector
+func (c *Client) DeleteConnector(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/connectors/"+url.PathEscape(name), nil, nil)
+}
+
+// Connectors lists the names of the registered connectors
+func (c *Client) Connectors(ctx context.Context) ([]string, error) {
+	var names []string
+	if err := c.do(ctx, http.MethodGet, "/connectors", nil, &names); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// ConnectorStatus returns the state of the connector and of its tasks
+func (c *Client) ConnectorStatus(ctx context.Context, name string) (*ConnectorStatus, error) {
+	var status ConnectorStatus
+	if err := c.do(ctx, http.MethodGet, "/connectors/"+url.PathEscape(name)+"/status", nil, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// WaitForConnectorRunning polls the status of the connector until it and all its tasks are running, as tasks are
+// started asynchronously once the connector is registered. It fails fast with ErrConnectorFailed if the connector
+// or one of its tasks failed.
+func (c *Client) WaitForConnectorRunning(ctx context.Context, name string) (*ConnectorStatus, error) {
+	for {
+		status, err := c.ConnectorStatus(ctx, name)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+
+		if status != nil {
+			if err := status.failure(); err != nil {
+				return nil, err
+			}
+			if status.running() {
+				return status, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: connector %s not running", ctx.Err(), name)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (s *ConnectorStatus) running() bool {
+	if s.Connector.State != StateRunning || len(s.Tasks) == 0 {
+		return false
+	}
+
+	for _, task := range s.Tasks {
+		if task.State != StateRunning {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *ConnectorStatus) failure() error {
+	if s.Connector.State == StateFailed {
+		return fmt.Errorf("%w: %s: %s", ErrConnectorFailed, s.Name, s.Connector.Trace)
+	}
+
+	for _, task := range s.Tasks {
+		if task.State == StateFailed {
+			return fmt.Errorf("%w: task %d of %s: %s", ErrConnectorFailed, task.ID, s.Name, task.Trace)
+		}
+	}
+
+	return nil
+}
+
+// statusError is returned for the error responses of the API
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.status, e.message)
+}
+
+func isNotFound(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, body io.Reader, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var r errorResponse
+		if err := json.Unmarshal(respBody, &r); err == nil && r.Message != "" {
+			return &statusError{status: resp.StatusCode, message: r.Message}
+		}
+		return &statusError{status: resp.StatusCode, message: string(respBody)}
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, data)
+}
@@ -0,0 +1,67 @@
+package kafkaconnect
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientWaitForConnectorRunning(t *testing.T) {
+	var config string
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/connectors/inventory/config":
+			body, _ := io.ReadAll(r.Body)
+			config = string(body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"name":"inventory","config":{},"tasks":[]}`))
+		case r.URL.Path == "/connectors/inventory/status":
+			calls++
+			switch calls {
+			case 1:
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error_code":404,"message":"No status found for connector inventory"}`))
+			case 2:
+				_, _ = w.Write([]byte(`{"name":"inventory","connector":{"state":"RUNNING"},"tasks":[]}`))
+			default:
+				_, _ = w.Write([]byte(`{"name":"inventory","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"RUNNING"}]}`))
+			}
+		case r.URL.Path == "/connectors/broken/status":
+			_, _ = w.Write([]byte(`{"name":"broken","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"FAILED","trace":"connection refused"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	err := client.CreateConnector(context.Background(), "inventory", map[string]string{"connector.class": "Postgres"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config != `{"connector.class":"Postgres"}` {
+		t.Fatalf("unexpected config %s", config)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	status, err := client.WaitForConnectorRunning(ctx, "inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Tasks) != 1 || calls != 3 {
+		t.Fatalf("unexpected status %+v after %d calls", status, calls)
+	}
+
+	_, err = client.WaitForConnectorRunning(ctx, "broken")
+	if !errors.Is(err, ErrConnectorFailed) {
+		t.Fatalf("expected the connector to fail, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package kafkaconnect provides a container running Kafka Connect with the Debezium connectors, against a provided
+// Kafka cluster, with a client to register connectors and wait for them to run, to integration test CDC pipelines
+package kafkaconnect
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/debezium/connect:2.0"
+	restPort     = "8083/tcp"
+)
+
+// KafkaConnectContainer represents the Kafka Connect container type used in the module
+type KafkaConnectContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Kafka Connect container type, connecting to the given bootstrap servers.
+// The servers are resolved by the container, e.g. the network alias of a Kafka container on a network set
+// with WithNetwork.
+func RunContainer(ctx context.Context, bootstrapServers string, opts ...testcontainers.CustomizeRequestOption) (*KafkaConnectContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{restPort},
+			Env: map[string]string{
+				"BOOTSTRAP_SERVERS":    bootstrapServers,
+				"GROUP_ID":             "testcontainers",
+				"CONFIG_STORAGE_TOPIC": "testcontainers_connect_configs",
+				"OFFSET_STORAGE_TOPIC": "testcontainers_connect_offsets",
+				"STATUS_STORAGE_TOPIC": "testcontainers_connect_statuses",
+				// test clusters usually have a single broker
+				"CONNECT_CONFIG_STORAGE_REPLICATION_FACTOR": "1",
+				"CONNECT_OFFSET_STORAGE_REPLICATION_FACTOR": "1",
+				"CONNECT_STATUS_STORAGE_REPLICATION_FACTOR": "1",
+			},
+			// the REST API is served once the worker joined its group
+			WaitingFor: wait.ForHTTP("/connectors").WithPort(restPort),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaConnectContainer{Container: container}, nil
+}
+
+// WithNetwork attaches the container to the network of the Kafka cluster and of the systems the connectors reach
+func WithNetwork(network string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Networks = append(req.Networks, network)
+	}
+}
+
+// WithGroupID sets the group of the Connect worker, and the prefix of its storage topics,
+// so that several workers can share a Kafka cluster
+func WithGroupID(groupID string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env["GROUP_ID"] = groupID
+		req.Env["CONFIG_STORAGE_TOPIC"] = groupID + "_connect_configs"
+		req.Env["OFFSET_STORAGE_TOPIC"] = groupID + "_connect_offsets"
+		req.Env["STATUS_STORAGE_TOPIC"] = groupID + "_connect_statuses"
+	}
+}
+
+// URL returns the base URL of the Kafka Connect REST API
+func (c *KafkaConnectContainer) URL(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, restPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port.Port())), nil
+}
+
+// Client returns a client of the Kafka Connect REST API
+func (c *KafkaConnectContainer) Client(ctx context.Context) (*Client, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
@@ -0,0 +1,124 @@
+package kafkaconnect
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestKafkaConnect(t *testing.T) {
+	ctx := context.Background()
+
+	networkName := "kafkaconnect-" + uuid.NewString()
+	network, err := testcontainers.GenericNetwork(ctx, testcontainers.GenericNetworkRequest{
+		NetworkRequest: testcontainers.NetworkRequest{Name: networkName, CheckDuplicate: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := network.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	kafka := startKafka(t, networkName)
+
+	pg, err := postgres.RunContainer(ctx, func(req *testcontainers.GenericContainerRequest) {
+		req.Networks = []string{networkName}
+		req.NetworkAliases = map[string][]string{networkName: {"postgres"}}
+		req.Cmd = []string{"postgres", "-c", "wal_level=logical"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := pg.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	container, err := RunContainer(ctx, "kafka:9092", WithNetwork(networkName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+		// Kafka Connect must be stopped before the cluster it uses
+		if err := kafka.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.CreateConnector(ctx, "inventory", map[string]string{
+		"connector.class":   "io.debezium.connector.postgresql.PostgresConnector",
+		"plugin.name":       "pgoutput",
+		"database.hostname": "postgres",
+		"database.port":     "5432",
+		"database.user":     "postgres",
+		"database.password": "postgres",
+		"database.dbname":   "postgres",
+		"topic.prefix":      "inventory",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	if _, err := client.WaitForConnectorRunning(waitCtx, "inventory"); err != nil {
+		t.Fatal(err)
+	}
+
+	connectors, err := client.Connectors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connectors) != 1 || connectors[0] != "inventory" {
+		t.Fatalf("unexpected connectors %v", connectors)
+	}
+}
+
+// startKafka starts a single node Kafka cluster in KRaft mode, reachable at kafka:9092 on the network
+func startKafka(t *testing.T, networkName string) testcontainers.Container {
+	kafka, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "docker.io/bitnami/kafka:3.3",
+			Networks:       []string{networkName},
+			NetworkAliases: map[string][]string{networkName: {"kafka"}},
+			Env: map[string]string{
+				"KAFKA_ENABLE_KRAFT":                       "yes",
+				"KAFKA_CFG_NODE_ID":                        "1",
+				"KAFKA_BROKER_ID":                          "1",
+				"KAFKA_CFG_PROCESS_ROLES":                  "broker,controller",
+				"KAFKA_CFG_CONTROLLER_LISTENER_NAMES":      "CONTROLLER",
+				"KAFKA_CFG_LISTENERS":                      "PLAINTEXT://:9092,CONTROLLER://:9093",
+				"KAFKA_CFG_LISTENER_SECURITY_PROTOCOL_MAP": "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+				"KAFKA_CFG_ADVERTISED_LISTENERS":           "PLAINTEXT://kafka:9092",
+				"KAFKA_CFG_CONTROLLER_QUORUM_VOTERS":       "1@127.0.0.1:9093",
+				"ALLOW_PLAINTEXT_LISTENER":                 "yes",
+			},
+			WaitingFor: wait.ForLog("Kafka Server started"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return kafka
+}
@@ -0,0 +1,173 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pushPath = "/loki/api/v1/push"
+
+// Entry is a log line of a stream
+type Entry struct {
+	Timestamp time.Time
+	Line      string
+}
+
+// Stream is a set of log lines sharing the same labels
+type Stream struct {
+	Labels  map[string]string
+	Entries []Entry
+}
+
+type pushRequest struct {
+	Streams []pushStream `json:"streams"`
+}
+
+type pushStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string       `json:"resultType"`
+		Result     []pushStream `json:"result"`
+	} `json:"data"`
+}
+
+// Client is a client of the Loki HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the Loki server at baseURL, pushing and querying log lines over HTTP
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Push pushes log lines with the given labels, e.g. to seed logs queried by the system under test
+func (c *Client) Push(ctx context.Context, labels map[string]string, entries ...Entry) error {
+	stream := pushStream{Stream: labels}
+	for _, e := range entries {
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.Timestamp.UnixNano(), 10), e.Line})
+	}
+
+	body, err := json.Marshal(pushRequest{Streams: []pushStream{stream}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+pushPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d pushing log lines: %s", resp.StatusCode, b)
+	}
+
+	return nil
+}
+
+// Query returns the streams matching the LogQL query, e.g. `{app="api"} |= "error"`,
+// with the log lines of the last hour in chronological order
+func (c *Client) Query(ctx context.Context, query string) ([]Stream, error) {
+	end := time.Now()
+	params := url.Values{
+		"query":     {query},
+		"start":     {strconv.FormatInt(end.Add(-time.Hour).UnixNano(), 10)},
+		"end":       {strconv.FormatInt(end.UnixNano(), 10)},
+		"direction": {"forward"},
+		"limit":     {"5000"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/loki/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d querying %q: %s", resp.StatusCode, query, b)
+	}
+
+	var r queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	if r.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("query %q returned %s instead of streams", query, r.Data.ResultType)
+	}
+
+	streams := make([]Stream, 0, len(r.Data.Result))
+	for _, s := range r.Data.Result {
+		stream := Stream{Labels: s.Stream}
+		for _, v := range s.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid timestamp", err)
+			}
+			stream.Entries = append(stream.Entries, Entry{Timestamp: time.Unix(0, ns), Line: v[1]})
+		}
+		sort.Slice(stream.Entries, func(i, j int) bool {
+			return stream.Entries[i].Timestamp.Before(stream.Entries[j].Timestamp)
+		})
+		streams = append(streams, stream)
+	}
+
+	return streams, nil
+}
+
+// WaitForLines polls the query until the matching streams hold at least count log lines in total,
+// as log lines are shipped asynchronously
+func (c *Client) WaitForLines(ctx context.Context, query string, count int) ([]Stream, error) {
+	for {
+		streams, err := c.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		lines := 0
+		for _, s := range streams {
+			lines += len(s.Entries)
+		}
+		if lines >= count {
+			return streams, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %d of %d log lines matching %q", ctx.Err(), lines, count, query)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
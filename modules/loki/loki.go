@@ -0,0 +1,81 @@
+// Package loki provides a container running Grafana Loki, with helpers to push log lines and query them,
+// to integration test log pipelines
+package loki
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/grafana/loki:2.7.0"
+	httpPort     = "3100/tcp"
+)
+
+// LokiContainer represents the Loki container type used in the module
+type LokiContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Loki container type, using the local storage configuration of the image
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*LokiContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{httpPort},
+			// the ingester only reports ready once it joined its ring
+			WaitingFor: wait.ForHTTP("/ready").WithPort(httpPort),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LokiContainer{Container: container}, nil
+}
+
+// URL returns the base URL of the Loki HTTP API
+func (c *LokiContainer) URL(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, httpPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port.Port())), nil
+}
+
+// PushURL returns the URL log shippers, like Promtail or the OpenTelemetry collector, push log lines to
+func (c *LokiContainer) PushURL(ctx context.Context) (string, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return url + pushPath, nil
+}
+
+// Client returns a client of the Loki HTTP API, to push and query log lines
+func (c *LokiContainer) Client(ctx context.Context) (*Client, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
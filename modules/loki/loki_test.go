@@ -0,0 +1,49 @@
+package loki
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoki(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	err = client.Push(ctx, map[string]string{"app": "api"},
+		Entry{Timestamp: now.Add(-time.Second), Line: "request failed: timeout"},
+		Entry{Timestamp: now, Line: "request succeeded"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	streams, err := client.WaitForLines(waitCtx, `{app="api"} |= "failed"`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 1 || len(streams[0].Entries) != 1 || streams[0].Entries[0].Line != "request failed: timeout" {
+		t.Fatalf("unexpected streams %+v", streams)
+	}
+	if streams[0].Labels["app"] != "api" {
+		t.Fatalf("unexpected labels %v", streams[0].Labels)
+	}
+}
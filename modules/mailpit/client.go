@@ -0,0 +1,180 @@
+package mailpit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrMessageNotFound is returned when no captured message matches the given ID
+var ErrMessageNotFound = errors.New("message not found")
+
+// Address is a sender or recipient of a message
+type Address struct {
+	Name    string `json:"Name"`
+	Address string `json:"Address"`
+}
+
+// MessageSummary is a captured message as listed by the API, without its body
+type MessageSummary struct {
+	ID          string    `json:"ID"`
+	MessageID   string    `json:"MessageID"`
+	Read        bool      `json:"Read"`
+	From        Address   `json:"From"`
+	To          []Address `json:"To"`
+	Cc          []Address `json:"Cc"`
+	Bcc         []Address `json:"Bcc"`
+	Subject     string    `json:"Subject"`
+	Created     time.Time `json:"Created"`
+	Tags        []string  `json:"Tags"`
+	Size        int       `json:"Size"`
+	Attachments int       `json:"Attachments"`
+	Snippet     string    `json:"Snippet"`
+}
+
+// Attachment describes a file attached to a message
+type Attachment struct {
+	PartID      string `json:"PartID"`
+	FileName    string `json:"FileName"`
+	ContentType string `json:"ContentType"`
+	Size        int    `json:"Size"`
+}
+
+// Message is a captured message including its body
+type Message struct {
+	ID          string              `json:"ID"`
+	MessageID   string              `json:"MessageID"`
+	From        Address             `json:"From"`
+	To          []Address           `json:"To"`
+	Cc          []Address           `json:"Cc"`
+	Bcc         []Address           `json:"Bcc"`
+	ReplyTo     []Address           `json:"ReplyTo"`
+	Subject     string              `json:"Subject"`
+	Date        time.Time           `json:"Date"`
+	Tags        []string            `json:"Tags"`
+	Text        string              `json:"Text"`
+	HTML        string              `json:"HTML"`
+	Size        int                 `json:"Size"`
+	Attachments []Attachment        `json:"Attachments"`
+	Headers     map[string][]string `json:"-"`
+}
+
+type messagesResponse struct {
+	Total    int              `json:"total"`
+	Messages []MessageSummary `json:"messages"`
+}
+
+// Client is a client of the Mailpit HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the Mailpit API at baseURL, the address of the web UI rather than of the SMTP server
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Messages lists the captured messages, the most recent first
+func (c *Client) Messages(ctx context.Context) ([]MessageSummary, error) {
+	var resp messagesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/messages", &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Messages, nil
+}
+
+// Search lists the captured messages matching the query, using the Mailpit search syntax
+// e.g. "to:john@example.com subject:welcome"
+func (c *Client) Search(ctx context.Context, query string) ([]MessageSummary, error) {
+	var resp messagesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/search?query="+url.QueryEscape(query), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Messages, nil
+}
+
+// Message returns the captured message with the given ID, including its body and headers
+func (c *Client) Message(ctx context.Context, id string) (*Message, error) {
+	var msg Message
+	if err := c.do(ctx, http.MethodGet, "/api/v1/message/"+url.PathEscape(id), &msg); err != nil {
+		return nil, err
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/api/v1/message/"+url.PathEscape(id)+"/headers", &msg.Headers); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+// DeleteAll deletes all the captured messages, e.g. between two tests sharing the container
+func (c *Client) DeleteAll(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/messages", nil)
+}
+
+// WaitForMessages polls the API until at least count messages matching the query were captured,
+// an empty query matches all the messages
+func (c *Client) WaitForMessages(ctx context.Context, query string, count int) ([]MessageSummary, error) {
+	for {
+		var (
+			messages []MessageSummary
+			err      error
+		)
+		if query == "" {
+			messages, err = c.Messages(ctx)
+		} else {
+			messages, err = c.Search(ctx, query)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) >= count {
+			return messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %d of %d messages captured", ctx.Err(), len(messages), count)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrMessageNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d from %s %s: %s", resp.StatusCode, method, path, body)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
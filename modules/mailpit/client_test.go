@@ -0,0 +1,64 @@
+package mailpit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientWaitForMessages(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/search" || r.URL.Query().Get("query") != "subject:welcome" {
+			http.NotFound(w, r)
+			return
+		}
+
+		requests++
+		if requests < 3 {
+			_, _ = w.Write([]byte(`{"total":0,"messages":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"total":1,"messages":[{"ID":"abc","Subject":"Welcome","To":[{"Address":"john@example.com"}]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	messages, err := NewClient(server.URL+"/").WaitForMessages(ctx, "subject:welcome", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].ID != "abc" || messages[0].To[0].Address != "john@example.com" {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+}
+
+func TestClientWaitForMessagesTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"total":0,"messages":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	_, err := NewClient(server.URL).WaitForMessages(ctx, "", 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+}
+
+func TestClientMessageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	_, err := NewClient(server.URL).Message(context.Background(), "unknown")
+	if !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+}
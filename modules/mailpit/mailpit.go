@@ -0,0 +1,90 @@
+// Package mailpit provides a container running Mailpit, an SMTP server capturing the emails it receives
+// and exposing them through an HTTP API, to verify email sending code end to end
+package mailpit
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/axllent/mailpit:v1.10"
+	smtpPort     = "1025/tcp"
+	apiPort      = "8025/tcp"
+)
+
+// MailpitContainer represents the Mailpit container type used in the module
+type MailpitContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Mailpit container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*MailpitContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{smtpPort, apiPort},
+			WaitingFor: wait.ForAll(
+				wait.ForListeningPort(smtpPort),
+				wait.ForHTTP("/api/v1/messages").WithPort(apiPort),
+			),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MailpitContainer{Container: container}, nil
+}
+
+// SMTPAddress returns the host:port the emails must be sent to, no authentication nor TLS is required
+func (c *MailpitContainer) SMTPAddress(ctx context.Context) (string, error) {
+	return c.address(ctx, smtpPort)
+}
+
+// APIURL returns the base URL of the HTTP API and web interface
+func (c *MailpitContainer) APIURL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, apiPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// Client returns a client of the HTTP API, to list and assert on the captured messages
+func (c *MailpitContainer) Client(ctx context.Context) (*Client, error) {
+	url, err := c.APIURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
+
+func (c *MailpitContainer) address(ctx context.Context, port nat.Port) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mappedPort.Port()), nil
+}
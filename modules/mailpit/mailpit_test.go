@@ -0,0 +1,68 @@
+package mailpit
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+	"time"
+)
+
+func TestMailpit(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	address, err := container.SMTPAddress(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := "To: john@example.com\r\nSubject: Welcome\r\n\r\nHello John\r\n"
+	if err := smtp.SendMail(address, nil, "noreply@example.com", []string{"john@example.com"}, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	messages, err := client.WaitForMessages(waitCtx, "to:john@example.com", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages[0].Subject != "Welcome" {
+		t.Fatalf("unexpected subject %q", messages[0].Subject)
+	}
+
+	msg, err := client.Message(ctx, messages[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Text != "Hello John\r\n" {
+		t.Fatalf("unexpected body %q", msg.Text)
+	}
+
+	if err := client.DeleteAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err = client.Messages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("expected no message after DeleteAll, got %d", len(messages))
+	}
+}
@@ -0,0 +1,163 @@
+// Package memcached provides a container running Memcached, for cache layer integration tests
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/memcached:1.6-alpine"
+	port         = "11211/tcp"
+)
+
+// MemcachedContainer represents the Memcached container type used in the module
+type MemcachedContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Memcached container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*MemcachedContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{port},
+			WaitingFor:   &statsStrategy{startupTimeout: 60 * time.Second},
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemcachedContainer{Container: container}, nil
+}
+
+// WithMemorySize sets the memory used to store the items, in megabytes, 64 by default.
+// A small size allows to test the behaviour of the system under test when items are evicted
+func WithMemorySize(megabytes int) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		// the entrypoint of the image runs memcached with the arguments
+		req.Cmd = append(req.Cmd, "-m", strconv.Itoa(megabytes))
+	}
+}
+
+// Address returns the host:port the clients connect to
+func (c *MemcachedContainer) Address(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mappedPort.Port()), nil
+}
+
+// Stats returns the general-purpose statistics of the server, e.g. "curr_items" or "evictions"
+func (c *MemcachedContainer) Stats(ctx context.Context) (map[string]string, error) {
+	address, err := c.Address(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats(ctx, address)
+}
+
+// Implement interface
+var _ wait.Strategy = (*statsStrategy)(nil)
+
+// statsStrategy waits until the server answers the stats command,
+// as the port is published by Docker before the server listens to it
+type statsStrategy struct {
+	startupTimeout time.Duration
+}
+
+// WaitUntilReady implements Strategy.WaitUntilReady
+func (s *statsStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startupTimeout)
+	defer cancel()
+
+	var err error
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s:%w", ctx.Err(), err)
+		case <-time.After(100 * time.Millisecond):
+			if err = s.check(ctx, target); err == nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *statsStrategy) check(ctx context.Context, target wait.StrategyTarget) error {
+	host, err := target.Host(ctx)
+	if err != nil {
+		return err
+	}
+
+	mappedPort, err := target.MappedPort(ctx, port)
+	if err != nil {
+		return err
+	}
+
+	_, err = stats(ctx, net.JoinHostPort(host, mappedPort.Port()))
+	return err
+}
+
+// stats sends the stats command of the text protocol and parses the "STAT <name> <value>" lines
+func stats(ctx context.Context, address string) (map[string]string, error) {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("stats\r\n")); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "END" {
+			return values, nil
+		}
+
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 || fields[0] != "STAT" {
+			return nil, fmt.Errorf("unexpected stats line %q", line)
+		}
+		values[fields[1]] = fields[2]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("connection closed before the end of the stats")
+}
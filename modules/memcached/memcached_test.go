@@ -0,0 +1,84 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+)
+
+func TestMemcached(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx, WithMemorySize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	address, err := container.Address(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("set greeting 0 0 5\r\nhello\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "STORED\r\n" {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+
+	stats, err := container.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["curr_items"] != "1" {
+		t.Fatalf("expected one item, got %s", stats["curr_items"])
+	}
+	if stats["limit_maxbytes"] != "16777216" {
+		t.Fatalf("expected a memory size of 16MB, got %s bytes", stats["limit_maxbytes"])
+	}
+}
+
+func TestStats(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		if line == "stats\r\n" {
+			_, _ = conn.Write([]byte("STAT pid 1\r\nSTAT version 1.6.17\r\nEND\r\n"))
+		}
+	}()
+
+	values, err := stats(context.Background(), listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["pid"] != "1" || values["version"] != "1.6.17" {
+		t.Fatalf("unexpected stats %v", values)
+	}
+}
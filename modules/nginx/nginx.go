@@ -0,0 +1,200 @@
+// Package nginx provides a container running nginx as a reverse proxy in front of other test containers,
+// with routes configured from Go values, to integration test routing, headers and TLS termination
+package nginx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/nginx:1.23-alpine"
+	httpPort     = "80/tcp"
+	httpsPort    = "443/tcp"
+
+	configDir      = "/etc/nginx/conf.d"
+	configPath     = configDir + "/default.conf"
+	certPath       = "/etc/nginx/server.crt"
+	keyPath        = "/etc/nginx/server.key"
+	includeSuffix  = ".inc"
+	tlsConfigPath  = configDir + "/tls" + includeSuffix
+	routeConfigFmt = configDir + "/route-%s" + includeSuffix
+)
+
+// config replaces the default server of the image, the routes and the TLS settings
+// are included from the files copied by the options
+const config = `server {
+    listen 80;
+    include ` + configDir + `/*` + includeSuffix + `;
+}
+`
+
+// NginxContainer represents the nginx container type used in the module
+type NginxContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the nginx container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*NginxContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{httpPort},
+			WaitingFor:   wait.ForListeningPort(httpPort),
+			LifecycleHooks: []testcontainers.ContainerLifecycleHooks{
+				{
+					PostCreates: []testcontainers.ContainerHook{
+						func(ctx context.Context, c testcontainers.Container) error {
+							return c.CopyToContainer(ctx, []byte(config), configPath, 0o644)
+						},
+					},
+				},
+			},
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NginxContainer{Container: container}, nil
+}
+
+// Route proxies the requests matching a path to an upstream
+type Route struct {
+	// Path is the prefix of the paths of the proxied requests, e.g. /api/
+	Path string
+	// Upstream is the URL the requests are proxied to, e.g. http://api:8080/. The host must be resolvable
+	// when nginx starts, e.g. the network alias of a running container on the same network.
+	Upstream string
+	// RequestHeaders are set on the requests sent to the upstream, e.g. X-Forwarded-Proto
+	RequestHeaders map[string]string
+	// ResponseHeaders are added to the responses sent to the client, e.g. Strict-Transport-Security
+	ResponseHeaders map[string]string
+}
+
+// WithRoutes proxies the requests matching the paths of the routes to their upstreams
+func WithRoutes(routes ...Route) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		for _, route := range routes {
+			withFile(fmt.Sprintf(routeConfigFmt, routeFileName(route.Path)), []byte(route.location()))(req)
+		}
+	}
+}
+
+// WithTLS terminates TLS on port 443 with the given PEM encoded certificate and key,
+// in addition to serving the routes over plain HTTP on port 80
+func WithTLS(certPEM []byte, keyPEM []byte) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.ExposedPorts = append(req.ExposedPorts, httpsPort)
+
+		tlsConfig := fmt.Sprintf("listen 443 ssl;\nssl_certificate %s;\nssl_certificate_key %s;\n", certPath, keyPath)
+
+		withFile(certPath, certPEM)(req)
+		withFile(keyPath, keyPEM)(req)
+		withFile(tlsConfigPath, []byte(tlsConfig))(req)
+	}
+}
+
+// withFile copies the content to the container once it is created, before nginx starts
+func withFile(containerPath string, content []byte) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithLifecycleHooks(testcontainers.ContainerLifecycleHooks{
+		PostCreates: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				return c.CopyToContainer(ctx, content, containerPath, 0o644)
+			},
+		},
+	})
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// routeFileName derives the name of the configuration file of a route from its path,
+// the paths are unique as nginx rejects duplicate locations
+func routeFileName(path string) string {
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(path, "_"), "_")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// location renders the route as a location block, with the headers sorted by name
+func (r Route) location() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "location %s {\n", r.Path)
+	fmt.Fprintf(&b, "    proxy_pass %s;\n", r.Upstream)
+	for _, name := range sortedKeys(r.RequestHeaders) {
+		fmt.Fprintf(&b, "    proxy_set_header %s %s;\n", name, quote(r.RequestHeaders[name]))
+	}
+	for _, name := range sortedKeys(r.ResponseHeaders) {
+		fmt.Fprintf(&b, "    add_header %s %s always;\n", name, quote(r.ResponseHeaders[name]))
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quote quotes a value of the nginx configuration, nginx variables like $host are still expanded
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// URL returns the base URL of the plain HTTP server
+func (c *NginxContainer) URL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, httpPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// HTTPSURL returns the base URL of the server terminating TLS, when enabled with WithTLS
+func (c *NginxContainer) HTTPSURL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, httpsPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://%s", address), nil
+}
+
+func (c *NginxContainer) address(ctx context.Context, port nat.Port) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mappedPort.Port()), nil
+}
@@ -0,0 +1,174 @@
+package nginx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestRouteLocation(t *testing.T) {
+	route := Route{
+		Path:            "/api/",
+		Upstream:        "http://api:8080/",
+		RequestHeaders:  map[string]string{"X-Forwarded-Proto": "$scheme", "Host": "$host"},
+		ResponseHeaders: map[string]string{"X-Proxy": `say "hi"`},
+	}
+
+	want := `location /api/ {
+    proxy_pass http://api:8080/;
+    proxy_set_header Host "$host";
+    proxy_set_header X-Forwarded-Proto "$scheme";
+    add_header X-Proxy "say \"hi\"" always;
+}
+`
+	if got := route.location(); got != want {
+		t.Fatalf("unexpected location:\n%s", got)
+	}
+
+	if got := routeFileName("/api/v1/"); got != "api_v1" {
+		t.Fatalf("unexpected file name %s", got)
+	}
+	if got := routeFileName("/"); got != "root" {
+		t.Fatalf("unexpected file name %s", got)
+	}
+}
+
+func TestNginx(t *testing.T) {
+	ctx := context.Background()
+
+	networkName := "nginx-" + uuid.NewString()
+	network, err := testcontainers.GenericNetwork(ctx, testcontainers.GenericNetworkRequest{
+		NetworkRequest: testcontainers.NetworkRequest{Name: networkName, CheckDuplicate: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := network.Remove(ctx); err != nil {
+			t.Fatalf("failed to remove network: %s", err)
+		}
+	})
+
+	upstream, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "docker.io/hashicorp/http-echo:0.2.3",
+			Cmd:            []string{"-text=hello from upstream"},
+			ExposedPorts:   []string{"5678/tcp"},
+			Networks:       []string{networkName},
+			NetworkAliases: map[string][]string{networkName: {"upstream"}},
+			WaitingFor:     wait.ForListeningPort("5678/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := upstream.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	certPEM, keyPEM := selfSignedCertificate(t)
+
+	container, err := RunContainer(ctx,
+		func(req *testcontainers.GenericContainerRequest) {
+			req.Networks = []string{networkName}
+		},
+		WithRoutes(Route{
+			Path:            "/api/",
+			Upstream:        "http://upstream:5678/",
+			ResponseHeaders: map[string]string{"X-Proxy": "nginx"},
+		}),
+		WithTLS(certPEM, keyPEM),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	url, err := container.URL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertProxied(t, http.DefaultClient, url+"/api/")
+
+	httpsURL, err := container.HTTPSURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // the certificate is self-signed
+	}}
+	assertProxied(t, client, httpsURL+"/api/")
+}
+
+func assertProxied(t *testing.T, client *http.Client, url string) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello from upstream\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if resp.Header.Get("X-Proxy") != "nginx" {
+		t.Fatalf("expected the response header to be added, got %v", resp.Header)
+	}
+}
+
+func selfSignedCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,125 @@
+package opa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrUndefined is returned when a decision is undefined, i.e. no rule of the policy produced a value
+var ErrUndefined = errors.New("undefined decision")
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Client is a client of the OPA REST API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the OPA server at baseURL, evaluating decisions against the policies and data it holds
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Decision evaluates the document at the given path of the data tree against the input, e.g. authz/allow,
+// and decodes it into result. ErrUndefined is returned if no rule produced a value.
+func (c *Client) Decision(ctx context.Context, dataPath string, input interface{}, result interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := c.do(ctx, http.MethodPost, dataURLPath(dataPath), "application/json", bytes.NewReader(data), &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Result) == 0 {
+		return fmt.Errorf("%w: %s", ErrUndefined, dataPath)
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Allowed evaluates a boolean decision, e.g. Allowed(ctx, "authz/allow", input). An undefined decision is
+// not allowed, as with the default deny of most policies.
+func (c *Client) Allowed(ctx context.Context, dataPath string, input interface{}) (bool, error) {
+	var allowed bool
+	err := c.Decision(ctx, dataPath, input, &allowed)
+	if errors.Is(err, ErrUndefined) {
+		return false, nil
+	}
+
+	return allowed, err
+}
+
+// PutPolicy creates or updates the policy with the given ID, failing if the module does not compile
+func (c *Client) PutPolicy(ctx context.Context, id string, module string) error {
+	return c.do(ctx, http.MethodPut, "/v1/policies/"+url.PathEscape(id), "text/plain", strings.NewReader(module), nil)
+}
+
+// DeletePolicy removes the policy with the given ID
+func (c *Client) DeletePolicy(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/policies/"+url.PathEscape(id), "", nil, nil)
+}
+
+// PutData creates or replaces the document at the given path of the data tree
+func (c *Client) PutData(ctx context.Context, dataPath string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPut, dataURLPath(dataPath), "application/json", bytes.NewReader(b), nil)
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, contentType string, body io.Reader, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var r errorResponse
+		if err := json.Unmarshal(respBody, &r); err == nil && r.Message != "" {
+			return fmt.Errorf("%s: %s", r.Code, r.Message)
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, data)
+}
@@ -0,0 +1,60 @@
+package opa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestClientAllowed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input map[string]string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/data/authz/allow":
+			_, _ = w.Write([]byte(`{"result":` + strconv.FormatBool(body.Input["user"] == "alice") + `}`))
+		case "/v1/data/authz/undefined":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"invalid_parameter","message":"invalid path"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		path string
+		user string
+		want bool
+	}{
+		{"authz/allow", "alice", true},
+		{"authz.allow", "bob", false},
+		{"authz/undefined", "alice", false},
+	}
+	for _, tt := range tests {
+		allowed, err := client.Allowed(ctx, tt.path, map[string]string{"user": tt.user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed != tt.want {
+			t.Fatalf("expected %s allowed for %s to be %t", tt.path, tt.user, tt.want)
+		}
+	}
+
+	_, err := client.Allowed(ctx, "other", nil)
+	if err == nil || err.Error() != "invalid_parameter: invalid path" {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+// Package opa provides a container running the Open Policy Agent server, with policies and data loaded
+// from Go values and a client of the decision API, to test authorization middleware against the real engine
+package opa
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net"
+	"path"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/openpolicyagent/opa:0.47.0"
+	apiPort      = "8181/tcp"
+)
+
+// OPAContainer represents the OPA container type used in the module
+type OPAContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the OPA container type, running the server without any policy
+// unless WithPolicies is given
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*OPAContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{apiPort},
+			Cmd:          []string{"run", "--server", "--addr", ":8181"},
+			WaitingFor:   wait.ForHTTP("/health").WithPort(apiPort),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OPAContainer{Container: container}, nil
+}
+
+// WithPolicies loads the .rego files of the file system once the server is ready, identified by their path,
+// e.g. WithPolicies(os.DirFS("policies")) or an embed.FS
+func WithPolicies(fsys fs.FS) testcontainers.CustomizeRequestOption {
+	return withClient(func(ctx context.Context, client *Client) error {
+		return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(p) != ".rego" {
+				return err
+			}
+
+			module, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+
+			return client.PutPolicy(ctx, p, string(module))
+		})
+	})
+}
+
+// WithData stores the document at the given path of the data tree once the server is ready,
+// e.g. WithData("roles", map[string][]string{"alice": {"admin"}}) to read it as data.roles in policies
+func WithData(dataPath string, data interface{}) testcontainers.CustomizeRequestOption {
+	return withClient(func(ctx context.Context, client *Client) error {
+		return client.PutData(ctx, dataPath, data)
+	})
+}
+
+// withClient calls the function with a client of the server once it is ready
+func withClient(fn func(ctx context.Context, client *Client) error) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithLifecycleHooks(testcontainers.ContainerLifecycleHooks{
+		PostReadies: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				url, err := baseURL(ctx, c)
+				if err != nil {
+					return err
+				}
+				return fn(ctx, NewClient(url))
+			},
+		},
+	})
+}
+
+// URL returns the base URL of the OPA REST API
+func (c *OPAContainer) URL(ctx context.Context) (string, error) {
+	return baseURL(ctx, c)
+}
+
+// Client returns a client of the OPA REST API, to query decisions and manage policies
+func (c *OPAContainer) Client(ctx context.Context) (*Client, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
+
+func baseURL(ctx context.Context, c testcontainers.Container) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, apiPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port.Port())), nil
+}
+
+// dataURLPath converts a path of the data tree in slash or dot notation, e.g. authz/allow or authz.allow,
+// to the path of the data API
+func dataURLPath(dataPath string) string {
+	return "/v1/data/" + strings.ReplaceAll(strings.Trim(dataPath, "/"), ".", "/")
+}
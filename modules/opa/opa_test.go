@@ -0,0 +1,51 @@
+package opa
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+const policy = `package authz
+
+default allow = false
+
+allow {
+	data.roles[input.user][_] == "admin"
+}
+`
+
+func TestOPA(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx,
+		WithPolicies(fstest.MapFS{
+			"authz/authz.rego": {Data: []byte(policy)},
+			"README.md":        {Data: []byte("not a policy")},
+		}),
+		WithData("roles", map[string][]string{"alice": {"admin"}, "bob": {"viewer"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for user, want := range map[string]bool{"alice": true, "bob": false} {
+		allowed, err := client.Allowed(ctx, "authz/allow", map[string]string{"user": user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed != want {
+			t.Fatalf("expected %s to be allowed: %t", user, want)
+		}
+	}
+}
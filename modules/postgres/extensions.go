@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// ErrIncompatibleExtensions is returned when the requested extensions are not shipped by a single image
+var ErrIncompatibleExtensions = errors.New("no image provides all the requested extensions")
+
+// extensionImages are the images providing the extensions not bundled with the official Postgres image,
+// for the major version of the default image
+var extensionImages = map[string]string{
+	"timescaledb":            "docker.io/timescale/timescaledb:2.9.1-pg15",
+	"postgis":                "docker.io/postgis/postgis:15-3.3-alpine",
+	"postgis_raster":         "docker.io/postgis/postgis:15-3.3-alpine",
+	"postgis_topology":       "docker.io/postgis/postgis:15-3.3-alpine",
+	"postgis_tiger_geocoder": "docker.io/postgis/postgis:15-3.3-alpine",
+}
+
+// extensionsScript is executed before the other init scripts, so that they can use the extensions
+const extensionsScript = initScriptsDir + "/000-testcontainers-extensions.sql"
+
+// extensionsLabel records the extensions requested with WithExtensions, so that RunContainer reports incompatible
+// extensions before the container is created, as options can't fail
+const extensionsLabel = "org.testcontainers.postgres.extensions"
+
+// WithExtensions creates the given extensions in the database on startup, e.g. "timescaledb", "postgis" or contrib
+// extensions like "pg_trgm". If an extension is not bundled with the official Postgres image, the image providing it
+// replaces the default one. A custom image set with testcontainers.WithImage before this option is kept as is
+// and must provide the extensions. RunContainer returns ErrIncompatibleExtensions if no image provides them all.
+func WithExtensions(extensions ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		if image, err := imageForExtensions(req.Image, extensions); err == nil {
+			req.Image = image
+		}
+
+		if req.Labels == nil {
+			req.Labels = make(map[string]string)
+		}
+		requested := extensions
+		if previous := req.Labels[extensionsLabel]; previous != "" {
+			requested = append(strings.Split(previous, ","), extensions...)
+		}
+		req.Labels[extensionsLabel] = strings.Join(requested, ",")
+
+		var script strings.Builder
+		for _, e := range extensions {
+			fmt.Fprintf(&script, "CREATE EXTENSION IF NOT EXISTS %q CASCADE;\n", e)
+		}
+
+		req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+			PostCreates: []testcontainers.ContainerHook{
+				func(ctx context.Context, c testcontainers.Container) error {
+					return c.CopyToContainer(ctx, []byte(script.String()), extensionsScript, 0o644)
+				},
+			},
+		})
+	}
+}
+
+// validateExtensions checks that the image of the request provides the extensions requested with WithExtensions
+func validateExtensions(req testcontainers.GenericContainerRequest) error {
+	requested, ok := req.Labels[extensionsLabel]
+	if !ok {
+		return nil
+	}
+	_, err := imageForExtensions(req.Image, strings.Split(requested, ","))
+	return err
+}
+
+// imageForExtensions returns the image providing all the extensions, starting from the current image of the request
+func imageForExtensions(current string, extensions []string) (string, error) {
+	image := current
+	for _, e := range extensions {
+		required, ok := extensionImages[e]
+		if !ok || required == image {
+			continue
+		}
+
+		switch {
+		case image == defaultImage:
+			image = required
+		case isExtensionImage(image):
+			return "", fmt.Errorf("%w: %s requires %s while another extension requires %s", ErrIncompatibleExtensions, e, required, image)
+		default:
+			// a custom image, trusted to provide the extensions
+		}
+	}
+
+	return image, nil
+}
+
+func isExtensionImage(image string) bool {
+	for _, i := range extensionImages {
+		if i == image {
+			return true
+		}
+	}
+	return false
+}
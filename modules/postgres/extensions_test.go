@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestImageForExtensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		image      string
+		extensions []string
+		want       string
+		wantErr    error
+	}{
+		{"contrib extensions", defaultImage, []string{"pg_trgm", "uuid-ossp"}, defaultImage, nil},
+		{"timescaledb", defaultImage, []string{"pg_trgm", "timescaledb"}, extensionImages["timescaledb"], nil},
+		{"postgis extensions", defaultImage, []string{"postgis", "postgis_topology"}, extensionImages["postgis"], nil},
+		{"custom image", "registry.local/postgres-all:15", []string{"timescaledb", "postgis"}, "registry.local/postgres-all:15", nil},
+		{"incompatible extensions", defaultImage, []string{"timescaledb", "postgis"}, "", ErrIncompatibleExtensions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imageForExtensions(tt.image, tt.extensions)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected image %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithExtensionsTimescaleDB(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx,
+		WithDatabase("metrics"),
+		WithExtensions("timescaledb", "pg_trgm"),
+		WithInitScripts("testdata/hypertable.sql"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	result, err := container.ExecWithResult(ctx, []string{
+		"psql", "-U", "postgres", "-d", "metrics", "-tA", "-c", "SELECT extname FROM pg_extension ORDER BY extname",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("psql failed: %s", result.Combined())
+	}
+
+	extensions := string(result.Stdout)
+	for _, e := range []string{"pg_trgm", "timescaledb"} {
+		if !strings.Contains(extensions, e) {
+			t.Fatalf("extension %s not created, got %q", e, extensions)
+		}
+	}
+}
+
+func TestWithExtensionsIncompatible(t *testing.T) {
+	// the error is returned before the container is created, a daemon is not needed
+	_, err := RunContainer(context.Background(), WithExtensions("timescaledb"), WithExtensions("postgis"))
+	if !errors.Is(err, ErrIncompatibleExtensions) {
+		t.Fatalf("expected ErrIncompatibleExtensions, got %v", err)
+	}
+}
+
+func TestWithExtensionsCustomImage(t *testing.T) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{Image: defaultImage},
+	}
+
+	testcontainers.WithImage("registry.local/postgres-all:15")(&req)
+	WithExtensions("timescaledb")(&req)
+
+	if req.Image != "registry.local/postgres-all:15" {
+		t.Fatalf("expected the custom image to be kept, got %s", req.Image)
+	}
+}
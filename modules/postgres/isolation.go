@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// CreateIsolatedDatabase creates a database dedicated to the test, owned by the superuser, and returns the URL
+// to connect to it, so that parallel tests can share a single container. The database is dropped once the test
+// completes, closing the connections left open. Args are appended as query parameters, like for ConnectionString.
+func (c *PostgresContainer) CreateIsolatedDatabase(ctx context.Context, t testing.TB, args ...string) (string, error) {
+	t.Helper()
+
+	name := testcontainers.IsolatedName(t)
+	if err := c.psql(ctx, c.database, fmt.Sprintf("CREATE DATABASE %q", name)); err != nil {
+		return "", err
+	}
+
+	t.Cleanup(func() {
+		// the context of the test may be done by then, forcing the drop requires Postgres 13 or later
+		if err := c.psql(context.Background(), c.database, fmt.Sprintf("DROP DATABASE IF EXISTS %q WITH (FORCE)", name)); err != nil {
+			t.Errorf("failed to drop the isolated database %s: %s", name, err)
+		}
+	})
+
+	return c.connectionString(ctx, name, args...)
+}
+
+// CreateIsolatedSchema creates a schema dedicated to the test in the database of the container, and returns the URL
+// to connect to the database with the schema as search path, so that the tables created without a schema go to it.
+// It is lighter than a database, but the extensions and the other schemas are shared. The schema is dropped with
+// all its objects once the test completes.
+func (c *PostgresContainer) CreateIsolatedSchema(ctx context.Context, t testing.TB, args ...string) (string, error) {
+	t.Helper()
+
+	name := testcontainers.IsolatedName(t)
+	if err := c.psql(ctx, c.database, fmt.Sprintf("CREATE SCHEMA %q", name)); err != nil {
+		return "", err
+	}
+
+	t.Cleanup(func() {
+		if err := c.psql(context.Background(), c.database, fmt.Sprintf("DROP SCHEMA IF EXISTS %q CASCADE", name)); err != nil {
+			t.Errorf("failed to drop the isolated schema %s: %s", name, err)
+		}
+	})
+
+	// the options are passed to the server on connection, the value is URL encoded
+	return c.connectionString(ctx, c.database, append([]string{"options=-csearch_path%3D" + name}, args...)...)
+}
+
+// psql executes the SQL statement in the database with psql in the container, as the superuser
+func (c *PostgresContainer) psql(ctx context.Context, database string, statement string) error {
+	result, err := c.ExecWithResult(ctx, []string{"psql", "-U", c.user, "-d", database, "-v", "ON_ERROR_STOP=1", "-c", statement})
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("psql exited with code %d: %s", result.ExitCode, result.Combined())
+	}
+
+	return nil
+}
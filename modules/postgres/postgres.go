@@ -0,0 +1,174 @@
+// Package postgres provides a container running PostgreSQL, optionally with extensions like TimescaleDB or PostGIS
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage    = "docker.io/postgres:15-alpine"
+	defaultUser     = "postgres"
+	defaultPassword = "postgres"
+	defaultDatabase = "postgres"
+	port            = "5432/tcp"
+	initScriptsDir  = "/docker-entrypoint-initdb.d"
+	fixturesDir     = "/tmp/fixtures"
+)
+
+// PostgresContainer represents the Postgres container type used in the module
+type PostgresContainer struct {
+	testcontainers.Container
+	user     string
+	password string
+	database string
+}
+
+// RunContainer creates an instance of the Postgres container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*PostgresContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{port},
+			Env: map[string]string{
+				"POSTGRES_USER":     defaultUser,
+				"POSTGRES_PASSWORD": defaultPassword,
+				"POSTGRES_DB":       defaultDatabase,
+			},
+			// the server is restarted once the database is initialized
+			WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	if err := validateExtensions(req); err != nil {
+		return nil, err
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresContainer{
+		Container: container,
+		user:      req.Env["POSTGRES_USER"],
+		password:  req.Env["POSTGRES_PASSWORD"],
+		database:  req.Env["POSTGRES_DB"],
+	}, nil
+}
+
+// FromContainer wraps a container running PostgreSQL, e.g. the container of a compose service, in a PostgresContainer.
+// The superuser, its password and the database are read from the environment of the container, with the defaults of
+// the image:
+//
+//	db, err := testcontainers.ServiceAs(ctx, stack, "db", postgres.FromContainer)
+func FromContainer(ctx context.Context, container testcontainers.Container) (*PostgresContainer, error) {
+	env, err := testcontainers.ContainerEnv(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+
+	user := env["POSTGRES_USER"]
+	if user == "" {
+		user = defaultUser
+	}
+	database := env["POSTGRES_DB"]
+	if database == "" {
+		// the image names the database after the superuser
+		database = user
+	}
+
+	return &PostgresContainer{
+		Container: container,
+		user:      user,
+		password:  env["POSTGRES_PASSWORD"],
+		database:  database,
+	}, nil
+}
+
+// WithDatabase sets the name of the database created on startup
+func WithDatabase(database string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env["POSTGRES_DB"] = database
+	}
+}
+
+// WithUsername sets the name of the superuser created on startup
+func WithUsername(user string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env["POSTGRES_USER"] = user
+	}
+}
+
+// WithPassword sets the password of the superuser
+func WithPassword(password string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env["POSTGRES_PASSWORD"] = password
+	}
+}
+
+// WithInitScripts executes the given .sql or .sh files when the database is created, in the order of their names
+func WithInitScripts(paths ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		for _, p := range paths {
+			req.Files = append(req.Files, testcontainers.ContainerFile{
+				HostFilePath:      p,
+				ContainerFilePath: initScriptsDir + "/" + filepath.Base(p),
+				FileMode:          0o644,
+			})
+		}
+	}
+}
+
+// WithFixtures executes the .sql files of the file system once the database is ready, in the order of their paths,
+// as the superuser in the database created on startup. Unlike init scripts, they are loaded from the tests,
+// e.g. with embed.FS, and a failing statement fails the creation of the container.
+func WithFixtures(fixtures fs.FS) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		seeder := testcontainers.ExecSeeder{
+			Pattern: "*.sql",
+			Command: func(file string) []string {
+				// the user and the database are read once all the options are applied
+				return []string{"psql", "-U", req.Env["POSTGRES_USER"], "-d", req.Env["POSTGRES_DB"], "-v", "ON_ERROR_STOP=1", "-f", file}
+			},
+		}
+		testcontainers.WithSeed(seeder, fixtures, fixturesDir)(req)
+	}
+}
+
+// ConnectionString returns the URL to connect to the database, args are appended as query parameters
+// e.g. ConnectionString(ctx, "sslmode=disable")
+func (c *PostgresContainer) ConnectionString(ctx context.Context, args ...string) (string, error) {
+	return c.connectionString(ctx, c.database, args...)
+}
+
+func (c *PostgresContainer) connectionString(ctx context.Context, database string, args ...string) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("postgres://%s:%s@%s/%s", c.user, c.password, net.JoinHostPort(host, mappedPort.Port()), database)
+	if len(args) > 0 {
+		url += "?" + strings.Join(args, "&")
+	}
+
+	return url, nil
+}
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestPostgres(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx,
+		WithDatabase("app"),
+		WithUsername("app"),
+		WithPassword("secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	url, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "postgres://app:secret@") || !strings.HasSuffix(url, "/app?sslmode=disable") {
+		t.Fatalf("unexpected connection string %s", url)
+	}
+
+	result, err := container.ExecWithResult(ctx, []string{"psql", "-U", "app", "-d", "app", "-tA", "-c", "SELECT 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "1\n" {
+		t.Fatalf("unexpected output %q", result.Combined())
+	}
+}
+
+func TestPostgresFromComposeService(t *testing.T) {
+	ctx := context.Background()
+
+	stack, err := testcontainers.NewDockerCompose("../../testresources/docker-compose-postgres.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := stack.Down(ctx, testcontainers.RemoveOrphans(true)); err != nil {
+			t.Fatalf("failed to tear down stack: %s", err)
+		}
+	})
+
+	err = stack.
+		WaitForService("postgres", wait.ForLog("database system is ready to accept connections").WithOccurrence(2)).
+		Up(ctx, testcontainers.Wait(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	container, err := testcontainers.ServiceAs(ctx, stack, "postgres", FromContainer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "postgres://postgres:s3cr3t@") || !strings.HasSuffix(url, ":15432/postgres?sslmode=disable") {
+		t.Fatalf("unexpected connection string %s", url)
+	}
+
+	if err := container.psql(ctx, "postgres", "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostgresFixtures(t *testing.T) {
+	ctx := context.Background()
+
+	fixtures := fstest.MapFS{
+		"01_schema.sql":       {Data: []byte("CREATE TABLE users (name TEXT NOT NULL);")},
+		"02_data/users.sql":   {Data: []byte("INSERT INTO users VALUES ('alice'), ('bob');")},
+		"02_data/README.md":   {Data: []byte("not a fixture")},
+		"03_data/invalid.txt": {Data: []byte("not SQL")},
+	}
+
+	container, err := RunContainer(ctx, WithFixtures(fixtures), WithUsername("app"), WithDatabase("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	result, err := container.ExecWithResult(ctx, []string{"psql", "-U", "app", "-d", "app", "-tA", "-c", "SELECT count(*) FROM users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "2\n" {
+		t.Fatalf("unexpected output %q", result.Combined())
+	}
+
+	_, err = RunContainer(ctx, WithFixtures(fstest.MapFS{"broken.sql": {Data: []byte("SELECT FROM missing;")}}))
+	if err == nil || !strings.Contains(err.Error(), "failed to load fixture /tmp/fixtures/broken.sql") {
+		t.Fatalf("expected the fixture to fail, got %v", err)
+	}
+}
+
+func TestPostgresIsolation(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	var database, schema string
+	t.Run("isolated", func(t *testing.T) {
+		url, err := container.CreateIsolatedDatabase(ctx, t, "sslmode=disable")
+		if err != nil {
+			t.Fatal(err)
+		}
+		database = url[strings.LastIndex(url, "/")+1 : strings.Index(url, "?")]
+		if !databaseExists(t, container, database) {
+			t.Fatalf("expected database %s to exist", database)
+		}
+
+		url, err = container.CreateIsolatedSchema(ctx, t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema = url[strings.LastIndex(url, "%3D")+3:]
+		if !strings.Contains(url, "/postgres?options=-csearch_path%3Dt_testpostgresisolation_isolated_") {
+			t.Fatalf("unexpected connection string %s", url)
+		}
+	})
+
+	if databaseExists(t, container, database) {
+		t.Fatalf("expected database %s to be dropped with the test", database)
+	}
+
+	result, err := container.ExecWithResult(ctx, []string{"psql", "-U", "postgres", "-tA", "-c",
+		"SELECT count(*) FROM information_schema.schemata WHERE schema_name = '" + schema + "'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "0\n" {
+		t.Fatalf("expected schema %s to be dropped with the test, got %q", schema, result.Combined())
+	}
+}
+
+func databaseExists(t *testing.T, container *PostgresContainer, database string) bool {
+	t.Helper()
+
+	result, err := container.ExecWithResult(context.Background(), []string{"psql", "-U", "postgres", "-tA", "-c",
+		"SELECT count(*) FROM pg_database WHERE datname = '" + database + "'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(result.Stdout) == "1\n"
+}
@@ -0,0 +1,2 @@
+CREATE TABLE readings (time TIMESTAMPTZ NOT NULL, value DOUBLE PRECISION);
+SELECT create_hypertable('readings', 'time');
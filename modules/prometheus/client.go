@@ -0,0 +1,209 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sample is a value of a time series at a given time
+type Sample struct {
+	Metric    map[string]string
+	Value     float64
+	Timestamp time.Time
+}
+
+// Alert is an alert that is pending or firing
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"` // pending or firing
+	ActiveAt    time.Time         `json:"activeAt"`
+	Value       string            `json:"value"`
+}
+
+// Target is a scrape target and the status of its last scrape
+type Target struct {
+	Labels     map[string]string `json:"labels"`
+	ScrapeURL  string            `json:"scrapeUrl"`
+	Health     string            `json:"health"` // up, down or unknown
+	LastError  string            `json:"lastError"`
+	LastScrape time.Time         `json:"lastScrape"`
+}
+
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+type vectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+// Client is a client of the Prometheus HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the Prometheus server at baseURL, e.g. http://localhost:9090, to run PromQL queries
+// and list the alerts
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Query evaluates an instant PromQL query returning a vector, e.g. `http_requests_total{code="500"}`
+func (c *Client) Query(ctx context.Context, query string) ([]Sample, error) {
+	var result struct {
+		ResultType string         `json:"resultType"`
+		Result     []vectorSample `json:"result"`
+	}
+	if err := c.get(ctx, "/api/v1/query?query="+url.QueryEscape(query), &result); err != nil {
+		return nil, err
+	}
+
+	if result.ResultType != "vector" {
+		return nil, fmt.Errorf("query %q returned a %s instead of a vector", query, result.ResultType)
+	}
+
+	samples := make([]Sample, 0, len(result.Result))
+	for _, r := range result.Result {
+		sample, err := parseSample(r)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// WaitForQuery polls the query until it returns at least one sample, as metrics are only
+// available once the targets were scraped or the rules evaluated
+func (c *Client) WaitForQuery(ctx context.Context, query string) ([]Sample, error) {
+	for {
+		samples, err := c.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) > 0 {
+			return samples, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: no sample returned by query %q", ctx.Err(), query)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Alerts lists the pending and firing alerts
+func (c *Client) Alerts(ctx context.Context) ([]Alert, error) {
+	var result struct {
+		Alerts []Alert `json:"alerts"`
+	}
+	if err := c.get(ctx, "/api/v1/alerts", &result); err != nil {
+		return nil, err
+	}
+
+	return result.Alerts, nil
+}
+
+// WaitForAlert polls the alerts until the one with the given name is firing
+func (c *Client) WaitForAlert(ctx context.Context, name string) (*Alert, error) {
+	for {
+		alerts, err := c.Alerts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range alerts {
+			if alerts[i].Labels["alertname"] == name && alerts[i].State == "firing" {
+				return &alerts[i], nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: alert %s not firing", ctx.Err(), name)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Targets lists the active scrape targets
+func (c *Client) Targets(ctx context.Context) ([]Target, error) {
+	var result struct {
+		ActiveTargets []Target `json:"activeTargets"`
+	}
+	if err := c.get(ctx, "/api/v1/targets?state=active", &result); err != nil {
+		return nil, err
+	}
+
+	return result.ActiveTargets, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, data interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var r apiResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return fmt.Errorf("%w: unexpected response with status %d: %s", err, resp.StatusCode, body)
+	}
+
+	if r.Status != "success" {
+		return fmt.Errorf("%s: %s", r.ErrorType, r.Error)
+	}
+
+	return json.Unmarshal(r.Data, data)
+}
+
+func parseSample(r vectorSample) (Sample, error) {
+	ts, ok := r.Value[0].(float64)
+	if !ok {
+		return Sample{}, errors.New("invalid sample timestamp")
+	}
+
+	raw, ok := r.Value[1].(string)
+	if !ok {
+		return Sample{}, errors.New("invalid sample value")
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("%w: invalid sample value", err)
+	}
+
+	return Sample{
+		Metric:    r.Metric,
+		Value:     value,
+		Timestamp: time.UnixMilli(int64(ts * 1000)),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "invalid(" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"self"},"value":[1669000000.5,"42"]}]}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	samples, err := client.Query(context.Background(), "up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || samples[0].Value != 42 || samples[0].Metric["job"] != "self" {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+	if samples[0].Timestamp.UnixMilli() != 1669000000500 {
+		t.Fatalf("unexpected timestamp %v", samples[0].Timestamp)
+	}
+
+	_, err = client.Query(context.Background(), "invalid(")
+	if err == nil || err.Error() != "bad_data: parse error" {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
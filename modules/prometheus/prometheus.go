@@ -0,0 +1,161 @@
+// Package prometheus provides a container running Prometheus, scraping test containers or receiving remote writes,
+// with helpers to query the collected metrics and the alerts, to integration test exporters and alerting rules
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/prom/prometheus:v2.40.1"
+	apiPort      = "9090/tcp"
+
+	configDir     = "/etc/prometheus"
+	configPath    = configDir + "/prometheus.yml"
+	targetsPrefix = "targets-"
+	rulesPrefix   = "rules-"
+	storagePath   = "/prometheus"
+)
+
+// config scrapes the targets and evaluates the rules copied by the options,
+// with short intervals so that tests don't wait long for samples and alerts
+const config = `global:
+  scrape_interval: 1s
+  evaluation_interval: 1s
+rule_files:
+  - ` + configDir + `/` + rulesPrefix + `*
+scrape_configs:
+  - job_name: testcontainers
+    file_sd_configs:
+      - files:
+          - ` + configDir + `/` + targetsPrefix + `*.json
+`
+
+// PrometheusContainer represents the Prometheus container type used in the module
+type PrometheusContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Prometheus container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*PrometheusContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{apiPort},
+			Cmd: []string{
+				"--config.file=" + configPath,
+				"--storage.tsdb.path=" + storagePath,
+				"--web.enable-lifecycle",
+			},
+			WaitingFor: wait.ForHTTP("/-/ready").WithPort(apiPort),
+			LifecycleHooks: []testcontainers.ContainerLifecycleHooks{
+				{
+					PostCreates: []testcontainers.ContainerHook{
+						func(ctx context.Context, c testcontainers.Container) error {
+							return c.CopyToContainer(ctx, []byte(config), configPath, 0o644)
+						},
+					},
+				},
+			},
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrometheusContainer{Container: container}, nil
+}
+
+// targetGroup is a group of targets in the file service discovery format
+type targetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// WithScrapeTargets scrapes the /metrics endpoint of the given host:port targets, labelled with the job name.
+// The targets must be reachable from the Prometheus container, e.g. through a network alias.
+func WithScrapeTargets(job string, targets ...string) testcontainers.CustomizeRequestOption {
+	return withFile(path.Join(configDir, targetsPrefix+job+".json"), func() ([]byte, error) {
+		return json.Marshal([]targetGroup{
+			{Targets: targets, Labels: map[string]string{"job": job}},
+		})
+	})
+}
+
+// WithRules loads the given alerting or recording rules, in the Prometheus rule file format
+func WithRules(name string, rules []byte) testcontainers.CustomizeRequestOption {
+	return withFile(path.Join(configDir, rulesPrefix+name), func() ([]byte, error) {
+		return rules, nil
+	})
+}
+
+// WithRemoteWriteReceiver accepts samples pushed to the remote write endpoint, see RemoteWriteURL
+func WithRemoteWriteReceiver() testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Cmd = append(req.Cmd, "--web.enable-remote-write-receiver")
+	}
+}
+
+// withFile copies the content to the container once it is created, before Prometheus starts
+func withFile(containerPath string, content func() ([]byte, error)) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithLifecycleHooks(testcontainers.ContainerLifecycleHooks{
+		PostCreates: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				b, err := content()
+				if err != nil {
+					return err
+				}
+				return c.CopyToContainer(ctx, b, containerPath, 0o644)
+			},
+		},
+	})
+}
+
+// URL returns the base URL of the Prometheus HTTP API and web interface
+func (c *PrometheusContainer) URL(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, apiPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, port.Port())), nil
+}
+
+// RemoteWriteURL returns the URL samples are pushed to when the remote write receiver is enabled
+func (c *PrometheusContainer) RemoteWriteURL(ctx context.Context) (string, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return url + "/api/v1/write", nil
+}
+
+// Client returns a client of the Prometheus HTTP API, to query the metrics and the alerts
+func (c *PrometheusContainer) Client(ctx context.Context) (*Client, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const rules = `groups:
+  - name: test
+    rules:
+      - alert: AlwaysFiring
+        expr: vector(1) > 0
+        labels:
+          severity: critical
+`
+
+func TestPrometheus(t *testing.T) {
+	ctx := context.Background()
+
+	// Prometheus scrapes itself, as any container reachable from it
+	container, err := RunContainer(ctx,
+		WithScrapeTargets("self", "localhost:9090"),
+		WithRules("test.yml", []byte(rules)),
+		WithRemoteWriteReceiver(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	samples, err := client.WaitForQuery(waitCtx, `up{job="self"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if samples[0].Value != 1 {
+		t.Fatalf("expected the target to be up, got %v", samples[0].Value)
+	}
+
+	alert, err := client.WaitForAlert(waitCtx, "AlwaysFiring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alert.Labels["severity"] != "critical" {
+		t.Fatalf("unexpected alert labels %v", alert.Labels)
+	}
+
+	targets, err := client.Targets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].Labels["job"] != "self" {
+		t.Fatalf("unexpected targets %+v", targets)
+	}
+}
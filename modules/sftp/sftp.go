@@ -0,0 +1,180 @@
+// Package sftp provides a container running an SFTP server, for file transfer integration tests
+package sftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/atmoz/sftp:alpine"
+	sftpPort     = "22/tcp"
+	hostKeyPath  = "/etc/ssh/ssh_host_ed25519_key.pub"
+)
+
+// ErrNoUsers is returned when the server is started without any user, which it does not support
+var ErrNoUsers = errors.New("the SFTP server requires at least one user, see WithUsers")
+
+// User is an account of the SFTP server, chrooted in /home/<Name>
+type User struct {
+	Name     string
+	Password string
+	UID      int      // optional, assigned by the server if zero
+	GID      int      // optional, assigned by the server if zero
+	Dirs     []string // directories created in the home of the user, the only places the user can write to
+	Files    fs.FS    // optional content copied to the home of the user once the server is ready
+}
+
+// spec formats the user the way the server expects it: name:password[:uid[:gid[:dir1[,dir2]...]]]
+func (u User) spec() string {
+	fields := []string{u.Name, u.Password, "", "", strings.Join(u.Dirs, ",")}
+	if u.UID > 0 {
+		fields[2] = strconv.Itoa(u.UID)
+	}
+	if u.GID > 0 {
+		fields[3] = strconv.Itoa(u.GID)
+	}
+	return strings.TrimRight(strings.Join(fields, ":"), ":")
+}
+
+// SFTPContainer represents the SFTP container type used in the module
+type SFTPContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the SFTP container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*SFTPContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{sftpPort},
+			// the users are created before the SSH server starts
+			WaitingFor: wait.ForSSH(sftpPort),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	if len(req.Cmd) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SFTPContainer{Container: container}, nil
+}
+
+// WithUsers declares the accounts of the server, and copies their files once it is ready
+func WithUsers(users ...User) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		for _, u := range users {
+			req.Cmd = append(req.Cmd, u.spec())
+
+			if u.Files == nil {
+				continue
+			}
+
+			u := u
+			req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+				PostReadies: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						return copyHome(ctx, c, u)
+					},
+				},
+			})
+		}
+	}
+}
+
+// copyHome copies the files of the user to its home directory, owned by the user
+func copyHome(ctx context.Context, c testcontainers.Container, u User) error {
+	home := path.Join("/home", u.Name)
+
+	return fs.WalkDir(u.Files, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == "." {
+			return err
+		}
+
+		target := path.Join(home, p)
+		if d.IsDir() {
+			return exec(ctx, c, "mkdir", "-p", target)
+		}
+
+		content, err := fs.ReadFile(u.Files, p)
+		if err != nil {
+			return err
+		}
+		if err := c.CopyToContainer(ctx, content, target, 0o644); err != nil {
+			return fmt.Errorf("%w: failed to copy %s", err, p)
+		}
+
+		// the home itself must stay owned by root for the chroot to work
+		return exec(ctx, c, "chown", "-R", u.Name, path.Join(home, strings.Split(p, "/")[0]))
+	})
+}
+
+func exec(ctx context.Context, c testcontainers.Container, cmd ...string) error {
+	code, _, err := c.Exec(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("%s exited with code %d", strings.Join(cmd, " "), code)
+	}
+	return nil
+}
+
+// Address returns the host:port the SFTP server is reachable at
+func (c *SFTPContainer) Address(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, sftpPort)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, port.Port()), nil
+}
+
+// HostKey returns the public key of the server, generated when it started,
+// so that clients can verify it instead of ignoring it
+func (c *SFTPContainer) HostKey(ctx context.Context) (ssh.PublicKey, error) {
+	r, err := c.CopyFileFromContainer(ctx, hostKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid host key", err)
+	}
+
+	return key, nil
+}
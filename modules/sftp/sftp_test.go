@@ -0,0 +1,97 @@
+package sftp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSFTP(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx, WithUsers(User{
+		Name:     "foo",
+		Password: "pass",
+		UID:      1001,
+		Dirs:     []string{"upload"},
+		Files:    os.DirFS("testdata"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	address, err := container.Address(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostKey, err := container.HostKey(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
+		User:            "foo",
+		Auth:            []ssh.AuthMethod{ssh.Password("pass")},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	r, err := container.CopyFileFromContainer(ctx, "/home/foo/site/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "<h1>hello</h1>\n" {
+		t.Fatalf("unexpected content: %q", content)
+	}
+
+	result, err := container.ExecWithResult(ctx, []string{"stat", "-c", "%u", "/home/foo/site/assets/style.css"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "1001\n" {
+		t.Fatalf("expected the file to be owned by the user, got %q", result.Stdout)
+	}
+}
+
+func TestSFTPRequiresUsers(t *testing.T) {
+	_, err := RunContainer(context.Background())
+	if !errors.Is(err, ErrNoUsers) {
+		t.Fatalf("expected ErrNoUsers, got %v", err)
+	}
+}
+
+func TestUserSpec(t *testing.T) {
+	tests := []struct {
+		user User
+		want string
+	}{
+		{User{Name: "foo", Password: "pass"}, "foo:pass"},
+		{User{Name: "foo", Password: "pass", Dirs: []string{"upload"}}, "foo:pass:::upload"},
+		{User{Name: "foo", Password: "pass", UID: 1001, GID: 100, Dirs: []string{"in", "out"}}, "foo:pass:1001:100:in,out"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.user.spec(); got != tt.want {
+			t.Errorf("spec() = %q, want %q", got, tt.want)
+		}
+	}
+}
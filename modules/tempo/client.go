@@ -0,0 +1,148 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrTraceNotFound is returned when no trace has the given ID, e.g. because it was not ingested yet
+var ErrTraceNotFound = errors.New("trace not found")
+
+// Span is a span of a trace
+type Span struct {
+	Name        string
+	ServiceName string
+	Attributes  map[string]string // string attributes of the span
+}
+
+// Trace is a trace as returned by the query API
+type Trace struct {
+	Spans []Span
+}
+
+// SpanNames returns the names of the spans of the trace
+func (t *Trace) SpanNames() []string {
+	names := make([]string, 0, len(t.Spans))
+	for _, s := range t.Spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+type attribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type scopeSpans struct {
+	Spans []struct {
+		Name       string      `json:"name"`
+		Attributes []attribute `json:"attributes"`
+	} `json:"spans"`
+}
+
+type traceResponse struct {
+	Batches []struct {
+		Resource struct {
+			Attributes []attribute `json:"attributes"`
+		} `json:"resource"`
+		// the name of the field depends on the version of the OTLP protocol used by Tempo
+		ScopeSpans                  []scopeSpans `json:"scopeSpans"`
+		InstrumentationLibrarySpans []scopeSpans `json:"instrumentationLibrarySpans"`
+	} `json:"batches"`
+}
+
+// Client is a client of the Tempo query API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the Tempo query frontend at baseURL, to fetch and search the ingested traces
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Trace returns the trace with the given hex encoded ID
+func (c *Client) Trace(ctx context.Context, traceID string) (*Trace, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/traces/"+url.PathEscape(traceID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrTraceNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d querying trace %s: %s", resp.StatusCode, traceID, b)
+	}
+
+	var r traceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	trace := &Trace{}
+	for _, batch := range r.Batches {
+		serviceName := attributes(batch.Resource.Attributes)["service.name"]
+		for _, scope := range append(batch.ScopeSpans, batch.InstrumentationLibrarySpans...) {
+			for _, s := range scope.Spans {
+				trace.Spans = append(trace.Spans, Span{
+					Name:        s.Name,
+					ServiceName: serviceName,
+					Attributes:  attributes(s.Attributes),
+				})
+			}
+		}
+	}
+
+	return trace, nil
+}
+
+// WaitForTrace polls the query API until the trace with the given ID holds at least spans spans,
+// as traces are exported and ingested asynchronously
+func (c *Client) WaitForTrace(ctx context.Context, traceID string, spans int) (*Trace, error) {
+	for {
+		trace, err := c.Trace(ctx, traceID)
+		if err != nil && !errors.Is(err, ErrTraceNotFound) {
+			return nil, err
+		}
+		if trace != nil && len(trace.Spans) >= spans {
+			return trace, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: trace %s with %d spans not found", ctx.Err(), traceID, spans)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func attributes(attrs []attribute) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Key] = a.Value.StringValue
+	}
+	return m
+}
@@ -0,0 +1,48 @@
+package tempo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/traces/v1":
+			_, _ = w.Write([]byte(`{"batches":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+				"instrumentationLibrarySpans":[{"spans":[{"name":"get /api","attributes":[{"key":"http.method","value":{"stringValue":"GET"}}]}]}]}]}`))
+		case "/api/traces/v2":
+			_, _ = w.Write([]byte(`{"batches":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"db"}}]},
+				"scopeSpans":[{"spans":[{"name":"select"},{"name":"insert"}]}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL)
+
+	trace, err := client.Trace(context.Background(), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Spans) != 1 || trace.Spans[0].ServiceName != "api" || trace.Spans[0].Attributes["http.method"] != "GET" {
+		t.Fatalf("unexpected trace %+v", trace)
+	}
+
+	trace, err = client.Trace(context.Background(), "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := trace.SpanNames(); len(names) != 2 || names[0] != "select" || names[1] != "insert" {
+		t.Fatalf("unexpected spans %v", names)
+	}
+
+	_, err = client.Trace(context.Background(), "unknown")
+	if !errors.Is(err, ErrTraceNotFound) {
+		t.Fatalf("expected ErrTraceNotFound, got %v", err)
+	}
+}
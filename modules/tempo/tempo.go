@@ -0,0 +1,140 @@
+// Package tempo provides a container running Grafana Tempo, receiving traces over OTLP and Zipkin,
+// with a client to query them, to integration test trace pipelines
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/grafana/tempo:1.5.0"
+	httpPort     = "3200/tcp"
+	otlpGRPCPort = "4317/tcp"
+	otlpHTTPPort = "4318/tcp"
+	zipkinPort   = "9411/tcp"
+	configPath   = "/etc/tempo.yaml"
+)
+
+// config enables the OTLP and Zipkin receivers, and stores the traces in the container
+const config = `server:
+  http_listen_port: 3200
+distributor:
+  receivers:
+    otlp:
+      protocols:
+        grpc:
+        http:
+    zipkin:
+storage:
+  trace:
+    backend: local
+    wal:
+      path: /tmp/tempo/wal
+    local:
+      path: /tmp/tempo/blocks
+`
+
+// TempoContainer represents the Tempo container type used in the module
+type TempoContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Tempo container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*TempoContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{httpPort, otlpGRPCPort, otlpHTTPPort, zipkinPort},
+			Cmd:          []string{"-config.file=" + configPath},
+			// the ingester only reports ready some seconds after it joined its ring
+			WaitingFor: wait.ForHTTP("/ready").WithPort(httpPort),
+			LifecycleHooks: []testcontainers.ContainerLifecycleHooks{
+				{
+					PostCreates: []testcontainers.ContainerHook{
+						func(ctx context.Context, c testcontainers.Container) error {
+							return c.CopyToContainer(ctx, []byte(config), configPath, 0o644)
+						},
+					},
+				},
+			},
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TempoContainer{Container: container}, nil
+}
+
+// URL returns the base URL of the Tempo query API
+func (c *TempoContainer) URL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, httpPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// OTLPGRPCEndpoint returns the host:port of the OTLP gRPC receiver, without TLS
+func (c *TempoContainer) OTLPGRPCEndpoint(ctx context.Context) (string, error) {
+	return c.address(ctx, otlpGRPCPort)
+}
+
+// OTLPHTTPURL returns the base URL of the OTLP HTTP receiver, traces are pushed to its /v1/traces path
+func (c *TempoContainer) OTLPHTTPURL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, otlpHTTPPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// ZipkinURL returns the URL Zipkin spans are pushed to
+func (c *TempoContainer) ZipkinURL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx, zipkinPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s/api/v2/spans", address), nil
+}
+
+// Client returns a client of the Tempo query API
+func (c *TempoContainer) Client(ctx context.Context) (*Client, error) {
+	url, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(url), nil
+}
+
+func (c *TempoContainer) address(ctx context.Context, port nat.Port) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mappedPort.Port()), nil
+}
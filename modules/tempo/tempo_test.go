@@ -0,0 +1,69 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const traceID = "463ac35c9f6413ad48485a3953bb6124"
+
+func TestTempo(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	zipkinURL, err := container.ZipkinURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := fmt.Sprintf(`[{
+		"traceId": %q,
+		"id": "a2fb4a1d1a96d312",
+		"name": "get /api",
+		"kind": "SERVER",
+		"timestamp": %d,
+		"duration": 1000,
+		"localEndpoint": {"serviceName": "api"},
+		"tags": {"http.method": "GET"}
+	}]`, traceID, time.Now().UnixMicro())
+
+	resp, err := http.Post(zipkinURL, "application/json", strings.NewReader(spans))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("unexpected status %d pushing spans", resp.StatusCode)
+	}
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	trace, err := client.WaitForTrace(waitCtx, traceID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span := trace.Spans[0]
+	if span.Name != "get /api" || span.ServiceName != "api" || span.Attributes["http.method"] != "GET" {
+		t.Fatalf("unexpected span %+v", span)
+	}
+}
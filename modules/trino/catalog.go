@@ -0,0 +1,83 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// catalogDir is the directory Trino loads the catalog properties files from
+const catalogDir = "/etc/trino/catalog"
+
+// Catalog describes a Trino catalog, written to the <Name>.properties file of the catalog directory
+type Catalog struct {
+	// Name is the name of the catalog in queries, e.g. pg for SELECT * FROM pg.public.users
+	Name string
+	// Connector is the connector of the catalog, e.g. postgresql, memory or tpch
+	Connector string
+	// Properties are the properties of the connector, e.g. connection-url
+	Properties map[string]string
+}
+
+// PostgresCatalog returns a catalog querying a PostgreSQL database through the given JDBC URL. The host of the URL
+// must be reachable from the Trino container, e.g. the network alias of a Postgres container on the same network.
+func PostgresCatalog(name string, jdbcURL string, user string, password string) Catalog {
+	return Catalog{
+		Name:      name,
+		Connector: "postgresql",
+		Properties: map[string]string{
+			"connection-url":      jdbcURL,
+			"connection-user":     user,
+			"connection-password": password,
+		},
+	}
+}
+
+// MemoryCatalog returns a catalog storing its tables in the memory of the Trino container
+func MemoryCatalog(name string) Catalog {
+	return Catalog{Name: name, Connector: "memory"}
+}
+
+// WithCatalogs adds the given catalogs to the ones shipped by the image, replacing those of the same name
+func WithCatalogs(catalogs ...Catalog) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+			PostCreates: []testcontainers.ContainerHook{
+				func(ctx context.Context, c testcontainers.Container) error {
+					for _, catalog := range catalogs {
+						path := fmt.Sprintf("%s/%s.properties", catalogDir, catalog.Name)
+						if err := c.CopyToContainer(ctx, []byte(catalog.properties()), path, 0o644); err != nil {
+							return fmt.Errorf("%w: failed to write catalog %s", err, catalog.Name)
+						}
+					}
+					return nil
+				},
+			},
+		})
+	}
+}
+
+// properties renders the catalog as a Java properties file, with the properties sorted by key
+func (c Catalog) properties() string {
+	keys := make([]string, 0, len(c.Properties))
+	for k := range c.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "connector.name=%s\n", escapeProperty(c.Connector))
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", k, escapeProperty(c.Properties[k]))
+	}
+
+	return b.String()
+}
+
+// escapeProperty escapes the characters having a meaning in the values of a Java properties file
+func escapeProperty(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(value)
+}
@@ -0,0 +1,20 @@
+package trino
+
+import "testing"
+
+func TestCatalogProperties(t *testing.T) {
+	catalog := PostgresCatalog("pg", "jdbc:postgresql://postgres:5432/test", "postgres", `pa\ss`)
+
+	want := `connector.name=postgresql
+connection-password=pa\\ss
+connection-url=jdbc:postgresql://postgres:5432/test
+connection-user=postgres
+`
+	if got := catalog.properties(); got != want {
+		t.Fatalf("unexpected properties:\n%s", got)
+	}
+
+	if got := MemoryCatalog("mem").properties(); got != "connector.name=memory\n" {
+		t.Fatalf("unexpected properties:\n%s", got)
+	}
+}
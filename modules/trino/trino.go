@@ -0,0 +1,98 @@
+// Package trino provides a container running the Trino distributed SQL query engine, with catalogs configured
+// from Go values, to integration test analytics queries
+package trino
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage = "docker.io/trinodb/trino:403"
+	defaultUser  = "test"
+	port         = "8080/tcp"
+)
+
+// TrinoContainer represents the Trino container type used in the module
+type TrinoContainer struct {
+	testcontainers.Container
+}
+
+// RunContainer creates an instance of the Trino container type
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*TrinoContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{port},
+			// the HTTP server answers before the coordinator completed its startup
+			WaitingFor: wait.ForHTTP("/v1/info").WithPort(port).WithResponseMatcher(func(body io.Reader) bool {
+				data, err := io.ReadAll(body)
+				return err == nil && strings.Contains(string(data), `"starting":false`)
+			}),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TrinoContainer{Container: container}, nil
+}
+
+// URL returns the base URL of the Trino HTTP API
+func (c *TrinoContainer) URL(ctx context.Context) (string, error) {
+	address, err := c.address(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// ConnectionString returns the DSN of the trino-go-client driver, connecting as a test user to the given catalog
+// and schema, e.g. ConnectionString(ctx, "pg", "public")
+func (c *TrinoContainer) ConnectionString(ctx context.Context, catalog string, schema string) (string, error) {
+	address, err := c.address(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s@%s?catalog=%s&schema=%s", defaultUser, address, catalog, schema), nil
+}
+
+// JDBCURL returns the URL of the Trino JDBC driver for the given catalog and schema,
+// for tools and services running on the JVM
+func (c *TrinoContainer) JDBCURL(ctx context.Context, catalog string, schema string) (string, error) {
+	address, err := c.address(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("jdbc:trino://%s/%s/%s?user=%s", address, catalog, schema, defaultUser), nil
+}
+
+func (c *TrinoContainer) address(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, mappedPort.Port()), nil
+}
@@ -0,0 +1,46 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+func TestTrino(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx, WithCatalogs(MemoryCatalog("mem")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	statements := "CREATE TABLE mem.default.users (name varchar); " +
+		"INSERT INTO mem.default.users VALUES ('alice'); " +
+		"SELECT name FROM mem.default.users"
+
+	result, err := container.ExecWithResult(ctx, []string{"trino", "--execute", statements}, tcexec.WithUser("trino"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("query failed: %s", result.Stderr)
+	}
+	if !strings.Contains(string(result.Stdout), "alice") {
+		t.Fatalf("expected the inserted row, got %s", result.Stdout)
+	}
+
+	dsn, err := container.ConnectionString(ctx, "mem", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dsn, "http://test@") || !strings.HasSuffix(dsn, "?catalog=mem&schema=default") {
+		t.Fatalf("unexpected connection string %s", dsn)
+	}
+}
@@ -0,0 +1,234 @@
+package verdaccio
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Package is a version of a package published with Client.Publish. Its package.json is generated from the name,
+// the version and the dependencies.
+type Package struct {
+	Name         string
+	Version      string
+	Dependencies map[string]string
+	// Files are the other files of the package by path, e.g. index.js
+	Files map[string]string
+}
+
+// PackageMetadata is the metadata of a package served by the registry to package managers
+type PackageMetadata struct {
+	Name     string                     `json:"name"`
+	DistTags map[string]string          `json:"dist-tags"`
+	Versions map[string]VersionMetadata `json:"versions"`
+}
+
+// VersionMetadata is the metadata of a version of a package, with the URL of its tarball
+type VersionMetadata struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	Dist         Dist              `json:"dist"`
+}
+
+// Dist locates the tarball of a version of a package and its checksums
+type Dist struct {
+	Tarball   string `json:"tarball"`
+	Shasum    string `json:"shasum"`
+	Integrity string `json:"integrity"`
+}
+
+type attachment struct {
+	ContentType string `json:"content_type"`
+	Data        string `json:"data"`
+	Length      int    `json:"length"`
+}
+
+type publishRequest struct {
+	ID          string                     `json:"_id"`
+	Name        string                     `json:"name"`
+	DistTags    map[string]string          `json:"dist-tags"`
+	Versions    map[string]VersionMetadata `json:"versions"`
+	Attachments map[string]attachment      `json:"_attachments"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Client is a client of the npm registry API, authenticated with basic authentication
+type Client struct {
+	baseURL    string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the registry served at the given URL, authenticated as the given user
+func NewClient(baseURL string, user string, password string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		user:       user,
+		password:   password,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish publishes the version of the package, tagged as the latest one, the way npm publish does
+func (c *Client) Publish(ctx context.Context, pkg Package) error {
+	tarball, err := pkg.tarball()
+	if err != nil {
+		return err
+	}
+
+	sha1Sum := sha1.Sum(tarball)
+	sha512Sum := sha512.Sum512(tarball)
+
+	// the tarball is named after the package without its scope
+	filename := fmt.Sprintf("%s-%s.tgz", pkg.Name[strings.LastIndex(pkg.Name, "/")+1:], pkg.Version)
+
+	body := publishRequest{
+		ID:       pkg.Name,
+		Name:     pkg.Name,
+		DistTags: map[string]string{"latest": pkg.Version},
+		Versions: map[string]VersionMetadata{
+			pkg.Version: {
+				Name:         pkg.Name,
+				Version:      pkg.Version,
+				Dependencies: pkg.Dependencies,
+				Dist: Dist{
+					Tarball:   fmt.Sprintf("%s/%s/-/%s", c.baseURL, pkg.Name, filename),
+					Shasum:    hex.EncodeToString(sha1Sum[:]),
+					Integrity: "sha512-" + base64.StdEncoding.EncodeToString(sha512Sum[:]),
+				},
+			},
+		},
+		Attachments: map[string]attachment{
+			filename: {
+				ContentType: "application/octet-stream",
+				Data:        base64.StdEncoding.EncodeToString(tarball),
+				Length:      len(tarball),
+			},
+		},
+	}
+
+	return c.do(ctx, http.MethodPut, "/"+url.PathEscape(pkg.Name), body, nil)
+}
+
+// Package returns the metadata of the package with all its versions
+func (c *Client) Package(ctx context.Context, name string) (*PackageMetadata, error) {
+	var metadata PackageMetadata
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(name), nil, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// tarball packs the package.json and the files of the package in the package directory, as npm pack does
+func (p Package) tarball() ([]byte, error) {
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"name":         p.Name,
+		"version":      p.Version,
+		"dependencies": p.Dependencies,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{"package.json": string(manifest)}
+	for name, content := range p.Files {
+		files[name] = content
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		content := files[name]
+		hdr := &tar.Header{Name: "package/" + name, Mode: 0o644, Size: int64(len(content))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, body interface{}, data interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var r errorResponse
+		if err := json.Unmarshal(respBody, &r); err == nil && r.Error != "" {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, r.Error)
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, data)
+}
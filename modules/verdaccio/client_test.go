@@ -0,0 +1,126 @@
+package verdaccio
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPublish(t *testing.T) {
+	var published publishRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"authorization required"}`))
+			return
+		}
+
+		if r.Method != http.MethodPut || r.URL.RawPath != "/@acme%2Fgreeter" || json.NewDecoder(r.Body).Decode(&published) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"ok":"created new package"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	pkg := Package{
+		Name:         "@acme/greeter",
+		Version:      "1.2.0",
+		Dependencies: map[string]string{"left-pad": "^1.3.0"},
+		Files:        map[string]string{"index.js": "module.exports = () => 'hello'\n"},
+	}
+
+	if err := NewClient(server.URL+"/", "alice", "secret").Publish(context.Background(), pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	version := published.Versions["1.2.0"]
+	if published.DistTags["latest"] != "1.2.0" || version.Dependencies["left-pad"] != "^1.3.0" {
+		t.Fatalf("unexpected package %+v", published)
+	}
+	if version.Dist.Tarball != server.URL+"/@acme/greeter/-/greeter-1.2.0.tgz" {
+		t.Fatalf("unexpected tarball URL %s", version.Dist.Tarball)
+	}
+
+	tarball, err := base64.StdEncoding.DecodeString(published.Attachments["greeter-1.2.0.tgz"].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := untar(t, tarball)
+	if files["package/index.js"] != pkg.Files["index.js"] {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	var manifest Package
+	if err := json.Unmarshal([]byte(files["package/package.json"]), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != pkg.Name || manifest.Version != pkg.Version {
+		t.Fatalf("unexpected package.json %s", files["package/package.json"])
+	}
+
+	err = NewClient(server.URL, "alice", "wrong").Publish(context.Background(), pkg)
+	if err == nil || err.Error() != "unexpected status 401: authorization required" {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
+
+func TestRenderConfig(t *testing.T) {
+	want := `storage: /verdaccio/storage/data
+plugins: /verdaccio/plugins
+auth:
+  htpasswd:
+    file: /verdaccio/conf/htpasswd
+    max_users: -1
+uplinks:
+  upstream:
+    url: "https://registry.npmjs.org/"
+packages:
+  '**':
+    access: $authenticated
+    publish: $authenticated
+    unpublish: $authenticated
+    proxy: upstream
+log: { type: stdout, format: pretty, level: http }
+`
+	if got := renderConfig("$authenticated", "https://registry.npmjs.org/"); got != want {
+		t.Fatalf("unexpected configuration:\n%s", got)
+	}
+}
+
+func untar(t *testing.T, tarball []byte) map[string]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(content)
+	}
+}
@@ -0,0 +1,187 @@
+// Package verdaccio provides a container running the Verdaccio npm registry, with a user bootstrapped on startup
+// and a client to publish packages, to test dependency resolution and publishing code paths against a private registry
+package verdaccio
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	defaultImage    = "docker.io/verdaccio/verdaccio:5.15"
+	defaultUser     = "verdaccio"
+	defaultPassword = "verdaccio"
+	registryPort    = "4873/tcp"
+
+	configPath   = "/verdaccio/conf/config.yaml"
+	htpasswdPath = "/verdaccio/conf/htpasswd"
+
+	// the settings of the options are kept in the environment of the request,
+	// and rendered in the configuration once all the options are applied
+	userEnv     = "REGISTRY_USER"
+	passwordEnv = "REGISTRY_PASSWORD"
+	uplinkEnv   = "REGISTRY_UPLINK"
+	accessEnv   = "REGISTRY_ACCESS"
+)
+
+// VerdaccioContainer represents the Verdaccio container type used in the module
+type VerdaccioContainer struct {
+	testcontainers.Container
+	user     string
+	password string
+}
+
+// RunContainer creates an instance of the Verdaccio container type, with a user allowed to publish packages.
+// The registry does not proxy any upstream registry unless WithUplink says otherwise, so that tests are hermetic.
+func RunContainer(ctx context.Context, opts ...testcontainers.CustomizeRequestOption) (*VerdaccioContainer, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        defaultImage,
+			ExposedPorts: []string{registryPort},
+			Env: map[string]string{
+				userEnv:     defaultUser,
+				passwordEnv: defaultPassword,
+				accessEnv:   "$all",
+			},
+			WaitingFor: wait.ForHTTP("/-/ping").WithPort(registryPort),
+		},
+		Started: true,
+	}
+
+	for _, opt := range opts {
+		opt(&req)
+	}
+
+	htpasswd, err := htpasswdEntry(req.Env[userEnv], req.Env[passwordEnv])
+	if err != nil {
+		return nil, err
+	}
+	config := renderConfig(req.Env[accessEnv], req.Env[uplinkEnv])
+
+	req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+		PostCreates: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				if err := c.CopyToContainer(ctx, []byte(htpasswd), htpasswdPath, 0o644); err != nil {
+					return err
+				}
+				return c.CopyToContainer(ctx, []byte(config), configPath, 0o644)
+			},
+		},
+	})
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerdaccioContainer{
+		Container: container,
+		user:      req.Env[userEnv],
+		password:  req.Env[passwordEnv],
+	}, nil
+}
+
+// WithCredentials sets the name and the password of the user created on startup
+func WithCredentials(user string, password string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env[userEnv] = user
+		req.Env[passwordEnv] = password
+	}
+}
+
+// WithUplink proxies the packages which are not published to the registry from an upstream registry,
+// e.g. https://registry.npmjs.org/, caching them in the container
+func WithUplink(url string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env[uplinkEnv] = url
+	}
+}
+
+// WithAuthenticatedAccess requires the clients to authenticate to install packages too, not only to publish them,
+// to test how credentials are passed to package managers
+func WithAuthenticatedAccess() testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.Env[accessEnv] = "$authenticated"
+	}
+}
+
+// htpasswdEntry hashes the password of the user with bcrypt, one of the schemes of the htpasswd files of Verdaccio
+func htpasswdEntry(user string, password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to hash the password of %s", err, user)
+	}
+
+	return fmt.Sprintf("%s:%s\n", user, hash), nil
+}
+
+// renderConfig renders the configuration of the registry, the registration of other users is disabled
+func renderConfig(access string, uplink string) string {
+	var b strings.Builder
+
+	b.WriteString("storage: /verdaccio/storage/data\n")
+	b.WriteString("plugins: /verdaccio/plugins\n")
+	fmt.Fprintf(&b, "auth:\n  htpasswd:\n    file: %s\n    max_users: -1\n", htpasswdPath)
+	if uplink != "" {
+		fmt.Fprintf(&b, "uplinks:\n  upstream:\n    url: %q\n", uplink)
+	}
+	b.WriteString("packages:\n  '**':\n")
+	fmt.Fprintf(&b, "    access: %s\n    publish: $authenticated\n    unpublish: $authenticated\n", access)
+	if uplink != "" {
+		b.WriteString("    proxy: upstream\n")
+	}
+	b.WriteString("log: { type: stdout, format: pretty, level: http }\n")
+
+	return b.String()
+}
+
+// Credentials returns the name and the password of the user created on startup
+func (c *VerdaccioContainer) Credentials() (string, string) {
+	return c.user, c.password
+}
+
+// URL returns the URL of the registry, with a trailing slash as package managers expect it
+func (c *VerdaccioContainer) URL(ctx context.Context) (string, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := c.MappedPort(ctx, registryPort)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://%s/", net.JoinHostPort(host, port.Port())), nil
+}
+
+// Npmrc returns the content of an .npmrc file using the registry, authenticated as the user created on startup,
+// to run npm or yarn against it
+func (c *VerdaccioContainer) Npmrc(ctx context.Context) (string, error) {
+	registryURL, err := c.URL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(c.user + ":" + c.password))
+
+	return fmt.Sprintf("registry=%s\n%s:_auth=%s\n", registryURL, strings.TrimPrefix(registryURL, "http:"), auth), nil
+}
+
+// Client returns a client of the registry authenticated as the user created on startup
+func (c *VerdaccioContainer) Client(ctx context.Context) (*Client, error) {
+	registryURL, err := c.URL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(registryURL, c.user, c.password), nil
+}
@@ -0,0 +1,59 @@
+package verdaccio
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVerdaccio(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := RunContainer(ctx, WithCredentials("alice", "s3cr3t!"), WithAuthenticatedAccess())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	})
+
+	client, err := container.Client(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.Publish(ctx, Package{
+		Name:    "@acme/greeter",
+		Version: "1.0.0",
+		Files:   map[string]string{"index.js": "module.exports = () => 'hello'\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := client.Package(ctx, "@acme/greeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.DistTags["latest"] != "1.0.0" || metadata.Versions["1.0.0"].Dist.Tarball == "" {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+
+	registryURL, err := container.URL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(registryURL, "", "").Package(ctx, "@acme/greeter"); err == nil {
+		t.Fatal("expected anonymous access to be denied")
+	}
+
+	npmrc, err := container.Npmrc(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(npmrc, "registry="+registryURL+"\n") {
+		t.Fatalf("unexpected .npmrc %s", npmrc)
+	}
+}
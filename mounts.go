@@ -17,6 +17,28 @@ type (
 	// ContainerMounts represents a collection of mounts for a container
 	ContainerMounts []ContainerMount
 	MountType       uint
+
+	// SELinuxLabel defines how the host path of a bind mount is relabeled on SELinux enabled hosts
+	SELinuxLabel string
+
+	// BindPropagation defines whether mounts created below a bind mount are propagated between the host and the container
+	BindPropagation string
+)
+
+const (
+	// SELinuxShared relabels the host path with a shared label, so that several containers can use it (:z)
+	SELinuxShared SELinuxLabel = "z"
+	// SELinuxPrivate relabels the host path with a private label, so that only this container can use it (:Z)
+	SELinuxPrivate SELinuxLabel = "Z"
+)
+
+const (
+	PropagationRPrivate BindPropagation = "rprivate"
+	PropagationPrivate  BindPropagation = "private"
+	PropagationRShared  BindPropagation = "rshared"
+	PropagationShared   BindPropagation = "shared"
+	PropagationRSlave   BindPropagation = "rslave"
+	PropagationSlave    BindPropagation = "slave"
 )
 
 // ContainerMountSource is the base for all mount sources
@@ -36,6 +58,12 @@ type GenericBindMountSource struct {
 	// HostPath is the path mounted into the container
 	// the same host path might be mounted to multiple locations withing a single container
 	HostPath string
+
+	// SELinuxLabel relabels the host path so that the container is allowed to access it, empty to keep the current label
+	SELinuxLabel SELinuxLabel
+
+	// Propagation sets the bind propagation mode, the daemon defaults to rprivate
+	Propagation BindPropagation
 }
 
 func (s GenericBindMountSource) Source() string {
@@ -100,6 +128,15 @@ func VolumeMount(volumeName string, mountTarget ContainerMountTarget) ContainerM
 	}
 }
 
+// TmpfsMount returns a new ContainerMount with a GenericTmpfsMountSource as source
+// This is a convenience method to cover typical use cases.
+func TmpfsMount(mountTarget ContainerMountTarget) ContainerMount {
+	return ContainerMount{
+		Source: GenericTmpfsMountSource{},
+		Target: mountTarget,
+	}
+}
+
 // Mounts returns a ContainerMounts to support a more fluent API
 func Mounts(mounts ...ContainerMount) ContainerMounts {
 	return mounts
@@ -114,3 +151,29 @@ type ContainerMount struct {
 	// ReadOnly determines if the mount should be read-only
 	ReadOnly bool
 }
+
+// WithReadOnly returns a copy of the mount that is mounted read-only
+func (m ContainerMount) WithReadOnly() ContainerMount {
+	m.ReadOnly = true
+	return m
+}
+
+// WithSELinuxLabel returns a copy of the bind mount relabeling its host path with the given label.
+// It must only be used on mounts created with BindMount.
+func (m ContainerMount) WithSELinuxLabel(label SELinuxLabel) ContainerMount {
+	if src, ok := m.Source.(GenericBindMountSource); ok {
+		src.SELinuxLabel = label
+		m.Source = src
+	}
+	return m
+}
+
+// WithPropagation returns a copy of the bind mount using the given propagation mode.
+// It must only be used on mounts created with BindMount.
+func (m ContainerMount) WithPropagation(propagation BindPropagation) ContainerMount {
+	if src, ok := m.Source.(GenericBindMountSource); ok {
+		src.Propagation = propagation
+		m.Source = src
+	}
+	return m
+}
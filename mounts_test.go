@@ -174,3 +174,97 @@ func TestContainerMounts_PrepareMounts(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerMounts_Builders(t *testing.T) {
+	t.Parallel()
+
+	mounts := Mounts(
+		BindMount("/var/lib/app/data", "/data").WithReadOnly().WithPropagation(PropagationRSlave),
+		VolumeMount("app-cache", "/cache").WithReadOnly(),
+		TmpfsMount("/tmp"),
+	)
+
+	assert.Equal(t, []mount.Mount{
+		{
+			Type:        mount.TypeBind,
+			Source:      "/var/lib/app/data",
+			Target:      "/data",
+			ReadOnly:    true,
+			BindOptions: &mount.BindOptions{Propagation: mount.PropagationRSlave},
+		},
+		{
+			Type:     mount.TypeVolume,
+			Source:   "app-cache",
+			Target:   "/cache",
+			ReadOnly: true,
+		},
+		{
+			Type:   mount.TypeTmpfs,
+			Target: "/tmp",
+		},
+	}, mapToDockerMounts(mounts))
+	assert.Empty(t, mapToDockerBinds(mounts))
+}
+
+func TestContainerMounts_SELinuxLabel(t *testing.T) {
+	t.Parallel()
+
+	mounts := Mounts(
+		BindMount("/var/lib/app/data", "/data").WithSELinuxLabel(SELinuxPrivate),
+		BindMount("/var/lib/app/config", "/config").WithSELinuxLabel(SELinuxShared).WithReadOnly().WithPropagation(PropagationShared),
+		VolumeMount("app-cache", "/cache").WithSELinuxLabel(SELinuxShared),
+	)
+
+	assert.Equal(t, []mount.Mount{
+		{
+			Type:   mount.TypeVolume,
+			Source: "app-cache",
+			Target: "/cache",
+		},
+	}, mapToDockerMounts(mounts))
+	assert.Equal(t, []string{
+		"/var/lib/app/data:/data:Z",
+		"/var/lib/app/config:/config:z,ro,shared",
+	}, mapToDockerBinds(mounts))
+}
+
+func TestContainerMounts_InvalidSELinuxLabel(t *testing.T) {
+	t.Parallel()
+
+	req := ContainerRequest{
+		Image:  "redis:latest",
+		Mounts: Mounts(BindMount("/data", "/data").WithSELinuxLabel("x")),
+	}
+
+	assert.ErrorIs(t, req.Validate(), ErrInvalidSELinuxLabel)
+}
+
+func TestContainerMounts_VolumeLabels(t *testing.T) {
+	t.Parallel()
+
+	options := &mount.VolumeOptions{Labels: map[string]string{"app": "orders", TestcontainerLabel: "false"}}
+	mounts := withVolumeLabels([]mount.Mount{
+		{Type: mount.TypeVolume, Source: "app-cache", Target: "/cache"},
+		{Type: mount.TypeVolume, Source: "app-data", Target: "/data", VolumeOptions: options},
+		{Type: mount.TypeVolume, Target: "/anonymous"},
+		{Type: mount.TypeBind, Source: "/var/lib/app/data", Target: "/host"},
+	}, map[string]string{TestcontainerLabel: "true", TestcontainerLabelSessionID: "session"})
+
+	assert.Equal(t, []mount.Mount{
+		{
+			Type:          mount.TypeVolume,
+			Source:        "app-cache",
+			Target:        "/cache",
+			VolumeOptions: &mount.VolumeOptions{Labels: map[string]string{TestcontainerLabel: "true", TestcontainerLabelSessionID: "session"}},
+		},
+		{
+			Type:          mount.TypeVolume,
+			Source:        "app-data",
+			Target:        "/data",
+			VolumeOptions: &mount.VolumeOptions{Labels: map[string]string{"app": "orders", TestcontainerLabel: "false", TestcontainerLabelSessionID: "session"}},
+		},
+		{Type: mount.TypeVolume, Target: "/anonymous"},
+		{Type: mount.TypeBind, Source: "/var/lib/app/data", Target: "/host"},
+	}, mounts)
+	assert.Equal(t, map[string]string{"app": "orders", TestcontainerLabel: "false"}, options.Labels, "the options of the request are not modified")
+}
@@ -0,0 +1,36 @@
+package testcontainers
+
+// CustomizeRequestOption is a type that can be used to configure the Testcontainers container request.
+// The passed request will be merged with the default one.
+type CustomizeRequestOption func(req *GenericContainerRequest)
+
+// WithImage replaces the image of the request, e.g. to use another version than the default one of a module
+func WithImage(image string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		req.Image = image
+	}
+}
+
+// WithEnvFile loads the variables of a dotenv file into the container environment.
+// Files are read in the order they are added, so later files override earlier ones,
+// and the variables set in Env always take precedence over any file.
+func WithEnvFile(path string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		req.EnvFiles = append(req.EnvFiles, path)
+	}
+}
+
+// WithWorkingDir sets the directory the entrypoint and the command of the container are executed from
+func WithWorkingDir(workingDir string) CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		req.WorkingDir = workingDir
+	}
+}
+
+// WithStartupLogs records the log lines written by the container until it is ready,
+// so that tests can assert on them with Container.StartupLogs
+func WithStartupLogs() CustomizeRequestOption {
+	return func(req *GenericContainerRequest) {
+		req.RecordStartupLogs = true
+	}
+}
@@ -0,0 +1,168 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPoolSize         = 1
+	defaultPoolRetryBackoff = time.Second
+)
+
+// ContainerFactory creates a started container, e.g. with the RunContainer function of a module
+type ContainerFactory func(ctx context.Context) (Container, error)
+
+// RequestFactory returns a factory creating started containers from the request
+func RequestFactory(req GenericContainerRequest) ContainerFactory {
+	return func(ctx context.Context) (Container, error) {
+		req.Started = true
+		return GenericContainer(ctx, req)
+	}
+}
+
+// PoolOptions configures a ContainerPool
+type PoolOptions struct {
+	Size   int     // number of containers kept ready, defaults to 1
+	Logger Logging // logs the failures to create containers, defaults to the global Logger
+}
+
+// ContainerPool creates containers in the background ahead of the tests, and hands them over as soon as a test
+// acquires one, replacing it asynchronously, to hide the startup latency of the containers in large parallel suites.
+// The acquired containers belong to the tests, which terminate them once done.
+type ContainerPool struct {
+	factory ContainerFactory
+	opts    PoolOptions
+
+	ready chan Container
+
+	lastErr error
+	unused  []Container // containers created but not acquired once the pool is closed
+	lock    sync.Mutex
+
+	cancel    context.CancelFunc
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewContainerPool starts creating containers with the factory in the background, until Close is called or
+// the context is cancelled. The context is passed to the factory.
+func NewContainerPool(ctx context.Context, factory ContainerFactory, opts PoolOptions) (*ContainerPool, error) {
+	if factory == nil {
+		return nil, errors.New("a container pool requires a factory")
+	}
+	if opts.Size <= 0 {
+		opts.Size = defaultPoolSize
+	}
+	if opts.Logger == nil {
+		opts.Logger = Logger
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	p := &ContainerPool{
+		factory: factory,
+		opts:    opts,
+		ready:   make(chan Container),
+		cancel:  cancel,
+		closed:  make(chan struct{}),
+	}
+
+	// each worker keeps one container ready, and creates the next one once it is acquired
+	for i := 0; i < opts.Size; i++ {
+		p.wg.Add(1)
+		go p.replenish(ctx)
+	}
+
+	return p, nil
+}
+
+// Acquire returns a ready container, waiting for one to be created if the pool is empty. The caller owns
+// the container and terminates it.
+func (p *ContainerPool) Acquire(ctx context.Context) (Container, error) {
+	select {
+	case c := <-p.ready:
+		return c, nil
+	case <-p.closed:
+		return nil, errors.New("the container pool is closed")
+	case <-ctx.Done():
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		if p.lastErr != nil {
+			return nil, fmt.Errorf("%w: no container available, last creation failed: %s", ctx.Err(), p.lastErr)
+		}
+		return nil, fmt.Errorf("%w: no container available", ctx.Err())
+	}
+}
+
+// Close stops creating containers and terminates the ones which were not acquired
+func (p *ContainerPool) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+	})
+	p.cancel()
+	p.wg.Wait()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var errs []error
+	for _, c := range p.unused {
+		if err := c.Terminate(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.unused = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: failed to terminate %d pooled containers", errs[0], len(errs))
+	}
+	return nil
+}
+
+// replenish keeps a container ready until it is acquired, then creates the next one, until the pool is closed
+func (p *ContainerPool) replenish(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		c, err := p.factory(ctx)
+		if err != nil {
+			// a container which failed to start is returned with the error, e.g. by GenericContainer
+			if c != nil {
+				if termErr := c.Terminate(context.Background()); termErr != nil {
+					p.opts.Logger.Printf("container pool: failed to terminate a container which failed to start: %s", termErr)
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			p.lock.Lock()
+			p.lastErr = err
+			p.lock.Unlock()
+			p.opts.Logger.Printf("container pool: failed to create a container: %s", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(defaultPoolRetryBackoff):
+			}
+			continue
+		}
+
+		// the channel is not buffered, the worker holds the container until a test acquires it
+		select {
+		case p.ready <- c:
+		case <-ctx.Done():
+			p.lock.Lock()
+			p.unused = append(p.unused, c)
+			p.lock.Unlock()
+			return
+		}
+	}
+}
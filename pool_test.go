@@ -0,0 +1,97 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerPoolCreationFailure(t *testing.T) {
+	pool, err := NewContainerPool(context.Background(), func(ctx context.Context) (Container, error) {
+		return nil, errors.New("image not found")
+	}, PoolOptions{Size: 2})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "last creation failed: image not found")
+
+	require.NoError(t, pool.Close(context.Background()))
+
+	_, err = pool.Acquire(context.Background())
+	assert.EqualError(t, err, "the container pool is closed")
+}
+
+// terminatedContainer counts its terminations, for the tests of the containers which failed to start
+type terminatedContainer struct {
+	Container
+	terminations *int32
+}
+
+func (c *terminatedContainer) Terminate(context.Context) error {
+	atomic.AddInt32(c.terminations, 1)
+	return nil
+}
+
+func TestContainerPoolTerminatesFailedContainers(t *testing.T) {
+	var created, terminations int32
+	pool, err := NewContainerPool(context.Background(), func(ctx context.Context) (Container, error) {
+		atomic.AddInt32(&created, 1)
+		return &terminatedContainer{terminations: &terminations}, errors.New("container did not get ready in time")
+	}, PoolOptions{Size: 2})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, pool.Close(context.Background()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&created), "each worker created a container")
+	assert.Equal(t, atomic.LoadInt32(&created), atomic.LoadInt32(&terminations), "the containers which failed to start are terminated")
+}
+
+func TestContainerPool(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := NewContainerPool(ctx, RequestFactory(GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+	}), PoolOptions{Size: 2})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, pool.Close(ctx))
+	})
+
+	// a failing creation is retried until the deadline, which reports its error
+	acquireCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+
+	ids := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		c, err := pool.Acquire(acquireCtx)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, c.Terminate(ctx))
+		})
+
+		state, err := c.State(ctx)
+		require.NoError(t, err)
+		assert.True(t, state.Running, "the pooled containers are started")
+
+		ids[c.GetContainerID()] = true
+	}
+	assert.Len(t, ids, 3, "each acquired container is a new one")
+}
@@ -0,0 +1,219 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/containerd/containerd/platforms"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// PrefetchOptions configures the prefetch of images
+type PrefetchOptions struct {
+	WorkersCount int // number of images pulled in parallel, defaultWorkersCount if zero or negative
+}
+
+// prefetchImage is an image to prefetch, with the options it is pulled with
+type prefetchImage struct {
+	tag      string
+	platform string
+	auth     string
+	always   bool
+	digest   string // digest of the manifest in the registry, empty if unknown
+}
+
+// PrefetchImages pulls the missing images of the requests with the default provider in one parallel phase, before
+// the containers are started, instead of pulling them one after the other as the containers start. It is meant to be
+// called before a test suite with the requests of all its containers.
+func PrefetchImages(ctx context.Context, reqs []ContainerRequest, opt PrefetchOptions) error {
+	provider, err := defaultDockerProvider()
+	if err != nil {
+		return err
+	}
+
+	return provider.PrefetchImages(ctx, reqs, opt)
+}
+
+// PrefetchImages pulls the missing images of the requests in one parallel phase. The image substitutors, platform,
+// credentials and pull policy of each request apply, and the requests building an image are skipped. The manifests of
+// the missing images are inspected in the registry first, so that the images sharing a manifest, e.g. two tags of the
+// same release, are pulled once and tagged locally. The layers shared by several images are downloaded once by the
+// daemon. The failures do not stop the other pulls, the first one is returned.
+func (p *DockerProvider) PrefetchImages(ctx context.Context, reqs []ContainerRequest, opt PrefetchOptions) error {
+	if opt.WorkersCount <= 0 {
+		opt.WorkersCount = defaultWorkersCount
+	}
+
+	images := prefetchImages(reqs)
+
+	missing, err := p.missingImages(ctx, images, opt.WorkersCount)
+	if err != nil {
+		return err
+	}
+
+	// inspecting the manifests is best effort: an image whose manifest is unknown is pulled on its own
+	runParallel(len(missing), opt.WorkersCount, func(i int) error {
+		inspect, err := p.client.DistributionInspect(ctx, missing[i].tag, missing[i].auth)
+		if err != nil {
+			p.Logger.Printf("failed to inspect the manifest of image %s, pulling it on its own: %s", missing[i].tag, err)
+			return nil
+		}
+		missing[i].digest = inspect.Descriptor.Digest.String()
+		return nil
+	})
+
+	pulls, tags := groupByManifest(missing)
+
+	errs := runParallel(len(pulls), opt.WorkersCount, func(i int) error {
+		img := pulls[i]
+		pullOpt := types.ImagePullOptions{Platform: img.platform, RegistryAuth: img.auth}
+		if err := p.attemptToPullImage(ctx, img.tag, pullOpt); err != nil {
+			return classifyError(err, ErrImagePull, "", img.tag)
+		}
+
+		for _, tag := range tags[img] {
+			if err := p.client.ImageTag(ctx, img.tag, tag); err != nil {
+				return fmt.Errorf("%w: failed to tag image %s as %s", err, img.tag, tag)
+			}
+		}
+		return nil
+	})
+
+	p.Logger.Printf("prefetched %d images, %d already present", len(missing), len(images)-len(missing))
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: failed to prefetch %d images", errs[0], len(errs))
+	}
+	return nil
+}
+
+// missingImages returns the images that must be pulled, i.e. the images not present locally for their platform,
+// and the images always pulled
+func (p *DockerProvider) missingImages(ctx context.Context, images []*prefetchImage, workersCount int) ([]*prefetchImage, error) {
+	present := make([]bool, len(images))
+
+	errs := runParallel(len(images), workersCount, func(i int) error {
+		img := images[i]
+		if img.always {
+			return nil
+		}
+
+		inspect, _, err := p.client.ImageInspectWithRaw(ctx, img.tag)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		if img.platform == "" {
+			present[i] = true
+			return nil
+		}
+
+		platform, err := platforms.Parse(img.platform)
+		if err != nil {
+			return fmt.Errorf("invalid platform %s: %w", img.platform, err)
+		}
+		present[i] = inspect.Architecture == platform.Architecture && inspect.Os == platform.OS
+		return nil
+	})
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	var missing []*prefetchImage
+	for i, img := range images {
+		if !present[i] {
+			missing = append(missing, img)
+		}
+	}
+
+	return missing, nil
+}
+
+// prefetchImages returns the images of the requests, once per tag and platform
+func prefetchImages(reqs []ContainerRequest) []*prefetchImage {
+	var images []*prefetchImage
+	seen := map[string]*prefetchImage{}
+
+	for _, req := range reqs {
+		if req.Image == "" || req.ShouldBuildImage() {
+			continue
+		}
+
+		tag := substituteImage(req.Image, req.ImageSubstitutors)
+		key := tag + "|" + req.ImagePlatform
+		if img, ok := seen[key]; ok {
+			img.always = img.always || req.AlwaysPullImage
+			continue
+		}
+
+		img := &prefetchImage{tag: tag, platform: req.ImagePlatform, auth: req.RegistryCred, always: req.AlwaysPullImage}
+		seen[key] = img
+		images = append(images, img)
+	}
+
+	return images
+}
+
+// groupByManifest returns the images to pull, one per manifest and platform, and for each of them the other tags of
+// its manifest, tagged locally once it is pulled
+func groupByManifest(images []*prefetchImage) ([]*prefetchImage, map[*prefetchImage][]string) {
+	var pulls []*prefetchImage
+	tags := map[*prefetchImage][]string{}
+	byManifest := map[string]*prefetchImage{}
+
+	for _, img := range images {
+		if img.digest == "" {
+			pulls = append(pulls, img)
+			continue
+		}
+
+		key := img.digest + "|" + img.platform
+		if first, ok := byManifest[key]; ok {
+			tags[first] = append(tags[first], img.tag)
+			continue
+		}
+
+		byManifest[key] = img
+		pulls = append(pulls, img)
+	}
+
+	return pulls, tags
+}
+
+// runParallel calls fn for the indexes from 0 to n-1 with at most workersCount calls at a time, and returns their
+// failures
+func runParallel(n int, workersCount int, fn func(i int) error) []error {
+	var (
+		errs []error
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	indexes := make(chan int)
+	for w := 0; w < workersCount && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(i); err != nil {
+					lock.Lock()
+					errs = append(errs, err)
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,58 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetchImagesOfRequests(t *testing.T) {
+	images := prefetchImages([]ContainerRequest{
+		{Image: "postgres:15"},
+		{Image: "postgres:15", AlwaysPullImage: true},
+		{Image: "postgres:15", ImagePlatform: "linux/arm64"},
+		{Image: "redis:7", ImageSubstitutors: []ImageSubstitutor{DockerHubMirror("registry.corp/mirror")}},
+		{FromDockerfile: FromDockerfile{Context: "."}},
+	})
+
+	assert.Equal(t, []*prefetchImage{
+		{tag: "postgres:15", always: true},
+		{tag: "postgres:15", platform: "linux/arm64"},
+		{tag: "registry.corp/mirror/redis:7"},
+	}, images)
+}
+
+func TestGroupByManifest(t *testing.T) {
+	latest := &prefetchImage{tag: "nginx:latest", digest: "sha256:1"}
+	stable := &prefetchImage{tag: "nginx:1.23", digest: "sha256:1"}
+	arm := &prefetchImage{tag: "nginx:1.23", platform: "linux/arm64", digest: "sha256:1"}
+	unknown := &prefetchImage{tag: "registry.local/app"}
+
+	pulls, tags := groupByManifest([]*prefetchImage{latest, stable, arm, unknown})
+
+	assert.Equal(t, []*prefetchImage{latest, arm, unknown}, pulls)
+	assert.Equal(t, map[*prefetchImage][]string{latest: {"nginx:1.23"}}, tags, "the tags of a pulled manifest are tagged locally")
+}
+
+func TestPrefetch(t *testing.T) {
+	ctx := context.Background()
+
+	provider, err := NewDockerProvider()
+	require.NoError(t, err)
+
+	reqs := []ContainerRequest{
+		{Image: "docker.io/alpine:3.17", AlwaysPullImage: true},
+		{Image: "docker.io/busybox:1.36", AlwaysPullImage: true},
+	}
+	require.NoError(t, provider.PrefetchImages(ctx, reqs, PrefetchOptions{}))
+
+	for _, req := range reqs {
+		_, _, err := provider.client.ImageInspectWithRaw(ctx, req.Image)
+		assert.NoError(t, err, "image %s is prefetched", req.Image)
+	}
+
+	err = provider.PrefetchImages(ctx, []ContainerRequest{{Image: "docker.io/testcontainers/missing:none"}}, PrefetchOptions{})
+	assert.Error(t, err)
+}
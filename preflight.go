@@ -0,0 +1,104 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// ErrPreflightFailed is returned when the Docker host does not satisfy the given Requirements
+var ErrPreflightFailed = errors.New("docker host does not satisfy the requirements")
+
+// Requirements defines the minimum resources the Docker host must provide to run a set of containers.
+// Zero values are not checked.
+type Requirements struct {
+	MinMemory int64 // total memory of the Docker host in bytes
+	MinDisk   int64 // free disk space in the Docker root directory in bytes
+	MinCPUs   int   // number of CPUs of the Docker host
+}
+
+// hostResources represents the resources reported for the Docker host.
+// DiskAvailable is negative if the free disk space could not be determined.
+type hostResources struct {
+	Memory        int64
+	DiskAvailable int64
+	CPUs          int
+}
+
+// Preflight checks that the Docker host of the default provider satisfies the given requirements.
+// It is meant to be called before starting a test suite, to fail fast with a clear message instead
+// of containers being OOM killed in the middle of the suite.
+func Preflight(ctx context.Context, req Requirements) error {
+	provider, err := defaultDockerProvider()
+	if err != nil {
+		return err
+	}
+
+	return provider.Preflight(ctx, req)
+}
+
+// Preflight checks that the Docker host satisfies the given requirements
+func (p *DockerProvider) Preflight(ctx context.Context, req Requirements) error {
+	info, err := p.client.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed getting information about docker server", err)
+	}
+
+	resources := hostResources{
+		Memory:        info.MemTotal,
+		DiskAvailable: -1,
+		CPUs:          info.NCPU,
+	}
+
+	if req.MinDisk > 0 {
+		if p.isLocalDaemon() && info.DockerRootDir != "" {
+			resources.DiskAvailable, err = availableDiskSpace(info.DockerRootDir)
+			if err != nil {
+				p.Logger.Printf("failed getting free disk space of %s, skipping disk check: %s", info.DockerRootDir, err)
+				resources.DiskAvailable = -1
+			}
+		} else {
+			p.Logger.Printf("free disk space of a remote docker host cannot be determined, skipping disk check")
+		}
+	}
+
+	return checkRequirements(req, resources)
+}
+
+// isLocalDaemon returns true if the Docker daemon shares the file system with the current process
+func (p *DockerProvider) isLocalDaemon() bool {
+	daemonURL, err := url.Parse(p.client.DaemonHost())
+	if err != nil {
+		return false
+	}
+
+	return (daemonURL.Scheme == "unix" || daemonURL.Scheme == "npipe") && !inAContainer()
+}
+
+func checkRequirements(req Requirements, resources hostResources) error {
+	var failures []string
+
+	if req.MinMemory > 0 && resources.Memory < req.MinMemory {
+		failures = append(failures, fmt.Sprintf("memory: %s required, %s available",
+			units.BytesSize(float64(req.MinMemory)), units.BytesSize(float64(resources.Memory))))
+	}
+
+	if req.MinDisk > 0 && resources.DiskAvailable >= 0 && resources.DiskAvailable < req.MinDisk {
+		failures = append(failures, fmt.Sprintf("disk: %s required, %s available",
+			units.BytesSize(float64(req.MinDisk)), units.BytesSize(float64(resources.DiskAvailable))))
+	}
+
+	if req.MinCPUs > 0 && resources.CPUs < req.MinCPUs {
+		failures = append(failures, fmt.Sprintf("cpus: %d required, %d available", req.MinCPUs, resources.CPUs))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w: %s", ErrPreflightFailed, strings.Join(failures, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRequirements(t *testing.T) {
+	resources := hostResources{
+		Memory:        4 * 1024 * 1024 * 1024,
+		DiskAvailable: 10 * 1024 * 1024 * 1024,
+		CPUs:          2,
+	}
+
+	tests := []struct {
+		name    string
+		req     Requirements
+		wantErr string
+	}{
+		{
+			name: "no requirements",
+			req:  Requirements{},
+		},
+		{
+			name: "satisfied requirements",
+			req: Requirements{
+				MinMemory: 2 * 1024 * 1024 * 1024,
+				MinDisk:   5 * 1024 * 1024 * 1024,
+				MinCPUs:   2,
+			},
+		},
+		{
+			name:    "not enough memory",
+			req:     Requirements{MinMemory: 8 * 1024 * 1024 * 1024},
+			wantErr: "memory: 8GiB required, 4GiB available",
+		},
+		{
+			name:    "not enough disk",
+			req:     Requirements{MinDisk: 20 * 1024 * 1024 * 1024},
+			wantErr: "disk: 20GiB required, 10GiB available",
+		},
+		{
+			name:    "not enough cpus",
+			req:     Requirements{MinCPUs: 4, MinMemory: 8 * 1024 * 1024 * 1024},
+			wantErr: "memory: 8GiB required, 4GiB available; cpus: 4 required, 2 available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRequirements(tt.req, resources)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrPreflightFailed))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCheckRequirementsSkipsUnknownDiskSpace(t *testing.T) {
+	err := checkRequirements(Requirements{MinDisk: 1}, hostResources{DiskAvailable: -1})
+	assert.NoError(t, err)
+}
+
+func TestPreflight(t *testing.T) {
+	err := Preflight(context.Background(), Requirements{MinCPUs: 1, MinMemory: 1})
+	assert.NoError(t, err)
+
+	err = Preflight(context.Background(), Requirements{MinCPUs: 1 << 20})
+	assert.True(t, errors.Is(err, ErrPreflightFailed))
+}
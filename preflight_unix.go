@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package testcontainers
+
+import "syscall"
+
+// availableDiskSpace returns the free disk space in bytes available to unprivileged users at the given path
+func availableDiskSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	return int64(uint64(stat.Bavail) * uint64(stat.Bsize)), nil
+}
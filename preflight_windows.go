@@ -0,0 +1,20 @@
+package testcontainers
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// availableDiskSpace returns the free disk space in bytes available to the current user at the given path
+func availableDiskSpace(path string) (int64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeBytesAvailable, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytesAvailable, &totalBytes, &totalFreeBytes); err != nil {
+		return 0, err
+	}
+
+	return int64(freeBytesAvailable), nil
+}
@@ -11,6 +11,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -20,6 +23,7 @@ const (
 	TestcontainerLabel          = "org.testcontainers.golang"
 	TestcontainerLabelSessionID = TestcontainerLabel + ".sessionId"
 	TestcontainerLabelIsReaper  = TestcontainerLabel + ".reaper"
+	TestcontainerLabelCreatedAt = TestcontainerLabel + ".createdAt"
 
 	ReaperDefaultImage = "docker.io/testcontainers/ryuk:0.3.4"
 )
@@ -50,6 +54,19 @@ func NewReaper(ctx context.Context, sessionID string, provider ReaperProvider, r
 
 	dockerHost := extractDockerHost(ctx)
 
+	tcConfig := provider.Config()
+
+	// in dry run, the resources are registered and labelled, but Ryuk is not started to remove them
+	if tcConfig.RyukDryRun {
+		reaper = &Reaper{
+			Provider:  provider,
+			SessionID: sessionID,
+			DryRun:    true,
+		}
+		Logger.Printf("Ryuk dry run: the resources of session %s are not removed once it ends, Session.Reapable lists them", sessionID)
+		return reaper, nil
+	}
+
 	// Otherwise create a new one
 	reaper = &Reaper{
 		Provider:  provider,
@@ -76,7 +93,6 @@ func NewReaper(ctx context.Context, sessionID string, provider ReaperProvider, r
 		req.Labels[k] = v
 	}
 
-	tcConfig := provider.Config()
 	req.Privileged = tcConfig.RyukPrivileged
 
 	// Attach reaper container to a requested network if it is specified
@@ -103,22 +119,43 @@ type Reaper struct {
 	Provider  ReaperProvider
 	SessionID string
 	Endpoint  string
+	DryRun    bool // whether Ryuk is not started, the resources registered with the reaper are kept
+}
+
+// ReapTarget is a resource the reaper removes once the session ends
+type ReapTarget struct {
+	Kind string // kind of the resource: container, network, volume or image
+	ID   string
+	Name string // name of the resource, its first tag for an image
 }
 
 // Connect runs a goroutine which can be terminated by sending true into the returned channel
 func (r *Reaper) Connect() (chan bool, error) {
+	return r.connect(r.Labels())
+}
+
+// connect registers a filter of the resources to remove with the given labels, until true is sent into
+// the returned channel
+func (r *Reaper) connect(labels map[string]string) (chan bool, error) {
+	terminationSignal := make(chan bool)
+	if r.DryRun {
+		go func() {
+			<-terminationSignal
+		}()
+		return terminationSignal, nil
+	}
+
 	conn, err := net.DialTimeout("tcp", r.Endpoint, 10*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("%w: Connecting to Ryuk on %s failed", err, r.Endpoint)
 	}
 
-	terminationSignal := make(chan bool)
 	go func(conn net.Conn) {
 		sock := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
 		defer conn.Close()
 
 		labelFilters := []string{}
-		for l, v := range r.Labels() {
+		for l, v := range labels {
 			labelFilters = append(labelFilters, fmt.Sprintf("label=%s=%s", l, v))
 		}
 
@@ -154,6 +191,62 @@ func (r *Reaper) Labels() map[string]string {
 	}
 }
 
+// reapTargets lists the resources carrying all the given labels, i.e. the resources the reaper would remove if they
+// were registered with these labels. The containers of the reaper are not listed.
+func reapTargets(ctx context.Context, cli client.APIClient, labels map[string]string) ([]ReapTarget, error) {
+	filter := filters.NewArgs()
+	for k, v := range labels {
+		filter.Add("label", k+"="+v)
+	}
+
+	var targets []ReapTarget
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range containers {
+		if c.Labels[TestcontainerLabelIsReaper] == "true" {
+			continue
+		}
+		var name string
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		targets = append(targets, ReapTarget{Kind: "container", ID: c.ID, Name: name})
+	}
+
+	networks, err := cli.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range networks {
+		targets = append(targets, ReapTarget{Kind: "network", ID: n.ID, Name: n.Name})
+	}
+
+	volumes, err := cli.VolumeList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range volumes.Volumes {
+		targets = append(targets, ReapTarget{Kind: "volume", ID: v.Name, Name: v.Name})
+	}
+
+	images, err := cli.ImageList(ctx, types.ImageListOptions{Filters: filter})
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		var name string
+		if len(img.RepoTags) > 0 {
+			name = img.RepoTags[0]
+		}
+		targets = append(targets, ReapTarget{Kind: "image", ID: img.ID, Name: name})
+	}
+
+	return targets, nil
+}
+
 func extractDockerHost(ctx context.Context) (dockerHostPath string) {
 	if dockerHostPath = os.Getenv("TESTCONTAINERS_DOCKER_SOCKET_OVERRIDE"); dockerHostPath != "" {
 		return dockerHostPath
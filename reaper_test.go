@@ -109,6 +109,56 @@ func Test_NewReaper(t *testing.T) {
 	}
 }
 
+func Test_NewReaperDryRun(t *testing.T) {
+	// make sure we re-initialize the singleton, and that the dry run reaper is not used by the other tests
+	reaper = nil
+	t.Cleanup(func() {
+		reaper = nil
+	})
+
+	provider := &mockReaperProvider{
+		config: TestContainersConfig{RyukDryRun: true},
+	}
+
+	r, err := NewReaper(context.TODO(), "sessionId", provider, "reaperImage")
+	assert.NoError(t, err)
+	assert.True(t, r.DryRun)
+	assert.Equal(t, ContainerRequest{}, provider.req, "Ryuk is not started")
+
+	termSignal, err := r.Connect()
+	assert.NoError(t, err)
+	termSignal <- true
+}
+
+func TestSessionReapable(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			FromDockerfile: FromDockerfile{
+				Context: "./testresources",
+			},
+			Mounts: Mounts(VolumeMount("testcontainers-reapable", "/data")),
+		},
+	})
+	assert.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, c)
+
+	targets, err := CurrentSession().Reapable(ctx)
+	assert.NoError(t, err)
+
+	assert.Contains(t, targets, ReapTarget{Kind: "volume", ID: "testcontainers-reapable", Name: "testcontainers-reapable"})
+
+	var container, image bool
+	for _, target := range targets {
+		container = container || target.Kind == "container" && target.ID == c.GetContainerID()
+		image = image || target.Kind == "image" && target.Name == c.(*DockerContainer).Image
+	}
+	assert.True(t, container)
+	assert.True(t, image, "the built image is removed with the session")
+}
+
 func Test_ExtractDockerHost(t *testing.T) {
 	t.Run("Docker Host as environment variable", func(t *testing.T) {
 		t.Setenv("TESTCONTAINERS_DOCKER_SOCKET_OVERRIDE", "/path/to/docker.sock")
@@ -0,0 +1,133 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// Seeder loads the fixtures of a file system into a container, e.g. the SQL scripts of a database,
+// the objects of a bucket or the messages of a topic. The target is specific to the seeder,
+// e.g. the directory the fixtures are copied to.
+type Seeder interface {
+	Seed(ctx context.Context, c Container, fixtures fs.FS, target string) error
+}
+
+// SeederFunc adapts a function to a Seeder
+type SeederFunc func(ctx context.Context, c Container, fixtures fs.FS, target string) error
+
+// Seed calls the function
+func (f SeederFunc) Seed(ctx context.Context, c Container, fixtures fs.FS, target string) error {
+	return f(ctx, c, fixtures, target)
+}
+
+// CopySeeder copies the fixtures to the target directory of the container, keeping their layout,
+// e.g. to serve them or to load them from a process of the container
+var CopySeeder Seeder = SeederFunc(func(ctx context.Context, c Container, fixtures fs.FS, target string) error {
+	_, err := copyFixtures(ctx, c, fixtures, "", target)
+	return err
+})
+
+// ExecSeeder copies the fixtures matching the pattern to the target directory of the container, then runs the
+// command returned for each of them in the order of their paths, e.g. a SQL client executing a script, a producer
+// publishing the messages of a file to the topic it is named after, or an object store client uploading it.
+// The fixtures are loaded in the order of their paths, and the first command failing stops the seeding.
+type ExecSeeder struct {
+	Pattern string                     // pattern of path.Match matched against the file names, all files if empty
+	Command func(file string) []string // command loading the fixture, given its path in the container
+}
+
+// Seed implements Seeder
+func (s ExecSeeder) Seed(ctx context.Context, c Container, fixtures fs.FS, target string) error {
+	if s.Command == nil {
+		return errors.New("no command to load the fixtures")
+	}
+
+	files, err := copyFixtures(ctx, c, fixtures, s.Pattern, target)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := execCommand(ctx, c, s.Command(file)...); err != nil {
+			return fmt.Errorf("%w: failed to load fixture %s", err, file)
+		}
+	}
+
+	return nil
+}
+
+// WithSeed loads the fixtures with the seeder once the container is ready, as a post-ready hook, so that the tests
+// start with the data they expect. A failure to load them fails the creation of the container.
+func WithSeed(seeder Seeder, fixtures fs.FS, target string) CustomizeRequestOption {
+	return WithLifecycleHooks(ContainerLifecycleHooks{
+		PostReadies: []ContainerHook{
+			func(ctx context.Context, c Container) error {
+				if err := seeder.Seed(ctx, c, fixtures, target); err != nil {
+					return fmt.Errorf("%w: seeding failed", err)
+				}
+				return nil
+			},
+		},
+	})
+}
+
+// copyFixtures copies the files of the file system whose name matches the pattern to the target directory of the
+// container, creating the directories first, and returns their paths in the container in lexical order.
+// An empty pattern matches all files.
+func copyFixtures(ctx context.Context, c Container, fixtures fs.FS, pattern string, target string) ([]string, error) {
+	var files []string
+	dirs := map[string]bool{target: true}
+
+	err := fs.WalkDir(fixtures, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if pattern != "" {
+			matched, err := path.Match(pattern, d.Name())
+			if err != nil {
+				return err
+			}
+			if !matched {
+				return nil
+			}
+		}
+
+		files = append(files, p)
+		dirs[path.Join(target, path.Dir(p))] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mkdir := []string{"mkdir", "-p"}
+	for dir := range dirs {
+		mkdir = append(mkdir, dir)
+	}
+	sort.Strings(mkdir[2:])
+
+	if err := execCommand(ctx, c, mkdir...); err != nil {
+		return nil, err
+	}
+
+	copied := make([]string, 0, len(files))
+	for _, f := range files {
+		content, err := fs.ReadFile(fixtures, f)
+		if err != nil {
+			return nil, err
+		}
+
+		dst := path.Join(target, f)
+		if err := c.CopyToContainer(ctx, content, dst, 0o644); err != nil {
+			return nil, fmt.Errorf("%w: failed to copy fixture %s", err, f)
+		}
+		copied = append(copied, dst)
+	}
+
+	return copied, nil
+}
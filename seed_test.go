@@ -0,0 +1,71 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSeed(t *testing.T) {
+	ctx := context.Background()
+
+	fixtures := fstest.MapFS{
+		"topics/orders.jsonl":   {Data: []byte(`{"id":1}` + "\n")},
+		"topics/payments.jsonl": {Data: []byte(`{"id":2}` + "\n")},
+		"README.md":             {Data: []byte("fixtures of the tests\n")},
+	}
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+		Started: true,
+	}
+	WithSeed(CopySeeder, fixtures, "/srv/fixtures")(&req)
+	// a stand-in for a producer publishing the messages to the topic the file is named after
+	WithSeed(ExecSeeder{
+		Pattern: "*.jsonl",
+		Command: func(file string) []string {
+			return []string{"sh", "-c", "echo $(basename " + file + " .jsonl) >> /tmp/topics"}
+		},
+	}, fixtures, "/tmp/seed")(&req)
+
+	c, err := GenericContainer(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Terminate(ctx))
+	})
+
+	result, err := c.ExecWithResult(ctx, []string{"cat", "/srv/fixtures/README.md", "/srv/fixtures/topics/orders.jsonl", "/tmp/topics"})
+	require.NoError(t, err)
+	assert.Equal(t, "fixtures of the tests\n{\"id\":1}\norders\npayments\n", string(result.Stdout))
+}
+
+func TestWithSeedFailure(t *testing.T) {
+	ctx := context.Background()
+
+	req := GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sleep", "60"},
+		},
+		Started: true,
+	}
+	WithSeed(ExecSeeder{
+		Command: func(file string) []string { return []string{"false"} },
+	}, fstest.MapFS{"data.sql": {Data: []byte("SELECT 1;")}}, "/tmp/seed")(&req)
+
+	c, err := GenericContainer(ctx, req)
+	if c != nil {
+		t.Cleanup(func() {
+			assert.NoError(t, c.Terminate(ctx))
+		})
+	}
+	assert.ErrorContains(t, err, "failed to load fixture /tmp/seed/data.sql: seeding failed")
+}
@@ -1,6 +1,9 @@
 package testcontainers
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"sync"
 
 	"github.com/google/uuid"
@@ -16,3 +19,47 @@ func sessionID() uuid.UUID {
 
 	return tcSessionID
 }
+
+// Session is the test session of the process: the containers, networks and volumes it creates are labelled with its
+// ID, and removed by the reaper once it ends
+type Session struct{}
+
+// CurrentSession returns the session of the process
+func CurrentSession() *Session {
+	return &Session{}
+}
+
+// ID returns the ID of the session, the value of the TestcontainerLabelSessionID label of its resources
+func (s *Session) ID() string {
+	return sessionID().String()
+}
+
+// AuditTrail returns the operations the session performed on the Docker daemon, in the order they completed, e.g. to
+// investigate a flaky test. Only the latest 10000 operations are kept.
+func (s *Session) AuditTrail() []AuditEntry {
+	return sessionAuditTrail.snapshot()
+}
+
+// WriteAuditTrail writes the audit trail of the session as a JSON array, e.g. to attach it to a support request
+func (s *Session) WriteAuditTrail(w io.Writer) error {
+	entries := s.AuditTrail()
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// Reapable lists the resources of the session the reaper removes once it ends, i.e. the containers, networks, volumes
+// and images labelled with the session. With the ryuk.dry.run configuration, Ryuk is not started and this tells what
+// it would remove, e.g. to check that a test labels all the resources it creates.
+func (s *Session) Reapable(ctx context.Context) ([]ReapTarget, error) {
+	provider, err := defaultDockerProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return reapTargets(ctx, provider.client, sessionLabels())
+}
@@ -0,0 +1,108 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const startLimitPollInterval = 100 * time.Millisecond
+
+// startLimiter bounds the containers created and started concurrently on the machine, across the test binaries of
+// all the packages run by go test, so that they don't overload the Docker daemon and time out. Each slot is a lock
+// file, locked by the process starting a container, and released once it is started or when the process exits.
+// A start locks as many slots as its cost.
+type startLimiter struct {
+	dir   string
+	slots int
+}
+
+// newStartLimiter returns the limiter of the configuration, nil if the starts are not limited
+func newStartLimiter(config TestContainersConfig) *startLimiter {
+	if config.MaxConcurrentStarts <= 0 {
+		return nil
+	}
+
+	return &startLimiter{
+		dir:   filepath.Join(os.TempDir(), "testcontainers-start-slots"),
+		slots: config.MaxConcurrentStarts,
+	}
+}
+
+// acquire locks as many free slots as the cost, waiting for them to be released by other starts, and returns
+// the function releasing them. The cost is at least 1 and at most the number of slots, a nil limiter doesn't wait.
+func (l *startLimiter) acquire(ctx context.Context, cost int) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > l.slots {
+		cost = l.slots
+	}
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	for {
+		release, err := l.tryAcquire(cost)
+		if err != nil {
+			return nil, err
+		}
+		if release != nil {
+			return release, nil
+		}
+
+		// the jitter spreads the retries of the starts waiting for slots
+		wait := startLimitPollInterval + time.Duration(rand.Int63n(int64(startLimitPollInterval)))
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: waiting for a slot to start the container, at most %d containers start concurrently", ctx.Err(), l.slots)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// tryAcquire locks as many free slots as the cost, or none, returning a nil release function when there were
+// not enough free slots
+func (l *startLimiter) tryAcquire(cost int) (func(), error) {
+	locked := make([]*os.File, 0, cost)
+	release := func() {
+		for _, f := range locked {
+			_ = unlockFile(f)
+			_ = f.Close()
+		}
+	}
+
+	for i := 0; i < l.slots && len(locked) < cost; i++ {
+		f, err := os.OpenFile(filepath.Join(l.dir, fmt.Sprintf("slot-%d.lock", i)), os.O_CREATE|os.O_RDWR, 0o666)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		ok, err := tryLockFile(f)
+		if err != nil || !ok {
+			_ = f.Close()
+			if err != nil {
+				release()
+				return nil, err
+			}
+			continue
+		}
+		locked = append(locked, f)
+	}
+
+	if len(locked) < cost {
+		release()
+		return nil, nil
+	}
+
+	return release, nil
+}
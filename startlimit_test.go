@@ -0,0 +1,56 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartLimiter(t *testing.T) {
+	l := &startLimiter{dir: t.TempDir(), slots: 3}
+
+	heavy, err := l.acquire(context.Background(), 2)
+	require.NoError(t, err)
+
+	light, err := l.acquire(context.Background(), 1)
+	require.NoError(t, err, "a slot is left")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = l.acquire(ctx, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "at most 3 containers start concurrently")
+
+	heavy()
+
+	// the cost is bounded by the number of slots, it waits for the other start to complete
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		all, err := l.acquire(context.Background(), 10)
+		assert.NoError(t, err)
+		all()
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the slots are acquired while a start holds one")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	light()
+	<-done
+}
+
+func TestStartLimiterUnlimited(t *testing.T) {
+	assert.Nil(t, newStartLimiter(TestContainersConfig{}))
+
+	release, err := newStartLimiter(TestContainersConfig{}).acquire(context.Background(), 5)
+	require.NoError(t, err)
+	release()
+
+	assert.Equal(t, 4, newStartLimiter(TestContainersConfig{MaxConcurrentStarts: 4}).slots)
+}
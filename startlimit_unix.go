@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package testcontainers
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile locks the file exclusively without waiting, and returns whether it was locked
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
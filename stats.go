@@ -0,0 +1,183 @@
+package testcontainers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// unlimitedMemoryThreshold is above any real memory limit: cgroup v1 reports a huge page aligned
+// value, close to the max int64, when the container has no memory limit
+const unlimitedMemoryThreshold = 1 << 62
+
+// statsResampleDelay is the time between the two samples needed to compute the CPU usage
+// when the backend does not report the previous CPU usage, like Podman
+const statsResampleDelay = time.Second
+
+// ContainerStats is a snapshot of the resource usage of a container, reported the same way
+// whether the host uses cgroup v1 or v2, and whatever the backend, Docker or Podman
+type ContainerStats struct {
+	Read          time.Time // time the sample was taken by the backend
+	CPUPercent    float64   // CPU usage in percent, where 100 is one fully used CPU
+	OnlineCPUs    int       // number of CPUs available to the container
+	MemoryUsage   uint64    // memory used in bytes, without the inactive page cache, like "docker stats"
+	MemoryLimit   uint64    // memory limit in bytes, 0 if the container is not limited
+	MemoryPercent float64   // memory usage relative to the limit, 0 if the container is not limited
+	PIDs          uint64    // number of processes and threads
+	NetworkRx     uint64    // bytes received on all the interfaces
+	NetworkTx     uint64    // bytes sent on all the interfaces
+	BlockRead     uint64    // bytes read from block devices
+	BlockWrite    uint64    // bytes written to block devices
+}
+
+// Stats returns the current resource usage of the container, normalized across cgroup versions and backends
+func (c *DockerContainer) Stats(ctx context.Context) (*ContainerStats, error) {
+	raw, err := c.stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw.PreCPUStats.SystemUsage == 0 {
+		// no previous sample to compute the CPU usage from, take a second one
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(statsResampleDelay):
+		}
+
+		next, err := c.stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		next.PreCPUStats = raw.CPUStats
+		raw = next
+	}
+
+	stats := normalizeStats(raw)
+	return &stats, nil
+}
+
+// normalizeStats maps the raw stats of the backend to ContainerStats
+func normalizeStats(raw *types.StatsJSON) ContainerStats {
+	stats := ContainerStats{
+		Read:        raw.Read,
+		CPUPercent:  cpuPercent(raw),
+		OnlineCPUs:  onlineCPUs(raw),
+		MemoryUsage: memoryUsage(raw),
+		PIDs:        raw.PidsStats.Current,
+	}
+
+	if limit := raw.MemoryStats.Limit; limit > 0 && limit < unlimitedMemoryThreshold {
+		stats.MemoryLimit = limit
+		stats.MemoryPercent = float64(stats.MemoryUsage) / float64(limit) * 100
+	}
+
+	for _, n := range raw.Networks {
+		stats.NetworkRx += n.RxBytes
+		stats.NetworkTx += n.TxBytes
+	}
+
+	// cgroup v1 names the operations "Read" and "Write", cgroup v2 "read" and "write"
+	for _, entry := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch {
+		case strings.EqualFold(entry.Op, "read"):
+			stats.BlockRead += entry.Value
+		case strings.EqualFold(entry.Op, "write"):
+			stats.BlockWrite += entry.Value
+		}
+	}
+
+	return stats
+}
+
+// sumStats sums the resource usage of several containers, e.g. the replicas of a compose service. The memory limit is
+// the sum of the limits, 0 if any of the containers is not limited, and the sample time is the latest one.
+func sumStats(samples []*ContainerStats) ContainerStats {
+	var total ContainerStats
+	limited := len(samples) > 0
+
+	for _, s := range samples {
+		if s.Read.After(total.Read) {
+			total.Read = s.Read
+		}
+		if s.OnlineCPUs > total.OnlineCPUs {
+			total.OnlineCPUs = s.OnlineCPUs
+		}
+		total.CPUPercent += s.CPUPercent
+		total.MemoryUsage += s.MemoryUsage
+		total.MemoryLimit += s.MemoryLimit
+		total.PIDs += s.PIDs
+		total.NetworkRx += s.NetworkRx
+		total.NetworkTx += s.NetworkTx
+		total.BlockRead += s.BlockRead
+		total.BlockWrite += s.BlockWrite
+
+		limited = limited && s.MemoryLimit > 0
+	}
+
+	if !limited {
+		total.MemoryLimit = 0
+	} else {
+		total.MemoryPercent = float64(total.MemoryUsage) / float64(total.MemoryLimit) * 100
+	}
+
+	return total
+}
+
+// memoryUsage excludes the inactive page cache from the usage, like the docker CLI does
+func memoryUsage(raw *types.StatsJSON) uint64 {
+	usage := raw.MemoryStats.Usage
+
+	// cgroup v1
+	if inactive, ok := raw.MemoryStats.Stats["total_inactive_file"]; ok && inactive < usage {
+		return usage - inactive
+	}
+	// cgroup v2
+	if inactive, ok := raw.MemoryStats.Stats["inactive_file"]; ok && inactive < usage {
+		return usage - inactive
+	}
+
+	return usage
+}
+
+// onlineCPUs falls back to the per CPU usage, which is only reported by cgroup v1
+func onlineCPUs(raw *types.StatsJSON) int {
+	if raw.CPUStats.OnlineCPUs > 0 {
+		return int(raw.CPUStats.OnlineCPUs)
+	}
+	if n := len(raw.CPUStats.CPUUsage.PercpuUsage); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// cpuPercent calculates the CPU usage the same way the docker CLI does
+func cpuPercent(stats *types.StatsJSON) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	return cpuDelta / systemDelta * float64(onlineCPUs(stats)) * 100
+}
+
+// stats returns a single sample of the resource usage of the container
+func (c *DockerContainer) stats(ctx context.Context) (*types.StatsJSON, error) {
+	resp, err := c.provider.client.ContainerStats(ctx, c.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,136 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUPercent(t *testing.T) {
+	stats := &types.StatsJSON{}
+	stats.PreCPUStats.CPUUsage.TotalUsage = 100
+	stats.PreCPUStats.SystemUsage = 1000
+	stats.CPUStats.CPUUsage.TotalUsage = 200
+	stats.CPUStats.SystemUsage = 2000
+	stats.CPUStats.OnlineCPUs = 4
+
+	assert.InDelta(t, 40.0, cpuPercent(stats), 0.001)
+
+	stats.CPUStats.OnlineCPUs = 0
+	stats.CPUStats.CPUUsage.PercpuUsage = []uint64{1, 2}
+	assert.InDelta(t, 20.0, cpuPercent(stats), 0.001)
+
+	// cgroup v2 reports neither the online CPUs on old daemons nor the per CPU usage
+	stats.CPUStats.CPUUsage.PercpuUsage = nil
+	assert.InDelta(t, 10.0, cpuPercent(stats), 0.001)
+
+	assert.Equal(t, 0.0, cpuPercent(&types.StatsJSON{}))
+}
+
+func TestNormalizeStatsCgroupV1(t *testing.T) {
+	raw := &types.StatsJSON{}
+	raw.MemoryStats.Usage = 100 << 20
+	raw.MemoryStats.Limit = 9223372036854771712 // no limit
+	raw.MemoryStats.Stats = map[string]uint64{
+		"total_inactive_file": 40 << 20,
+		"inactive_file":       10 << 20,
+	}
+	raw.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Op: "Read", Value: 10},
+		{Op: "Write", Value: 20},
+		{Op: "Total", Value: 30},
+	}
+	raw.Networks = map[string]types.NetworkStats{
+		"eth0": {RxBytes: 1, TxBytes: 2},
+		"eth1": {RxBytes: 3, TxBytes: 4},
+	}
+	raw.PidsStats.Current = 7
+
+	stats := normalizeStats(raw)
+
+	assert.Equal(t, uint64(60<<20), stats.MemoryUsage)
+	assert.Equal(t, uint64(0), stats.MemoryLimit)
+	assert.Equal(t, 0.0, stats.MemoryPercent)
+	assert.Equal(t, uint64(10), stats.BlockRead)
+	assert.Equal(t, uint64(20), stats.BlockWrite)
+	assert.Equal(t, uint64(4), stats.NetworkRx)
+	assert.Equal(t, uint64(6), stats.NetworkTx)
+	assert.Equal(t, uint64(7), stats.PIDs)
+}
+
+func TestNormalizeStatsCgroupV2(t *testing.T) {
+	raw := &types.StatsJSON{}
+	raw.MemoryStats.Usage = 100 << 20
+	raw.MemoryStats.Limit = 200 << 20
+	raw.MemoryStats.Stats = map[string]uint64{
+		"inactive_file": 20 << 20,
+	}
+	raw.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Op: "read", Value: 10},
+		{Op: "write", Value: 20},
+	}
+
+	stats := normalizeStats(raw)
+
+	assert.Equal(t, uint64(80<<20), stats.MemoryUsage)
+	assert.Equal(t, uint64(200<<20), stats.MemoryLimit)
+	assert.InDelta(t, 40.0, stats.MemoryPercent, 0.001)
+	assert.Equal(t, uint64(10), stats.BlockRead)
+	assert.Equal(t, uint64(20), stats.BlockWrite)
+	assert.Equal(t, 1, stats.OnlineCPUs)
+}
+
+func TestSumStats(t *testing.T) {
+	now := time.Now()
+
+	total := sumStats([]*ContainerStats{
+		{Read: now.Add(-time.Second), CPUPercent: 20, OnlineCPUs: 2, MemoryUsage: 100, MemoryLimit: 400, PIDs: 2, NetworkRx: 10, NetworkTx: 5},
+		{Read: now, CPUPercent: 30, OnlineCPUs: 4, MemoryUsage: 300, MemoryLimit: 400, PIDs: 3, NetworkRx: 20, NetworkTx: 15},
+	})
+	assert.Equal(t, ContainerStats{
+		Read:          now,
+		CPUPercent:    50,
+		OnlineCPUs:    4,
+		MemoryUsage:   400,
+		MemoryLimit:   800,
+		MemoryPercent: 50,
+		PIDs:          5,
+		NetworkRx:     30,
+		NetworkTx:     20,
+	}, total)
+
+	unlimited := sumStats([]*ContainerStats{
+		{MemoryUsage: 100, MemoryLimit: 400},
+		{MemoryUsage: 300},
+	})
+	assert.Equal(t, uint64(400), unlimited.MemoryUsage)
+	assert.Zero(t, unlimited.MemoryLimit, "a replica without limit is not limited")
+	assert.Zero(t, unlimited.MemoryPercent)
+}
+
+func TestContainerStats(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sh", "-c", "while true; do :; done"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	terminateContainerOnEnd(t, ctx, c)
+
+	stats, err := c.Stats(ctx)
+	require.NoError(t, err)
+
+	assert.Greater(t, stats.CPUPercent, 0.0)
+	assert.Greater(t, stats.MemoryUsage, uint64(0))
+	assert.GreaterOrEqual(t, stats.OnlineCPUs, 1)
+	assert.GreaterOrEqual(t, stats.PIDs, uint64(1))
+}
@@ -0,0 +1,154 @@
+package testcontainers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TeardownOptions configures the teardown of a container of a TeardownGroup
+type TeardownOptions struct {
+	// DependsOn are the containers the container uses, e.g. the database an application flushes its state to.
+	// They are torn down once the container is.
+	DependsOn []Container
+	// GracePeriod is how long the container is given to stop gracefully before it is killed, the stop timeout of
+	// the container if zero
+	GracePeriod time.Duration
+}
+
+// TeardownGroup terminates containers in the reverse order of their dependencies, so that the containers depending on
+// others are stopped first, e.g. an application before the database it flushes its state to. The containers without
+// dependencies between each other are torn down concurrently. Compose stacks tear their services down in the reverse
+// order of their depends_on attributes already.
+type TeardownGroup struct {
+	containers []Container
+	options    map[Container]TeardownOptions
+	lock       sync.Mutex
+}
+
+// NewTeardownGroup returns an empty group
+func NewTeardownGroup() *TeardownGroup {
+	return &TeardownGroup{options: map[Container]TeardownOptions{}}
+}
+
+// Add adds the container to the group with its dependencies, which are added too if they are not part of the group
+func (g *TeardownGroup) Add(c Container, opts TeardownOptions) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.add(c, opts)
+	for _, dependency := range opts.DependsOn {
+		if _, ok := g.options[dependency]; !ok {
+			g.add(dependency, TeardownOptions{})
+		}
+	}
+}
+
+func (g *TeardownGroup) add(c Container, opts TeardownOptions) {
+	if _, ok := g.options[c]; !ok {
+		g.containers = append(g.containers, c)
+	}
+	g.options[c] = opts
+}
+
+// Terminate stops the containers of the group in the reverse order of their dependencies, each one with its grace
+// period, and removes them. A failure does not prevent the other containers from being terminated, and a dependency
+// cycle terminates the containers of the cycle in the order they were added. The group is empty afterwards.
+func (g *TeardownGroup) Terminate(ctx context.Context) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	var errs []error
+	for _, wave := range teardownWaves(g.containers, g.options) {
+		errs = append(errs, g.terminateWave(ctx, wave)...)
+	}
+
+	g.containers = nil
+	g.options = map[Container]TeardownOptions{}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: failed to tear down %d containers", errs[0], len(errs))
+	}
+	return nil
+}
+
+// terminateWave stops and removes the containers concurrently, and returns their failures
+func (g *TeardownGroup) terminateWave(ctx context.Context, wave []Container) []error {
+	var (
+		errs []error
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	for _, c := range wave {
+		wg.Add(1)
+		go func(c Container) {
+			defer wg.Done()
+
+			var timeout *time.Duration
+			if grace := g.options[c].GracePeriod; grace > 0 {
+				timeout = &grace
+			}
+
+			// the container is removed even if it failed to stop gracefully
+			stopErr := c.Stop(ctx, timeout)
+			err := c.Terminate(ctx)
+			if err == nil && stopErr != nil {
+				err = fmt.Errorf("%w: failed to stop container %s", stopErr, c.GetContainerID())
+			}
+			if err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// teardownWaves groups the containers in waves torn down one after the other: each wave holds the containers no
+// remaining container depends on. The containers of a dependency cycle make up the last waves, one container each.
+func teardownWaves(containers []Container, options map[Container]TeardownOptions) [][]Container {
+	// number of remaining containers depending on each container
+	dependents := make(map[Container]int, len(containers))
+	for _, c := range containers {
+		for _, dependency := range options[c].DependsOn {
+			dependents[dependency]++
+		}
+	}
+
+	var waves [][]Container
+	remaining := containers
+	for len(remaining) > 0 {
+		var wave, next []Container
+		for _, c := range remaining {
+			if dependents[c] == 0 {
+				wave = append(wave, c)
+			} else {
+				next = append(next, c)
+			}
+		}
+
+		if len(wave) == 0 {
+			// a cycle, whose containers are torn down one after the other in the order they were added
+			for _, c := range next {
+				waves = append(waves, []Container{c})
+			}
+			return waves
+		}
+
+		for _, c := range wave {
+			for _, dependency := range options[c].DependsOn {
+				dependents[dependency]--
+			}
+		}
+
+		waves = append(waves, wave)
+		remaining = next
+	}
+
+	return waves
+}
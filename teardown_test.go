@@ -0,0 +1,144 @@
+package testcontainers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedContainer is a container identified by its name, for the tests of the teardown order
+type namedContainer struct {
+	Container
+	name string
+}
+
+func TestTeardownWaves(t *testing.T) {
+	app := &namedContainer{name: "app"}
+	worker := &namedContainer{name: "worker"}
+	cache := &namedContainer{name: "cache"}
+	db := &namedContainer{name: "db"}
+
+	options := map[Container]TeardownOptions{
+		app:    {DependsOn: []Container{db, cache}},
+		worker: {DependsOn: []Container{db}},
+		cache:  {DependsOn: []Container{db}},
+	}
+
+	waves := teardownWaves([]Container{db, cache, app, worker}, options)
+	assert.Equal(t, [][]Container{{app, worker}, {cache}, {db}}, waves)
+}
+
+func TestTeardownWavesCycle(t *testing.T) {
+	app := &namedContainer{name: "app"}
+	a := &namedContainer{name: "a"}
+	b := &namedContainer{name: "b"}
+
+	options := map[Container]TeardownOptions{
+		app: {DependsOn: []Container{a}},
+		a:   {DependsOn: []Container{b}},
+		b:   {DependsOn: []Container{a}},
+	}
+
+	waves := teardownWaves([]Container{a, b, app}, options)
+	assert.Equal(t, [][]Container{{app}, {a}, {b}}, waves, "the containers of a cycle are torn down last, one after the other")
+}
+
+// terminationRecorder records the order its containers are terminated in
+type terminationRecorder struct {
+	order []string
+	lock  sync.Mutex
+}
+
+// recordedContainer is a container recording its termination, for the tests of the teardown order
+type recordedContainer struct {
+	Container
+	name     string
+	recorder *terminationRecorder
+}
+
+func (c *recordedContainer) Stop(context.Context, *time.Duration) error {
+	return nil
+}
+
+func (c *recordedContainer) Terminate(context.Context) error {
+	// leaves the time to the containers terminated concurrently to be recorded first
+	time.Sleep(10 * time.Millisecond)
+
+	c.recorder.lock.Lock()
+	defer c.recorder.lock.Unlock()
+	c.recorder.order = append(c.recorder.order, c.name)
+	return nil
+}
+
+func TestTeardownGroupCycle(t *testing.T) {
+	recorder := &terminationRecorder{}
+	container := func(name string) *recordedContainer {
+		return &recordedContainer{name: name, recorder: recorder}
+	}
+	app, a, b, c := container("app"), container("a"), container("b"), container("c")
+
+	g := NewTeardownGroup()
+	g.Add(c, TeardownOptions{DependsOn: []Container{a}})
+	g.Add(a, TeardownOptions{DependsOn: []Container{b}})
+	g.Add(b, TeardownOptions{DependsOn: []Container{c}})
+	g.Add(app, TeardownOptions{DependsOn: []Container{a}})
+
+	require.NoError(t, g.Terminate(context.Background()))
+	assert.Equal(t, []string{"app", "c", "a", "b"}, recorder.order, "the containers of the cycle are terminated in the order they were added")
+}
+
+func TestTeardownGroup(t *testing.T) {
+	ctx := context.Background()
+
+	start := func(cmd string) Container {
+		c, err := GenericContainer(ctx, GenericContainerRequest{
+			ProviderType: providerType,
+			ContainerRequest: ContainerRequest{
+				Image: "docker.io/alpine",
+				Cmd:   []string{"sh", "-c", cmd},
+			},
+			Started: true,
+		})
+		require.NoError(t, err)
+		return c
+	}
+
+	// the database exits as soon as it is stopped, the application once it flushed its state
+	db := start("trap 'exit 0' TERM; while true; do sleep 0.1; done")
+	app := start("trap 'sleep 1; exit 0' TERM; while true; do sleep 0.1; done")
+
+	g := NewTeardownGroup()
+	g.Add(app, TeardownOptions{DependsOn: []Container{db}, GracePeriod: 10 * time.Second})
+
+	eventsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, errs := db.(*DockerContainer).provider.client.Events(eventsCtx, types.EventsOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", "container"),
+			filters.Arg("event", "destroy"),
+			filters.Arg("container", db.GetContainerID()),
+			filters.Arg("container", app.GetContainerID()),
+		),
+	})
+
+	require.NoError(t, g.Terminate(ctx))
+
+	var order []string
+	for len(order) < 2 {
+		select {
+		case e := <-events:
+			order = append(order, e.Actor.ID)
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(30 * time.Second):
+			t.Fatal("the containers were not destroyed")
+		}
+	}
+	assert.Equal(t, []string{app.GetContainerID(), db.GetContainerID()}, order, "the application is torn down before the database")
+}
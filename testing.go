@@ -2,7 +2,11 @@ package testcontainers
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 // SkipIfProviderIsNotHealthy is a utility function capable of skipping tests
@@ -20,3 +24,25 @@ func SkipIfProviderIsNotHealthy(t *testing.T) {
 		t.Skipf("Docker is not running. TestContainers can't perform is work without it: %s", err)
 	}
 }
+
+// isolatedNameMaxLength keeps the names within the limits of most databases, e.g. 63 bytes for Postgres identifiers
+const isolatedNameMaxLength = 48
+
+var unsafeIsolatedNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// IsolatedName returns a name unique to the test, derived from its name, to create resources isolating the test
+// from the other ones sharing a container, e.g. a database, a schema or a topic. It is made of lowercase letters,
+// digits and underscores only, and is unique across parallel tests, subtests and repeated runs of the test.
+func IsolatedName(t testing.TB) string {
+	prefix := strings.Trim(unsafeIsolatedNameChars.ReplaceAllString(strings.ToLower(t.Name()), "_"), "_")
+
+	// identifiers can't start with a digit
+	prefix = "t_" + prefix
+
+	suffix := "_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:12]
+	if len(prefix) > isolatedNameMaxLength-len(suffix) {
+		prefix = strings.TrimSuffix(prefix[:isolatedNameMaxLength-len(suffix)], "_")
+	}
+
+	return prefix + suffix
+}
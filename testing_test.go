@@ -1,7 +1,27 @@
 package testcontainers
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func ExampleSkipIfProviderIsNotHealthy() {
 	SkipIfProviderIsNotHealthy(&testing.T{})
 }
+
+func TestIsolatedName(t *testing.T) {
+	t.Run("Subtest with a Very/Long name, and symbols!", func(t *testing.T) {
+		name := IsolatedName(t)
+		other := IsolatedName(t)
+
+		if name == other {
+			t.Fatalf("expected unique names, got %s twice", name)
+		}
+		if len(name) > isolatedNameMaxLength {
+			t.Fatalf("expected at most %d characters, got %s", isolatedNameMaxLength, name)
+		}
+		if !regexp.MustCompile(`^t_testisolatedname_subtest_with_a_[a-z0-9_]*_[0-9a-f]{12}$`).MatchString(name) {
+			t.Fatalf("unexpected name %s", name)
+		}
+	})
+}
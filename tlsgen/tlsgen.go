@@ -0,0 +1,253 @@
+// Package tlsgen generates TLS material for tests: a certificate authority, and server and client certificates
+// issued by it for the hostnames and aliases of containers, to mount into the containers and to trust from the tests
+// and the wait strategies, instead of maintaining openssl scripts and certificates checked in with the tests.
+package tlsgen
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"path"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	// CAFile is the name of the certificate of the authority written by WithCertificate
+	CAFile = "ca.crt"
+	// CertFile is the name of the certificate written by WithCertificate
+	CertFile = "tls.crt"
+	// KeyFile is the name of the private key of the certificate written by WithCertificate
+	KeyFile = "tls.key"
+
+	// validity is how long the generated certificates are valid, long enough for any test run
+	validity = 7 * 24 * time.Hour
+)
+
+// CA is a certificate authority issuing certificates for tests
+type CA struct {
+	Certificate *x509.Certificate
+	CertPEM     []byte
+
+	key *ecdsa.PrivateKey
+}
+
+// Certificate is a certificate issued by a CA, with its private key, PEM encoded
+type Certificate struct {
+	Leaf    *x509.Certificate
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// NewCA creates a self-signed certificate authority with the given common name
+func NewCA(commonName string) (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := newTemplate(commonName)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to create the CA certificate", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{
+		Certificate: cert,
+		CertPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:         key,
+	}, nil
+}
+
+// IssueServer issues a server certificate for the given hostnames and IP addresses, e.g. the network aliases of a
+// container. localhost and the loopback addresses are always included, so that the tests and the wait strategies
+// can verify the certificate through the mapped ports.
+func (ca *CA) IssueServer(hosts ...string) (*Certificate, error) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost"}
+	}
+
+	template, err := newTemplate(hosts[0])
+	if err != nil {
+		return nil, err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	for _, h := range append(hosts, "localhost", "127.0.0.1", "::1") {
+		if ip := net.ParseIP(h); ip != nil {
+			if !containsIP(template.IPAddresses, ip) {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if !containsString(template.DNSNames, h) {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	return ca.issue(template)
+}
+
+// IssueClient issues a client certificate with the given common name, e.g. the user authenticated with mutual TLS
+func (ca *CA) IssueClient(commonName string) (*Certificate, error) {
+	template, err := newTemplate(commonName)
+	if err != nil {
+		return nil, err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	return ca.issue(template)
+}
+
+// CertPool returns a pool trusting the certificates issued by the authority
+func (ca *CA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+
+	return pool
+}
+
+// ClientConfig returns a TLS configuration trusting the certificates issued by the authority, for the clients of the
+// tests and the wait strategies, e.g. wait.ForHTTP("/").WithTLS(true, ca.ClientConfig(nil)). The client certificate
+// is presented to the servers requiring mutual TLS, if not nil.
+func (ca *CA) ClientConfig(client *Certificate) (*tls.Config, error) {
+	config := &tls.Config{RootCAs: ca.CertPool()}
+
+	if client != nil {
+		cert, err := client.TLSCertificate()
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// ServerConfig returns a TLS configuration serving the server certificate, e.g. for a server of the tests called by
+// a container, requiring the clients to present a certificate issued by the authority if requested
+func (ca *CA) ServerConfig(server *Certificate, requireClientCert bool) (*tls.Config, error) {
+	cert, err := server.TLSCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if requireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = ca.CertPool()
+	}
+
+	return config, nil
+}
+
+// TLSCertificate returns the certificate with its private key, to serve it or to present it as a client
+func (c *Certificate) TLSCertificate() (tls.Certificate, error) {
+	return tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+}
+
+// WithCertificate copies the certificate of the authority, the certificate and its private key to the directory of
+// the container as ca.crt, tls.crt and tls.key, before it starts. The directory must exist in the image,
+// e.g. /etc/ssl/private.
+func WithCertificate(ca *CA, cert *Certificate, dir string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) {
+		req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+			PostCreates: []testcontainers.ContainerHook{
+				func(ctx context.Context, c testcontainers.Container) error {
+					if err := c.CopyToContainer(ctx, ca.CertPEM, path.Join(dir, CAFile), 0o644); err != nil {
+						return err
+					}
+					if err := c.CopyToContainer(ctx, cert.CertPEM, path.Join(dir, CertFile), 0o644); err != nil {
+						return err
+					}
+					// the key is readable by any user, as the process of the image may not run as root
+					return c.CopyToContainer(ctx, cert.KeyPEM, path.Join(dir, KeyFile), 0o644)
+				},
+			},
+		})
+	}
+}
+
+func (ca *CA) issue(template *x509.Certificate) (*Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to issue the certificate of %s", err, template.Subject.CommonName)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	// PKCS #8 is understood by most servers, e.g. Java ones unlike the SEC 1 encoding
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificate{
+		Leaf:    leaf,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+func newTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Testcontainers"}},
+		// tolerate a clock of the containers behind the one of the host
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(validity),
+	}, nil
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
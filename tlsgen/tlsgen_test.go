@@ -0,0 +1,140 @@
+package tlsgen_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/tlsgen"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestIssueServer(t *testing.T) {
+	ca, err := tlsgen.NewCA("test CA")
+	require.NoError(t, err)
+	assert.True(t, ca.Certificate.IsCA)
+
+	cert, err := ca.IssueServer("nginx", "api.internal", "10.0.0.5")
+	require.NoError(t, err)
+
+	assert.Equal(t, "nginx", cert.Leaf.Subject.CommonName)
+	assert.Equal(t, []string{"nginx", "api.internal", "localhost"}, cert.Leaf.DNSNames)
+	assert.Len(t, cert.Leaf.IPAddresses, 3)
+
+	for _, host := range []string{"nginx", "api.internal", "localhost", "10.0.0.5", "127.0.0.1"} {
+		_, err := cert.Leaf.Verify(x509.VerifyOptions{
+			DNSName: host,
+			Roots:   ca.CertPool(),
+		})
+		assert.NoError(t, err, host)
+	}
+
+	other, err := tlsgen.NewCA("other CA")
+	require.NoError(t, err)
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: "nginx", Roots: other.CertPool()})
+	assert.Error(t, err, "the certificate is only trusted with its authority")
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, err := tlsgen.NewCA("test CA")
+	require.NoError(t, err)
+
+	serverCert, err := ca.IssueServer("localhost")
+	require.NoError(t, err)
+	clientCert, err := ca.IssueClient("alice")
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello " + r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS, err = ca.ServerConfig(serverCert, true)
+	require.NoError(t, err)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	config, err := ca.ClientConfig(clientCert)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello alice", string(body))
+
+	config, err = ca.ClientConfig(nil)
+	require.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	_, err = client.Get(server.URL)
+	assert.Error(t, err, "the server requires a client certificate")
+}
+
+func TestWithCertificate(t *testing.T) {
+	ctx := context.Background()
+
+	ca, err := tlsgen.NewCA("test CA")
+	require.NoError(t, err)
+	cert, err := ca.IssueServer("nginx")
+	require.NoError(t, err)
+	config, err := ca.ClientConfig(nil)
+	require.NoError(t, err)
+
+	nginxConf := `server {
+    listen 443 ssl;
+    ssl_certificate /etc/nginx/tls.crt;
+    ssl_certificate_key /etc/nginx/tls.key;
+    location / {
+        return 200 "hello over tls";
+    }
+}
+`
+
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "docker.io/nginx:stable-alpine",
+			ExposedPorts: []string{"443/tcp"},
+			LifecycleHooks: []testcontainers.ContainerLifecycleHooks{{
+				PostCreates: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						return c.CopyToContainer(ctx, []byte(nginxConf), "/etc/nginx/conf.d/default.conf", 0o644)
+					},
+				},
+			}},
+			// the certificate is verified with the authority
+			WaitingFor: wait.ForHTTP("/").WithPort("443/tcp").WithTLS(true, config),
+		},
+		Started: true,
+	}
+	tlsgen.WithCertificate(ca, cert, "/etc/nginx")(&req)
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(ctx))
+	})
+
+	endpoint, err := container.PortEndpoint(ctx, "443/tcp", "https")
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(endpoint)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello over tls", string(body))
+
+	_, err = (&http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{}}}).Get(endpoint)
+	assert.Error(t, err, "the authority is not trusted by default")
+}
@@ -2,6 +2,7 @@ package wait
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -16,6 +17,9 @@ type ExitStrategy struct {
 
 	// additional properties
 	PollInterval time.Duration
+
+	// expected exit code of the container, any code is accepted if nil
+	exitCode *int
 }
 
 //NewExitStrategy constructs with polling interval of 100 milliseconds without timeout by default
@@ -36,6 +40,13 @@ func (ws *ExitStrategy) WithExitTimeout(exitTimeout time.Duration) *ExitStrategy
 	return ws
 }
 
+// WithExitCode fails the strategy if the container exits with another code than the given one,
+// e.g. 0 for a one-shot container which must complete successfully
+func (ws *ExitStrategy) WithExitCode(exitCode int) *ExitStrategy {
+	ws.exitCode = &exitCode
+	return ws
+}
+
 // WithPollInterval can be used to override the default polling interval of 100 milliseconds
 func (ws *ExitStrategy) WithPollInterval(pollInterval time.Duration) *ExitStrategy {
 	ws.PollInterval = pollInterval
@@ -78,6 +89,9 @@ func (ws *ExitStrategy) WaitUntilReady(ctx context.Context, target StrategyTarge
 				time.Sleep(ws.PollInterval)
 				continue
 			}
+			if ws.exitCode != nil && state.ExitCode != *ws.exitCode {
+				return fmt.Errorf("container exited with code %d instead of %d", state.ExitCode, *ws.exitCode)
+			}
 			return nil
 		}
 	}
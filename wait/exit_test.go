@@ -8,10 +8,13 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/go-connections/nat"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
 )
 
 type exitStrategyTarget struct {
 	isRunning bool
+	exitCode  int
 	err       error
 }
 
@@ -31,12 +34,12 @@ func (st exitStrategyTarget) Logs(ctx context.Context) (io.ReadCloser, error) {
 	return nil, nil
 }
 
-func (st exitStrategyTarget) Exec(ctx context.Context, cmd []string) (int, io.Reader, error) {
+func (st exitStrategyTarget) Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error) {
 	return 0, nil, nil
 }
 
 func (st exitStrategyTarget) State(ctx context.Context) (*types.ContainerState, error) {
-	return &types.ContainerState{Running: st.isRunning}, nil
+	return &types.ContainerState{Running: st.isRunning, ExitCode: st.exitCode}, nil
 }
 
 func TestWaitForExit(t *testing.T) {
@@ -49,3 +52,16 @@ func TestWaitForExit(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestWaitForExitWithExitCode(t *testing.T) {
+	wg := NewExitStrategy().WithExitTimeout(100 * time.Millisecond).WithExitCode(0)
+
+	if err := wg.WaitUntilReady(context.Background(), exitStrategyTarget{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := wg.WaitUntilReady(context.Background(), exitStrategyTarget{exitCode: 3})
+	if err == nil || err.Error() != "container exited with code 3 instead of 0" {
+		t.Fatalf("expected the exit code to be checked, got %v", err)
+	}
+}
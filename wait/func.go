@@ -0,0 +1,90 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Implement interface
+var _ Strategy = (*FuncStrategy)(nil)
+
+// FuncStrategy will wait until a function checking the container succeeds, e.g. the Ping of the client of the
+// service in the container, without implementing a Strategy
+type FuncStrategy struct {
+	// all Strategies should have a startupTimeout to avoid waiting infinitely
+	startupTimeout time.Duration
+
+	// additional properties
+	Check           func(ctx context.Context, target StrategyTarget) error // succeeds once the container is ready
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration // the interval doubles after each failed check up to this one, fixed if not greater
+}
+
+// NewFuncStrategy constructs a strategy calling the function until it succeeds
+func NewFuncStrategy(check func(ctx context.Context, target StrategyTarget) error) *FuncStrategy {
+	return &FuncStrategy{
+		startupTimeout: defaultStartupTimeout(),
+		Check:          check,
+		PollInterval:   defaultPollInterval(),
+	}
+}
+
+// ForFunc is a convenience method to assign FuncStrategy
+//
+// For Example:
+// wait.
+//     ForFunc(func(ctx context.Context, target wait.StrategyTarget) error {
+//         return client.Ping(ctx)
+//     }).
+//     WithStartupTimeout(30 * time.Second)
+func ForFunc(check func(ctx context.Context, target StrategyTarget) error) *FuncStrategy {
+	return NewFuncStrategy(check)
+}
+
+// WithStartupTimeout can be used to change the default startup timeout
+func (ws *FuncStrategy) WithStartupTimeout(startupTimeout time.Duration) *FuncStrategy {
+	ws.startupTimeout = startupTimeout
+	return ws
+}
+
+// WithPollInterval can be used to override the default polling interval of 100 milliseconds
+func (ws *FuncStrategy) WithPollInterval(pollInterval time.Duration) *FuncStrategy {
+	ws.PollInterval = pollInterval
+	return ws
+}
+
+// WithBackoff doubles the polling interval after each failed check, up to the given one, e.g. to spare a service
+// slow to start from frequent checks
+func (ws *FuncStrategy) WithBackoff(maxPollInterval time.Duration) *FuncStrategy {
+	ws.MaxPollInterval = maxPollInterval
+	return ws
+}
+
+// WaitUntilReady implements Strategy.WaitUntilReady
+func (ws *FuncStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) error {
+	// limit context to startupTimeout
+	ctx, cancelContext := context.WithTimeout(ctx, ws.startupTimeout)
+	defer cancelContext()
+
+	interval := ws.PollInterval
+	for {
+		err := ws.Check(ctx, target)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: last check failed: %s", ctx.Err(), err)
+		case <-time.After(interval):
+		}
+
+		if interval < ws.MaxPollInterval {
+			interval *= 2
+			if interval > ws.MaxPollInterval {
+				interval = ws.MaxPollInterval
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForFunc(t *testing.T) {
+	calls := 0
+	wg := ForFunc(func(ctx context.Context, target StrategyTarget) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}).WithPollInterval(10 * time.Millisecond)
+
+	if err := wg.WaitUntilReady(context.Background(), exitStrategyTarget{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected the function to be called until it succeeds, got %d calls", calls)
+	}
+}
+
+func TestWaitForFuncTimeout(t *testing.T) {
+	wg := ForFunc(func(ctx context.Context, target StrategyTarget) error {
+		return errors.New("connection refused")
+	}).WithStartupTimeout(100 * time.Millisecond).WithPollInterval(10 * time.Millisecond)
+
+	err := wg.WaitUntilReady(context.Background(), exitStrategyTarget{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	if err.Error() != "context deadline exceeded: last check failed: connection refused" {
+		t.Fatalf("expected the last error of the function, got %v", err)
+	}
+}
+
+func TestWaitForFuncWithBackoff(t *testing.T) {
+	var calls []time.Time
+	wg := ForFunc(func(ctx context.Context, target StrategyTarget) error {
+		calls = append(calls, time.Now())
+		if len(calls) < 4 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}).WithPollInterval(20 * time.Millisecond).WithBackoff(40 * time.Millisecond)
+
+	if err := wg.WaitUntilReady(context.Background(), exitStrategyTarget{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 20ms, then 40ms twice
+	if elapsed := calls[3].Sub(calls[0]); elapsed < 100*time.Millisecond {
+		t.Fatalf("expected the interval to double up to the maximum, checked within %s", elapsed)
+	}
+	if elapsed := calls[3].Sub(calls[2]); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected the interval to stay at the maximum, checked within %s", elapsed)
+	}
+}
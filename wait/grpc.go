@@ -0,0 +1,172 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// Implement interface
+var _ Strategy = (*GRPCStrategy)(nil)
+
+// GRPCStrategy will wait until the gRPC server in the container lists the given service
+// through the server reflection API
+type GRPCStrategy struct {
+	// all Strategies should have a startupTimeout to avoid waiting infinitely
+	startupTimeout time.Duration
+
+	// additional properties
+	Port         nat.Port
+	Service      string                           // fully qualified service name, e.g. "grpc.health.v1.Health"
+	Credentials  credentials.TransportCredentials // transport credentials, defaults to insecure
+	PollInterval time.Duration
+}
+
+// NewGRPCStrategy constructs a gRPC strategy waiting on the given port for the given service
+func NewGRPCStrategy(port nat.Port, fullServiceName string) *GRPCStrategy {
+	return &GRPCStrategy{
+		startupTimeout: defaultStartupTimeout(),
+		Port:           port,
+		Service:        fullServiceName,
+		PollInterval:   defaultPollInterval(),
+	}
+}
+
+// fluent builders for each property
+// since go has neither covariance nor generics, the return type must be the type of the concrete implementation
+// this is true for all properties, even the "shared" ones like startupTimeout
+
+// WithStartupTimeout can be used to change the default startup timeout
+func (ws *GRPCStrategy) WithStartupTimeout(startupTimeout time.Duration) *GRPCStrategy {
+	ws.startupTimeout = startupTimeout
+	return ws
+}
+
+// WithPollInterval can be used to override the default polling interval of 100 milliseconds
+func (ws *GRPCStrategy) WithPollInterval(pollInterval time.Duration) *GRPCStrategy {
+	ws.PollInterval = pollInterval
+	return ws
+}
+
+// WithTransportCredentials can be used to connect to servers using TLS
+func (ws *GRPCStrategy) WithTransportCredentials(creds credentials.TransportCredentials) *GRPCStrategy {
+	ws.Credentials = creds
+	return ws
+}
+
+// ForGRPCService is the default construction for the fluid interface.
+//
+// For Example:
+// wait.
+//     ForGRPCService("50051/tcp", "helloworld.Greeter").
+//     WithStartupTimeout(30 * time.Second)
+func ForGRPCService(port nat.Port, fullServiceName string) *GRPCStrategy {
+	return NewGRPCStrategy(port, fullServiceName)
+}
+
+// WaitUntilReady implements Strategy.WaitUntilReady
+func (ws *GRPCStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) (err error) {
+	// limit context to startupTimeout
+	ctx, cancelContext := context.WithTimeout(ctx, ws.startupTimeout)
+	defer cancelContext()
+
+	ipAddress, err := target.Host(ctx)
+	if err != nil {
+		return
+	}
+
+	var port nat.Port
+	port, err = target.MappedPort(ctx, ws.Port)
+
+	for port == "" {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s:%w", ctx.Err(), err)
+		case <-time.After(ws.PollInterval):
+			port, err = target.MappedPort(ctx, ws.Port)
+		}
+	}
+
+	if port.Proto() != "tcp" {
+		return errors.New("Cannot use gRPC client on non-TCP ports")
+	}
+
+	creds := ws.Credentials
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
+	address := net.JoinHostPort(ipAddress, strconv.Itoa(port.Int()))
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := rpb.NewServerReflectionClient(conn)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s:%w", ctx.Err(), err)
+		case <-time.After(ws.PollInterval):
+			var services []string
+			services, err = listServices(ctx, client)
+			if err != nil {
+				continue
+			}
+			for _, s := range services {
+				if s == ws.Service {
+					return nil
+				}
+			}
+			err = fmt.Errorf("service %s is not registered, found %v", ws.Service, services)
+		}
+	}
+}
+
+// listServices asks the reflection service for all registered services
+func listServices(ctx context.Context, client rpb.ServerReflectionClient) ([]string, error) {
+	stream, err := client.ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	err = stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("reflection error %d: %s", errResp.ErrorCode, errResp.ErrorMessage)
+	}
+
+	listResp := resp.GetListServicesResponse()
+	if listResp == nil {
+		return nil, errors.New("unexpected reflection response")
+	}
+
+	services := make([]string, 0, len(listResp.Service))
+	for _, s := range listResp.Service {
+		services = append(services, s.Name)
+	}
+
+	return services, nil
+}
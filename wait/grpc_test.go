@@ -0,0 +1,55 @@
+package wait
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func startGRPCServer(t *testing.T, register func(s *grpc.Server)) *net.TCPAddr {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	register(s)
+	reflection.Register(s)
+
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	return listener.Addr().(*net.TCPAddr)
+}
+
+func TestGRPCStrategyWaitUntilReady(t *testing.T) {
+	addr := startGRPCServer(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	})
+
+	wg := ForGRPCService("50051/tcp", "grpc.health.v1.Health").WithStartupTimeout(5 * time.Second)
+	err := wg.WaitUntilReady(context.Background(), listenerStrategyTarget{addr: addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPCStrategyFailsForUnregisteredService(t *testing.T) {
+	addr := startGRPCServer(t, func(s *grpc.Server) {})
+
+	wg := ForGRPCService("50051/tcp", "grpc.health.v1.Health").WithStartupTimeout(500 * time.Millisecond)
+	err := wg.WaitUntilReady(context.Background(), listenerStrategyTarget{addr: addr})
+	if err == nil {
+		t.Fatal("expected an error for a service which is not registered")
+	}
+}
@@ -15,6 +15,16 @@ import (
 // Implement interface
 var _ Strategy = (*HostPortStrategy)(nil)
 
+var (
+	// ErrPortNotListening is returned when no process listens on the port inside the container,
+	// which usually means the application failed to start
+	ErrPortNotListening = errors.New("port is not listening inside the container")
+
+	// ErrPortNotReachable is returned when the port listens inside the container but the published
+	// host port does not accept connections, which points to a port mapping or networking problem
+	ErrPortNotReachable = errors.New("published port is not reachable from the host")
+)
+
 type HostPortStrategy struct {
 	// Port is a string containing port number and protocol in the format "80/tcp"
 	// which
@@ -39,6 +49,8 @@ func NewHostPortStrategy(port nat.Port) *HostPortStrategy {
 
 // ForListeningPort is a helper similar to those in Wait.java
 // https://github.com/testcontainers/testcontainers-java/blob/1d85a3834bd937f80aad3a4cec249c027f31aeb4/core/src/main/java/org/testcontainers/containers/wait/strategy/Wait.java
+// It waits until the port is listening inside the container and the published host port accepts connections.
+// On timeout, the error wraps ErrPortNotListening or ErrPortNotReachable depending on which check failed.
 func ForListeningPort(port nat.Port) *HostPortStrategy {
 	return NewHostPortStrategy(port)
 }
@@ -114,47 +126,78 @@ func (hp *HostPortStrategy) WaitUntilReady(ctx context.Context, target StrategyT
 	proto := port.Proto()
 	portNumber := port.Int()
 	portString := strconv.Itoa(portNumber)
-
-	//external check
-	dialer := net.Dialer{}
 	address := net.JoinHostPort(ipAddress, portString)
+	command := buildInternalCheckCommand(internalPort.Int())
+
+	// both checks are retried until they succeed together, so that on timeout the error
+	// tells whether the application never listened or the published port never answered
+	var listening, reachable bool
 	for {
-		conn, err := dialer.DialContext(ctx, proto, address)
-		if err != nil {
-			if v, ok := err.(*net.OpError); ok {
-				if v2, ok := (v.Err).(*os.SyscallError); ok {
-					if isConnRefusedErr(v2.Err) {
-						time.Sleep(waitInterval)
-						continue
-					}
-				}
+		select {
+		case <-ctx.Done():
+			if !listening {
+				return fmt.Errorf("%w: port %s inside the container: %s", ErrPortNotListening, internalPort, ctx.Err())
 			}
+			return fmt.Errorf("%w: %s published as %s: %s", ErrPortNotReachable, internalPort, address, ctx.Err())
+		case <-time.After(waitInterval):
+		}
+
+		listening, err = internalCheck(ctx, target, command)
+		if err != nil {
 			return err
-		} else {
-			_ = conn.Close()
-			break
+		}
+
+		reachable, err = externalCheck(ctx, proto, address)
+		if err != nil {
+			return err
+		}
+
+		if listening && reachable {
+			return nil
 		}
 	}
+}
 
-	//internal check
-	command := buildInternalCheckCommand(internalPort.Int())
-	for {
+// internalCheck reports whether a process listens on the port from inside the container
+func internalCheck(ctx context.Context, target StrategyTarget, command string) (bool, error) {
+	exitCode, _, err := target.Exec(ctx, []string{"/bin/sh", "-c", command})
+	if err != nil {
 		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-		exitCode, _, err := target.Exec(ctx, []string{"/bin/sh", "-c", command})
-		if err != nil {
-			return fmt.Errorf("%w, host port waiting failed", err)
+			// the timeout is reported by the caller, with the state of both checks
+			return false, nil
 		}
+		return false, fmt.Errorf("%w, host port waiting failed", err)
+	}
+
+	if exitCode == 126 {
+		return false, errors.New("/bin/sh command not executable")
+	}
+
+	return exitCode == 0, nil
+}
 
-		if exitCode == 0 {
-			break
-		} else if exitCode == 126 {
-			return errors.New("/bin/sh command not executable")
+// externalCheck reports whether the published port accepts connections from the host
+func externalCheck(ctx context.Context, proto string, address string) (bool, error) {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, proto, address)
+	if err == nil {
+		_ = conn.Close()
+		return true, nil
+	}
+
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	if v, ok := err.(*net.OpError); ok {
+		if v2, ok := (v.Err).(*os.SyscallError); ok {
+			if isConnRefusedErr(v2.Err) {
+				return false, nil
+			}
 		}
 	}
 
-	return nil
+	return false, fmt.Errorf("%w: %v", ErrPortNotReachable, err)
 }
 
 func buildInternalCheckCommand(internalPort int) string {
@@ -0,0 +1,108 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+// hostPortStrategyTarget publishes the port on addr and reports the internal check result through exitCode
+type hostPortStrategyTarget struct {
+	addr     *net.TCPAddr
+	exitCode int
+}
+
+func (st hostPortStrategyTarget) Host(ctx context.Context) (string, error) {
+	return st.addr.IP.String(), nil
+}
+
+func (st hostPortStrategyTarget) Ports(ctx context.Context) (nat.PortMap, error) {
+	return nil, nil
+}
+
+func (st hostPortStrategyTarget) MappedPort(ctx context.Context, n nat.Port) (nat.Port, error) {
+	return nat.NewPort("tcp", strconv.Itoa(st.addr.Port))
+}
+
+func (st hostPortStrategyTarget) Logs(ctx context.Context) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func (st hostPortStrategyTarget) Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error) {
+	return st.exitCode, nil, nil
+}
+
+func (st hostPortStrategyTarget) State(ctx context.Context) (*types.ContainerState, error) {
+	return nil, nil
+}
+
+func listen(t *testing.T) *net.TCPAddr {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return listener.Addr().(*net.TCPAddr)
+}
+
+// closedPort returns the address of a port nobody listens on
+func closedPort(t *testing.T) *net.TCPAddr {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	_ = listener.Close()
+
+	return addr
+}
+
+func TestHostPortStrategyReady(t *testing.T) {
+	wg := ForListeningPort("80/tcp").WithStartupTimeout(5 * time.Second)
+
+	err := wg.WaitUntilReady(context.Background(), hostPortStrategyTarget{addr: listen(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHostPortStrategyNotListening(t *testing.T) {
+	wg := ForListeningPort("80/tcp").WithStartupTimeout(500 * time.Millisecond)
+
+	err := wg.WaitUntilReady(context.Background(), hostPortStrategyTarget{addr: listen(t), exitCode: 1})
+	if !errors.Is(err, ErrPortNotListening) {
+		t.Fatalf("expected ErrPortNotListening, got %v", err)
+	}
+}
+
+func TestHostPortStrategyNotReachable(t *testing.T) {
+	wg := ForListeningPort("80/tcp").WithStartupTimeout(500 * time.Millisecond)
+
+	err := wg.WaitUntilReady(context.Background(), hostPortStrategyTarget{addr: closedPort(t)})
+	if !errors.Is(err, ErrPortNotReachable) {
+		t.Fatalf("expected ErrPortNotReachable, got %v", err)
+	}
+}
+
+func TestHostPortStrategyShellNotExecutable(t *testing.T) {
+	wg := ForListeningPort("80/tcp").WithStartupTimeout(5 * time.Second)
+
+	err := wg.WaitUntilReady(context.Background(), hostPortStrategyTarget{addr: listen(t), exitCode: 126})
+	if err == nil || errors.Is(err, ErrPortNotListening) || errors.Is(err, ErrPortNotReachable) {
+		t.Fatalf("expected the shell error, got %v", err)
+	}
+}
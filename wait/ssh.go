@@ -0,0 +1,217 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"golang.org/x/crypto/ssh"
+)
+
+// Implement interface
+var _ Strategy = (*SSHStrategy)(nil)
+
+// sshClientVersion is the identification string sent to the server during the banner exchange
+const sshClientVersion = "SSH-2.0-testcontainers"
+
+// maxSSHBannerLines is the number of lines a server may send before its identification string,
+// see https://www.rfc-editor.org/rfc/rfc4253#section-4.2
+const maxSSHBannerLines = 32
+
+// SSHStrategy will wait until the SSH server in the container completes the transport banner exchange.
+// If credentials are configured, the strategy also waits until the server accepts them.
+type SSHStrategy struct {
+	// all Strategies should have a startupTimeout to avoid waiting infinitely
+	startupTimeout time.Duration
+
+	// additional properties
+	Port         nat.Port
+	User         string
+	Password     string
+	PrivateKey   []byte // PEM encoded private key
+	PollInterval time.Duration
+}
+
+// NewSSHStrategy constructs an SSH strategy waiting on the given port, without authentication
+func NewSSHStrategy(port nat.Port) *SSHStrategy {
+	return &SSHStrategy{
+		startupTimeout: defaultStartupTimeout(),
+		Port:           port,
+		PollInterval:   defaultPollInterval(),
+	}
+}
+
+// fluent builders for each property
+// since go has neither covariance nor generics, the return type must be the type of the concrete implementation
+// this is true for all properties, even the "shared" ones like startupTimeout
+
+// WithStartupTimeout can be used to change the default startup timeout
+func (ws *SSHStrategy) WithStartupTimeout(startupTimeout time.Duration) *SSHStrategy {
+	ws.startupTimeout = startupTimeout
+	return ws
+}
+
+// WithPollInterval can be used to override the default polling interval of 100 milliseconds
+func (ws *SSHStrategy) WithPollInterval(pollInterval time.Duration) *SSHStrategy {
+	ws.PollInterval = pollInterval
+	return ws
+}
+
+// WithPassword makes the strategy wait until the server accepts the given user and password
+func (ws *SSHStrategy) WithPassword(user string, password string) *SSHStrategy {
+	ws.User = user
+	ws.Password = password
+	return ws
+}
+
+// WithPrivateKey makes the strategy wait until the server accepts the given user and PEM encoded private key
+func (ws *SSHStrategy) WithPrivateKey(user string, pemBytes []byte) *SSHStrategy {
+	ws.User = user
+	ws.PrivateKey = pemBytes
+	return ws
+}
+
+// ForSSH is the default construction for the fluid interface.
+//
+// For Example:
+// wait.
+//     ForSSH("22/tcp").
+//     WithPassword("foo", "pass")
+func ForSSH(port nat.Port) *SSHStrategy {
+	return NewSSHStrategy(port)
+}
+
+// WaitUntilReady implements Strategy.WaitUntilReady
+func (ws *SSHStrategy) WaitUntilReady(ctx context.Context, target StrategyTarget) (err error) {
+	// limit context to startupTimeout
+	ctx, cancelContext := context.WithTimeout(ctx, ws.startupTimeout)
+	defer cancelContext()
+
+	ipAddress, err := target.Host(ctx)
+	if err != nil {
+		return
+	}
+
+	var port nat.Port
+	port, err = target.MappedPort(ctx, ws.Port)
+
+	for port == "" {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s:%w", ctx.Err(), err)
+		case <-time.After(ws.PollInterval):
+			port, err = target.MappedPort(ctx, ws.Port)
+		}
+	}
+
+	if port.Proto() != "tcp" {
+		return errors.New("Cannot use SSH client on non-TCP ports")
+	}
+
+	authMethods, err := ws.authMethods()
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(ipAddress, strconv.Itoa(port.Int()))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s:%w", ctx.Err(), err)
+		case <-time.After(ws.PollInterval):
+			if len(authMethods) == 0 {
+				err = ws.exchangeBanner(ctx, address)
+			} else {
+				err = ws.authenticate(ctx, address, authMethods)
+			}
+			if err != nil {
+				continue
+			}
+			return nil
+		}
+	}
+}
+
+func (ws *SSHStrategy) authMethods() ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if len(ws.PrivateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(ws.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid private key", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if ws.Password != "" {
+		methods = append(methods, ssh.Password(ws.Password))
+	}
+
+	return methods, nil
+}
+
+// exchangeBanner sends the client identification and waits for the server identification string
+func (ws *SSHStrategy) exchangeBanner(ctx context.Context, address string) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte(sshClientVersion + "\r\n")); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	for i := 0; i < maxSSHBannerLines; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "SSH-") {
+			return nil
+		}
+	}
+
+	return errors.New("no SSH identification string received")
+}
+
+// authenticate completes a full SSH handshake including user authentication
+func (ws *SSHStrategy) authenticate(ctx context.Context, address string, authMethods []ssh.AuthMethod) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            ws.User,
+		Auth:            authMethods,
+		ClientVersion:   sshClientVersion,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // test containers generate their host keys on startup
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		return err
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs).Close()
+}
@@ -0,0 +1,153 @@
+package wait
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"golang.org/x/crypto/ssh"
+
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+type listenerStrategyTarget struct {
+	addr *net.TCPAddr
+}
+
+func (st listenerStrategyTarget) Host(ctx context.Context) (string, error) {
+	return st.addr.IP.String(), nil
+}
+
+func (st listenerStrategyTarget) Ports(ctx context.Context) (nat.PortMap, error) {
+	return nil, nil
+}
+
+func (st listenerStrategyTarget) MappedPort(ctx context.Context, n nat.Port) (nat.Port, error) {
+	return nat.NewPort("tcp", strconv.Itoa(st.addr.Port))
+}
+
+func (st listenerStrategyTarget) Logs(ctx context.Context) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func (st listenerStrategyTarget) Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error) {
+	return 0, nil, nil
+}
+
+func (st listenerStrategyTarget) State(ctx context.Context) (*types.ContainerState, error) {
+	return nil, nil
+}
+
+func startSSHServer(t *testing.T, user string, password string) *net.TCPAddr {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == user && string(pass) == password {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					_ = ch.Reject(ssh.Prohibited, "no channels")
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr)
+}
+
+func TestSSHStrategyBannerExchange(t *testing.T) {
+	addr := startSSHServer(t, "foo", "pass")
+
+	wg := ForSSH("22/tcp").WithStartupTimeout(5 * time.Second)
+	err := wg.WaitUntilReady(context.Background(), listenerStrategyTarget{addr: addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSSHStrategyPasswordAuthentication(t *testing.T) {
+	addr := startSSHServer(t, "foo", "pass")
+
+	wg := ForSSH("22/tcp").WithPassword("foo", "pass").WithStartupTimeout(5 * time.Second)
+	err := wg.WaitUntilReady(context.Background(), listenerStrategyTarget{addr: addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSSHStrategyFailsWithWrongPassword(t *testing.T) {
+	addr := startSSHServer(t, "foo", "pass")
+
+	wg := ForSSH("22/tcp").WithPassword("foo", "wrong").WithStartupTimeout(500 * time.Millisecond)
+	err := wg.WaitUntilReady(context.Background(), listenerStrategyTarget{addr: addr})
+	if err == nil {
+		t.Fatal("expected an authentication error")
+	}
+}
+
+func TestSSHStrategyFailsWithoutSSHServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n"))
+			_ = conn.Close()
+		}
+	}()
+
+	wg := ForSSH("22/tcp").WithStartupTimeout(500 * time.Millisecond)
+	err = wg.WaitUntilReady(context.Background(), listenerStrategyTarget{addr: listener.Addr().(*net.TCPAddr)})
+	if err == nil {
+		t.Fatal("expected an error for a non SSH server")
+	}
+}
@@ -0,0 +1,198 @@
+package testcontainers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	defaultWatchdogInterval    = 5 * time.Second
+	defaultWatchdogGracePeriod = 30 * time.Second
+)
+
+// WatchdogAction defines what happens to a container exceeding its thresholds
+type WatchdogAction int
+
+const (
+	// WatchdogFlag only records and logs the violation
+	WatchdogFlag WatchdogAction = iota
+	// WatchdogTerminate records the violation and terminates the container
+	WatchdogTerminate
+)
+
+// WatchdogOptions configures the thresholds and the behavior of a Watchdog.
+// Zero thresholds are not checked.
+type WatchdogOptions struct {
+	MaxMemory     int64                   // memory usage in bytes
+	MaxCPUPercent float64                 // CPU usage in percent, where 100 is one fully used CPU
+	GracePeriod   time.Duration           // how long the thresholds may be exceeded, defaults to 30 seconds
+	Interval      time.Duration           // how often stats are sampled, defaults to 5 seconds
+	Action        WatchdogAction          // what to do with a container exceeding its thresholds
+	OnViolation   func(WatchdogViolation) // optional callback invoked for each violation
+}
+
+// WatchdogViolation describes a container that exceeded its thresholds for longer than the grace period
+type WatchdogViolation struct {
+	ContainerID   string
+	Memory        int64
+	CPUPercent    float64
+	ExceededSince time.Time
+	DetectedAt    time.Time
+}
+
+func (v WatchdogViolation) String() string {
+	return fmt.Sprintf("container %s exceeded its resource thresholds since %s: memory=%d bytes, cpu=%.2f%%",
+		v.ContainerID, v.ExceededSince.Format(time.RFC3339), v.Memory, v.CPUPercent)
+}
+
+// Watchdog samples the resource usage of a container and flags or terminates it when it exceeds
+// the configured thresholds for too long. This protects shared CI machines from runaway test containers.
+type Watchdog struct {
+	container *DockerContainer
+	opts      WatchdogOptions
+
+	exceededSince time.Time
+	violations    []WatchdogViolation
+	lock          sync.Mutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// watchdogSample represents the resource usage of a container at a point in time
+type watchdogSample struct {
+	Memory     int64
+	CPUPercent float64
+}
+
+// StartWatchdog starts watching the given container in the background until Stop is called,
+// the context is cancelled or the container is gone.
+func StartWatchdog(ctx context.Context, c Container, opts WatchdogOptions) (*Watchdog, error) {
+	dc, ok := c.(*DockerContainer)
+	if !ok {
+		return nil, errors.New("the watchdog requires a container created by the Docker provider")
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWatchdogInterval
+	}
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = defaultWatchdogGracePeriod
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Watchdog{
+		container: dc,
+		opts:      opts,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	go w.run(ctx)
+
+	return w, nil
+}
+
+// Stop stops watching the container and waits for the background sampling to finish
+func (w *Watchdog) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// Violations returns all violations detected so far
+func (w *Watchdog) Violations() []WatchdogViolation {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	violations := make([]WatchdogViolation, len(w.violations))
+	copy(violations, w.violations)
+
+	return violations
+}
+
+func (w *Watchdog) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats, err := w.container.Stats(ctx)
+			if err != nil {
+				if client.IsErrNotFound(err) || ctx.Err() != nil {
+					return
+				}
+				w.container.logger.Printf("watchdog failed sampling stats of container %s: %s", w.container.ID, err)
+				continue
+			}
+
+			violation := w.evaluate(newWatchdogSample(stats), time.Now())
+			if violation == nil {
+				continue
+			}
+
+			w.container.logger.Printf("watchdog: %s", violation)
+			if w.opts.OnViolation != nil {
+				w.opts.OnViolation(*violation)
+			}
+
+			if w.opts.Action == WatchdogTerminate {
+				if err := w.container.Terminate(ctx); err != nil {
+					w.container.logger.Printf("watchdog failed terminating container %s: %s", w.container.ID, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// evaluate records and returns a violation if the thresholds have been exceeded longer than the grace period
+func (w *Watchdog) evaluate(sample watchdogSample, now time.Time) *WatchdogViolation {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	exceeded := (w.opts.MaxMemory > 0 && sample.Memory > w.opts.MaxMemory) ||
+		(w.opts.MaxCPUPercent > 0 && sample.CPUPercent > w.opts.MaxCPUPercent)
+
+	if !exceeded {
+		w.exceededSince = time.Time{}
+		return nil
+	}
+
+	if w.exceededSince.IsZero() {
+		w.exceededSince = now
+	}
+
+	if now.Sub(w.exceededSince) < w.opts.GracePeriod {
+		return nil
+	}
+
+	violation := WatchdogViolation{
+		ContainerID:   w.container.ID,
+		Memory:        sample.Memory,
+		CPUPercent:    sample.CPUPercent,
+		ExceededSince: w.exceededSince,
+		DetectedAt:    now,
+	}
+	w.violations = append(w.violations, violation)
+	// start a new grace period for the next violation
+	w.exceededSince = time.Time{}
+
+	return &violation
+}
+
+func newWatchdogSample(stats *ContainerStats) watchdogSample {
+	return watchdogSample{
+		Memory:     int64(stats.MemoryUsage),
+		CPUPercent: stats.CPUPercent,
+	}
+}
@@ -0,0 +1,75 @@
+package testcontainers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdogEvaluate(t *testing.T) {
+	w := &Watchdog{
+		container: &DockerContainer{ID: "abc"},
+		opts: WatchdogOptions{
+			MaxMemory:     100,
+			MaxCPUPercent: 50,
+			GracePeriod:   10 * time.Second,
+		},
+	}
+
+	start := time.Now()
+
+	assert.Nil(t, w.evaluate(watchdogSample{Memory: 50, CPUPercent: 10}, start))
+	assert.Nil(t, w.evaluate(watchdogSample{Memory: 150, CPUPercent: 10}, start.Add(time.Second)))
+	assert.Nil(t, w.evaluate(watchdogSample{Memory: 150, CPUPercent: 10}, start.Add(5*time.Second)))
+
+	violation := w.evaluate(watchdogSample{Memory: 10, CPUPercent: 90}, start.Add(11*time.Second))
+	require.NotNil(t, violation)
+	assert.Equal(t, "abc", violation.ContainerID)
+	assert.Equal(t, start.Add(time.Second), violation.ExceededSince)
+	assert.Equal(t, 90.0, violation.CPUPercent)
+
+	// usage going back to normal resets the grace period
+	assert.Nil(t, w.evaluate(watchdogSample{Memory: 10, CPUPercent: 10}, start.Add(12*time.Second)))
+	assert.Nil(t, w.evaluate(watchdogSample{Memory: 150, CPUPercent: 10}, start.Add(13*time.Second)))
+	assert.Nil(t, w.evaluate(watchdogSample{Memory: 150, CPUPercent: 10}, start.Add(20*time.Second)))
+
+	assert.Len(t, w.Violations(), 1)
+}
+
+func TestWatchdogTerminatesRunawayContainer(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := GenericContainer(ctx, GenericContainerRequest{
+		ProviderType: providerType,
+		ContainerRequest: ContainerRequest{
+			Image: "docker.io/alpine",
+			Cmd:   []string{"sh", "-c", "while true; do :; done"},
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+
+	violations := make(chan WatchdogViolation, 1)
+	w, err := StartWatchdog(ctx, c, WatchdogOptions{
+		MaxCPUPercent: 10,
+		GracePeriod:   time.Second,
+		Interval:      500 * time.Millisecond,
+		Action:        WatchdogTerminate,
+		OnViolation: func(v WatchdogViolation) {
+			violations <- v
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(w.Stop)
+
+	select {
+	case v := <-violations:
+		assert.Equal(t, c.GetContainerID(), v.ContainerID)
+		assert.Greater(t, v.CPUPercent, 10.0)
+	case <-time.After(30 * time.Second):
+		t.Fatal("expected the watchdog to detect the runaway container")
+	}
+}